@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/devos-os/d-guard/internal"
+	"github.com/devos-os/d-guard/internal/baseline"
+	"github.com/devos-os/d-guard/internal/core"
+	"github.com/devos-os/d-guard/internal/gate"
+	"github.com/devos-os/d-guard/internal/git"
+	"github.com/devos-os/d-guard/internal/suppress"
+	"github.com/spf13/cobra"
+)
+
+func newBaselineCmd() *cobra.Command {
+	var output string
+
+	baselineCmd := &cobra.Command{
+		Use:   "baseline",
+		Short: "Manage the baseline of accepted (legacy) findings",
+	}
+
+	createCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Scan the whole repository and record all current findings as the baseline",
+		Run: func(cmd *cobra.Command, args []string) {
+			// Baseline снимается по всему репозиторию, а не по diff
+			root, err := git.GetRepoRoot()
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(gate.ExitError)
+			}
+			if cfg.Files, err = git.ListFiles(root); err != nil {
+				fmt.Printf("❌ Failed to list repository files: %v\n", err)
+				os.Exit(gate.ExitError)
+			}
+			res, err := internal.RunAll(cmd.Context(), cfg)
+			exitIfInterrupted(cmd.Context())
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(gate.ExitError)
+			}
+
+			path := output
+			if path == "" {
+				path = filepath.Join(res.Root, baseline.DefaultFile)
+			}
+			b, err := baseline.Create(res.Issues, res.Lines(), path)
+			if err != nil {
+				fmt.Printf("❌ Failed to write baseline: %v\n", err)
+				os.Exit(gate.ExitError)
+			}
+			fmt.Printf("\n📌 Baseline with %d findings written to %s\n", len(b.Entries), path)
+		},
+	}
+	createCmd.Flags().StringVarP(&output, "output", "o", "", "Baseline file (default: "+baseline.DefaultFile+" in repo root)")
+
+	baselineCmd.AddCommand(createCmd)
+	return baselineCmd
+}
+
+// applyBaseline убирает из результатов известные проблемы и сообщает
+// о записях baseline, которые больше не воспроизводятся
+func applyBaseline(res *internal.Result) []core.Issue {
+	path := baselineFile
+	if path == "" {
+		path = filepath.Join(res.Root, baseline.DefaultFile)
+		if _, err := os.Stat(path); err != nil {
+			return res.Issues
+		}
+	}
+
+	b, err := baseline.Load(path)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(gate.ExitError)
+	}
+
+	// "Устаревшими" считаем только записи из реально просканированных файлов
+	var scope []string
+	if !res.ScanAll {
+		scope = append([]string{}, res.Files...)
+	}
+	if res.Hunks != nil {
+		// Проблемы на неизмененных строках скрыты, а не исчезли: "устаревших" не ищем
+		scope = []string{}
+	}
+	fresh, stale := b.Filter(res.Issues, res.Lines(), scope)
+
+	fmt.Printf("\n📌 Baseline %s: %d known findings accepted, %d new\n", path, len(res.Issues)-len(fresh), len(suppress.Active(fresh)))
+	if len(stale) > 0 {
+		fmt.Printf("🧹 %d baseline entries no longer reproduce (re-run 'd-guard baseline create' to prune):\n", len(stale))
+		for _, e := range stale {
+			fmt.Printf("    [%s] %s (%s:%d)\n", e.Scanner, e.Message, e.File, e.Line)
+		}
+	}
+	return fresh
+}
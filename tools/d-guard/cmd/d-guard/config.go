@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/devos-os/d-guard/internal"
+	"github.com/devos-os/d-guard/internal/gate"
+	"github.com/devos-os/d-guard/internal/git"
+	"github.com/spf13/cobra"
+)
+
+func newConfigCmd() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the project configuration (.d-guard.yaml)",
+	}
+
+	configCmd.AddCommand(&cobra.Command{
+		Use:   "validate",
+		Short: "Validate .d-guard.yaml (or --config) without running scanners",
+		Run: func(cmd *cobra.Command, args []string) {
+			root, _ := git.GetRepoRoot()
+			project, err := internal.LoadProject(root, cfg.ConfigFile)
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(gate.ExitError)
+			}
+			if project.Path == "" {
+				fmt.Println("ℹ️  No .d-guard.yaml found, defaults are used")
+				return
+			}
+			fmt.Printf("✅ %s is valid\n", project.Path)
+		},
+	})
+	return configCmd
+}
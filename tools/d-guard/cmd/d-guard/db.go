@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/devos-os/d-guard/internal/gate"
+	"github.com/devos-os/d-guard/internal/osv"
+	"github.com/spf13/cobra"
+)
+
+func newDBCmd() *cobra.Command {
+	var dir string
+
+	dbCmd := &cobra.Command{
+		Use:   "db",
+		Short: "Manage the local OSV vulnerability database used by the native SCA scanner (deps)",
+	}
+	dbCmd.PersistentFlags().StringVar(&dir, "db", osv.DefaultDir(), "Database directory (scanners.deps.db in .d-guard.yaml must point to the same place)")
+
+	updateCmd := &cobra.Command{
+		Use:   "update [SOURCE...]",
+		Short: "Import OSV records from files or URLs (default: OSV exports for Go, npm, crates.io, PyPI)",
+		Long: `Import OSV records into the local database.
+
+A SOURCE is a path or an http(s) URL to an OSV export (all.zip of an
+ecosystem), a single OSV JSON record or a JSON array of records. Ecosystems
+found in the sources replace their previous contents in the database.
+
+With --offline only local files are accepted, e.g. a mirror of all.zip
+copied into an air-gapped network.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			sources := args
+			if len(sources) == 0 {
+				if cfg.Offline {
+					fmt.Println("❌ --offline: pass local OSV files to import (no default download)")
+					os.Exit(gate.ExitError)
+				}
+				sources = osv.DefaultSources()
+			}
+			for _, src := range sources {
+				if cfg.Offline && osv.IsURL(src) {
+					fmt.Printf("❌ --offline: refusing to download %s\n", src)
+					os.Exit(gate.ExitError)
+				}
+				fmt.Printf("📥 %s\n", src)
+			}
+
+			counts, err := osv.Update(cmd.Context(), dir, sources)
+			exitIfInterrupted(cmd.Context())
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(gate.ExitError)
+			}
+			var ecos []string
+			for eco := range counts {
+				ecos = append(ecos, eco)
+			}
+			sort.Strings(ecos)
+			for _, eco := range ecos {
+				fmt.Printf("✅ %s: %d advisories\n", eco, counts[eco])
+			}
+			fmt.Printf("📂 OSV database: %s\n", dir)
+		},
+	}
+
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show ecosystems in the local OSV database and when they were updated",
+		Run: func(cmd *cobra.Command, args []string) {
+			db, err := osv.Open(dir)
+			if err != nil {
+				fmt.Printf("⚪ %v\n", err)
+				return
+			}
+			fmt.Printf("📂 OSV database: %s\n\n", db.Dir)
+			var ecos []string
+			for eco := range db.Meta.Ecosystems {
+				ecos = append(ecos, eco)
+			}
+			sort.Strings(ecos)
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ECOSYSTEM\tADVISORIES\tUPDATED\tSOURCE")
+			for _, eco := range ecos {
+				m := db.Meta.Ecosystems[eco]
+				age := time.Since(m.Updated).Round(time.Hour)
+				fmt.Fprintf(w, "%s\t%d\t%s (%s ago)\t%s\n", eco, m.Count, m.Updated.Local().Format("2006-01-02 15:04"), age, m.Source)
+			}
+			w.Flush()
+		},
+	}
+
+	dbCmd.AddCommand(updateCmd, statusCmd)
+	return dbCmd
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/devos-os/d-guard/internal/gate"
+	"github.com/devos-os/d-guard/internal/git"
+	"github.com/devos-os/d-guard/internal/hooks"
+	"github.com/spf13/cobra"
+)
+
+func newHookCmd() *cobra.Command {
+	var types []string
+	var binary string
+
+	hookCmd := &cobra.Command{
+		Use:   "hook",
+		Short: "Manage git hooks (pre-commit: staged changes, pre-push: secrets in pushed commits)",
+	}
+	hookCmd.PersistentFlags().StringSliceVar(&types, "type", hooks.Types, "Hook types")
+
+	installCmd := &cobra.Command{
+		Use:   "install",
+		Short: "Install d-guard git hooks (existing hooks are kept and run first)",
+		Run: func(cmd *cobra.Command, args []string) {
+			dir := hooksDir()
+			if binary == "" {
+				binary = selfPath()
+			}
+			for _, t := range types {
+				st, err := hooks.Install(dir, t, binary)
+				if err != nil {
+					fmt.Printf("❌ %s: %v\n", t, err)
+					os.Exit(gate.ExitError)
+				}
+				fmt.Printf("✅ %s: %s (%s)\n", st.Type, st.State, st.Path)
+				if st.State == hooks.StateChained {
+					fmt.Printf("    🔗 previous hook kept as %s%s and runs first\n", st.Type, hooks.ChainedSuffix)
+				}
+			}
+			if root, err := git.GetRepoRoot(); err == nil {
+				if _, err := os.Stat(filepath.Join(root, ".pre-commit-config.yaml")); err == nil {
+					fmt.Println("ℹ️  This repository uses the pre-commit framework: consider adding d-guard to .pre-commit-config.yaml instead (see 'd-guard hook pre-commit-config')")
+				}
+			}
+		},
+	}
+	installCmd.Flags().StringVar(&binary, "binary", "", "Path to d-guard used by the hooks (default: this executable)")
+
+	uninstallCmd := &cobra.Command{
+		Use:   "uninstall",
+		Short: "Remove d-guard git hooks and restore previously existing ones",
+		Run: func(cmd *cobra.Command, args []string) {
+			dir := hooksDir()
+			for _, t := range types {
+				st, err := hooks.Uninstall(dir, t)
+				if err != nil {
+					fmt.Printf("❌ %s: %v\n", t, err)
+					os.Exit(gate.ExitError)
+				}
+				fmt.Printf("🧹 %s: %s\n", st.Type, st.State)
+			}
+		},
+	}
+
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show which git hooks are managed by d-guard",
+		Run: func(cmd *cobra.Command, args []string) {
+			dir := hooksDir()
+			fmt.Printf("📂 Hooks directory: %s\n", dir)
+			for _, t := range types {
+				st := hooks.Check(dir, t)
+				icon := "⚪"
+				switch st.State {
+				case hooks.StateInstalled, hooks.StateChained:
+					icon = "✅"
+				case hooks.StateForeign:
+					icon = "⚠️ "
+				}
+				fmt.Printf("%s %s: %s\n", icon, st.Type, st.State)
+			}
+		},
+	}
+
+	var output string
+	preCommitCmd := &cobra.Command{
+		Use:   "pre-commit-config",
+		Short: "Generate .pre-commit-hooks.yaml for the pre-commit framework",
+		Run: func(cmd *cobra.Command, args []string) {
+			data := hooks.PreCommitHooksYAML()
+			if output == "-" {
+				fmt.Print(data)
+				return
+			}
+			if err := os.WriteFile(output, []byte(data), 0o644); err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(gate.ExitError)
+			}
+			fmt.Printf("📄 pre-commit hook definitions written to %s\n", output)
+		},
+	}
+	preCommitCmd.Flags().StringVarP(&output, "output", "o", ".pre-commit-hooks.yaml", "Output file ('-' for stdout)")
+
+	hookCmd.AddCommand(installCmd, uninstallCmd, statusCmd, preCommitCmd)
+	return hookCmd
+}
+
+// hooksDir — каталог hooks текущего репозитория (core.hooksPath учитывается)
+func hooksDir() string {
+	root, err := git.GetRepoRoot()
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(gate.ExitError)
+	}
+	dir, err := hooks.Dir(root)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(gate.ExitError)
+	}
+	return dir
+}
+
+// selfPath — абсолютный путь к текущему бинарнику d-guard
+func selfPath() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return "d-guard"
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		return resolved
+	}
+	return exe
+}
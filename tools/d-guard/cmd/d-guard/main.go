@@ -1,57 +1,273 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/devos-os/d-guard/internal"
+	"github.com/devos-os/d-guard/internal/baseline"
 	"github.com/devos-os/d-guard/internal/core"
+	"github.com/devos-os/d-guard/internal/gate"
 	"github.com/devos-os/d-guard/internal/reporters"
+	"github.com/devos-os/d-guard/internal/scanner"
+	"github.com/devos-os/d-guard/internal/suppress"
 	"github.com/spf13/cobra"
 )
 
 var cfg core.Config
-var reportFile string
+var reportFiles []string
 var strictMode bool // <--- Новый флаг
+var baselineFile string
+var failOn string
+var failOnScanner map[string]string
+var timeouts []string
 
 func main() {
 	var rootCmd = &cobra.Command{
 		Use:   "d-guard",
 		Short: "DevOS Security Orchestrator",
-		Run:   run,
+		Long: `DevOS Security Orchestrator
+
+Exit codes (when gating is enabled with --strict, --ci or --fail-on):
+  0  no active findings at or above the threshold
+  1  findings at or above the threshold (--fail-on, fail_on in .d-guard.yaml)
+  2  execution error: a scanner failed or timed out, invalid configuration, not a git repository
+  130  interrupted (Ctrl+C, SIGTERM): scanners and their child processes are stopped`,
+		Run: run,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return parseTimeouts(timeouts)
+		},
 	}
 	rootCmd.PersistentFlags().BoolVar(&cfg.IsCI, "ci", false, "CI Mode (Diff vs Base Branch)")
 	rootCmd.PersistentFlags().BoolVar(&cfg.ScanAll, "all", false, "Scan All Files")
 	rootCmd.PersistentFlags().StringVar(&cfg.BaseBranch, "base", "", "Diff Base")
-	rootCmd.PersistentFlags().StringVar(&reportFile, "report", "", "HTML Report file")
+	rootCmd.PersistentFlags().StringSliceVar(&reportFiles, "report", nil, "Report files, repeatable: out.html, out.sarif, out.json, out.xml (JUnit) or FORMAT=path")
+	rootCmd.PersistentFlags().StringVar(&cfg.OutputFmt, "format", "", "Report format: html, sarif, json, junit (default: by --report extension)")
 	
 	// Добавляем флаг strict
 	rootCmd.PersistentFlags().BoolVar(&strictMode, "strict", false, "Exit with code 1 if issues found (for pre-commit)")
+	rootCmd.PersistentFlags().StringVar(&failOn, "fail-on", "", "Fail only on findings of this severity or higher: LOW, MEDIUM, HIGH, CRITICAL (implies gating)")
+	rootCmd.PersistentFlags().StringToStringVar(&failOnScanner, "fail-on-scanner", nil, "Per-scanner threshold, e.g. semgrep=HIGH,code=CRITICAL")
+
+	rootCmd.PersistentFlags().StringVar(&cfg.ConfigFile, "config", "", "Project config (default: .d-guard.yaml in repo root)")
+	rootCmd.PersistentFlags().BoolVar(&cfg.NoCache, "no-cache", false, "Rescan every file instead of reusing cached results of native scanners (~/.cache/devos/d-guard)")
+	rootCmd.PersistentFlags().IntVar(&cfg.Workers, "workers", 0, "Parallel workers for native scanners (default: number of CPUs)")
+	rootCmd.PersistentFlags().StringSliceVar(&timeouts, "timeout", []string{"20m"}, "Time limit per scanner (0 = none), repeatable; NAME=DURATION sets it for one scanner, e.g. --timeout 10m --timeout semgrep=3m")
+	rootCmd.PersistentFlags().BoolVar(&cfg.Offline, "offline", false, "Never access the network: Trivy uses its cached DB, SCA uses the local OSV database ('d-guard db update')")
+
+	// Выбор сканеров из реестра
+	rootCmd.PersistentFlags().StringSliceVar(&cfg.Only, "only", nil, "Run only these scanners (comma-separated, see 'd-guard scanners')")
+	rootCmd.PersistentFlags().StringSliceVar(&cfg.Skip, "skip", nil, "Skip these scanners (comma-separated)")
+
+	rootCmd.Flags().BoolVar(&cfg.Staged, "staged", false, "Scan only staged changes, i.e. what will be committed (used by the pre-commit hook)")
+
+	// Pre-commit на legacy-файлах: только то, что затронуто изменением
+	rootCmd.Flags().BoolVar(&cfg.ChangedLines, "changed-lines", false, "Report only issues on lines changed in the diff (git diff -U0 hunks)")
+
+	// История: секреты, которые были закоммичены и потом удалены
+	rootCmd.Flags().BoolVar(&cfg.History, "history", false, "Scan lines added by past commits for secrets (native engine) instead of the working tree")
+	rootCmd.Flags().StringVar(&cfg.HistoryRange, "history-range", "", "Commit range for --history, e.g. origin/main..HEAD (default: all branches)")
+
+	// Baseline: известные проблемы не ломают --strict
+	rootCmd.Flags().StringVar(&baselineFile, "baseline", "", "Baseline file with accepted findings (default: "+baseline.DefaultFile+" in repo root, if present)")
+	rootCmd.AddCommand(newBaselineCmd())
+	rootCmd.AddCommand(newConfigCmd())
+	rootCmd.AddCommand(newHookCmd())
+	rootCmd.AddCommand(newRuntimeCmd())
+	rootCmd.AddCommand(newDBCmd())
+	rootCmd.AddCommand(newSBOMCmd())
+	rootCmd.AddCommand(newToolsCmd())
+	rootCmd.AddCommand(newRulesCmd())
+
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "scanners",
+		Short: "List registered scanners",
+		Run: func(cmd *cobra.Command, args []string) {
+			for _, s := range scanner.All() {
+				fmt.Println(s.Name())
+			}
+		},
+	})
+
+	// Ctrl+C/SIGTERM отменяют context: сканеры и их группы процессов останавливаются.
+	// Повторный Ctrl+C после отмены завершает d-guard сразу.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil { os.Exit(gate.ExitError) }
+}
+
+// parseTimeouts разбирает --timeout: DURATION — для всех сканеров, NAME=DURATION — для одного
+func parseTimeouts(specs []string) error {
+	for _, spec := range specs {
+		name, value, named := strings.Cut(spec, "=")
+		if !named {
+			value = spec
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid --timeout %q: expected a duration like 90s or 10m, or NAME=DURATION", spec)
+		}
+		if !named {
+			cfg.Timeout = d
+			continue
+		}
+		if cfg.Timeouts == nil {
+			cfg.Timeouts = map[string]time.Duration{}
+		}
+		cfg.Timeouts[strings.TrimSpace(name)] = d
+	}
+	return nil
+}
 
-	if err := rootCmd.Execute(); err != nil { os.Exit(1) }
+// exitIfInterrupted: после Ctrl+C результаты неполные, отчеты и gating не имеют смысла
+func exitIfInterrupted(ctx context.Context) {
+	if ctx.Err() != nil {
+		fmt.Println("\n🛑 Interrupted, results discarded")
+		os.Exit(gate.ExitInterrupted)
+	}
 }
 
 func run(cmd *cobra.Command, args []string) {
-	issues := internal.RunAll(cfg)
+	scan := internal.RunAll
+	if cfg.History || cfg.HistoryRange != "" {
+		scan = internal.RunHistory
+	}
+	res, err := scan(cmd.Context(), cfg)
+	exitIfInterrupted(cmd.Context())
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(gate.ExitError)
+	}
+	finish(res, applyBaseline(res))
+}
+
+// finish пишет отчеты, выводит проблемы и завершает процесс по политике (--strict, --fail-on)
+func finish(res *internal.Result, issues []core.Issue) {
+	policy, err := gate.NewPolicy(cfg.IsCI || strictMode, failOn, failOnScanner, res.Project)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(gate.ExitError)
+	}
 
-	if reportFile != "" {
-		reporters.GenerateHTML(issues, reportFile)
+	for _, report := range reportFiles {
+		writeReport(issues, res, report)
 	}
 	
-	if len(issues) > 0 {
-		fmt.Printf("\n🔥 Total Issues: %d\n", len(issues))
-		for _, i := range issues {
+	active := suppress.Active(issues)
+	printSuppressed(issues)
+
+	if len(active) > 0 {
+		fmt.Printf("\n🔥 Total Issues: %d\n", len(active))
+		for _, i := range active {
 			color := "\033[33m" // Yellow
 			if i.Severity == core.SevCritical { color = "\033[31m" }
 			reset := "\033[0m"
-			fmt.Printf("%s[%s] %s%s: %s\n    %s\n", color, i.Severity, i.Scanner, reset, i.Message, i.File)
-		}
-		
-		// Ломаем процесс, если включен CI ИЛИ Strict
-		if cfg.IsCI || strictMode {
-			os.Exit(1)
+			fmt.Printf("%s[%s] %s%s: %s\n    %s\n", color, i.Severity, i.Scanner, reset, i.Message, location(i))
 		}
 	} else {
 		fmt.Println("\n✨ All clear. Good job.")
 	}
-}
\ No newline at end of file
+
+	if len(res.Runs) > 0 {
+		fmt.Println("\n🧪 Scanners")
+		reporters.PrintScanners(os.Stdout, res.Runs)
+		reporters.PrintCache(os.Stdout, res.Runs)
+	}
+	if len(issues) > 0 {
+		fmt.Println("\n📊 Summary")
+		reporters.PrintSummary(os.Stdout, issues)
+	}
+	fmt.Printf("\n🚦 Policy: %s\n", policy.Describe())
+
+	// Ломаем процесс, если включен CI ИЛИ Strict ИЛИ --fail-on
+	if failing := policy.Failing(active); len(failing) > 0 {
+		fmt.Printf("❌ %d findings at or above the threshold\n", len(failing))
+		os.Exit(gate.ExitFindings)
+	}
+	if failed := res.Failed(); policy.Enabled && len(failed) > 0 {
+		fmt.Printf("❌ %d scanners failed, results are incomplete\n", len(failed))
+		os.Exit(gate.ExitError)
+	}
+}
+
+// location — файл (и коммит для --history) для вывода в консоль
+func location(i core.Issue) string {
+	if i.Commit == "" {
+		return i.File
+	}
+	return fmt.Sprintf("%s:%d @ %s (%s, %s)", i.File, i.Line, core.ShortSHA(i.Commit), i.Author, i.Date.Format("2006-01-02"))
+}
+
+// printSuppressed показывает подавленные проблемы вместе с обоснованием
+func printSuppressed(issues []core.Issue) {
+	var n int
+	for _, i := range issues {
+		if !i.Suppressed { continue }
+		if n == 0 { fmt.Println("\n🔕 Suppressed:") }
+		n++
+		fmt.Printf("    [%s] %s: %s (%s:%d) — %s\n", i.Severity, i.Scanner, i.Message, i.File, i.Line, i.Justification)
+	}
+}
+
+// reportFormats — поддерживаемые форматы --report
+var reportFormats = []string{"html", "sarif", "json", "junit"}
+
+// reportFormat выбирает формат отчета: префикс FORMAT=path, явный --format или расширение файла
+func reportFormat(spec string) (format, filename string) {
+	if f, path, ok := strings.Cut(spec, "="); ok && contains(reportFormats, strings.ToLower(f)) {
+		return strings.ToLower(f), path
+	}
+	if cfg.OutputFmt != "" {
+		return strings.ToLower(cfg.OutputFmt), spec
+	}
+	switch {
+	case strings.HasSuffix(spec, ".sarif") || strings.HasSuffix(spec, ".sarif.json"):
+		return "sarif", spec
+	case strings.HasSuffix(spec, ".json"):
+		return "json", spec
+	case strings.HasSuffix(spec, ".xml"):
+		return "junit", spec
+	}
+	return "html", spec
+}
+
+// writeReport пишет один отчет; ошибка записи не отменяет остальные отчеты
+func writeReport(issues []core.Issue, res *internal.Result, spec string) {
+	format, filename := reportFormat(spec)
+
+	var err error
+	switch format {
+	case "html":
+		reporters.GenerateHTML(issues, res.Runs, filename)
+	case "sarif":
+		err = reporters.GenerateSARIF(issues, res.Runs, res.Root, filename)
+	case "json":
+		err = reporters.GenerateJSON(issues, res.Runs, res.Info, filename)
+	case "junit":
+		err = reporters.GenerateJUnit(issues, res.Runs, res.Info, filename)
+	default:
+		fmt.Printf("⚠️  Unknown report format '%s' (supported: %s)\n", format, strings.Join(reportFormats, ", "))
+		return
+	}
+	if err != nil {
+		fmt.Printf("⚠️  Failed to write %s report %s: %v\n", format, filename, err)
+	}
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/devos-os/d-guard/internal/gate"
+	"github.com/devos-os/d-guard/internal/git"
+	"github.com/devos-os/d-guard/internal/tools"
+	"github.com/spf13/cobra"
+)
+
+func newRulesCmd() *cobra.Command {
+	rulesCmd := &cobra.Command{
+		Use:   "rules",
+		Short: "Manage Semgrep rules: bundled DevOS packs and the offline registry cache",
+		Long: `Manage Semgrep rules used by the semgrep scanner.
+
+scanners.semgrep.config in .d-guard.yaml is a comma-separated list of:
+  devos            bundled DevOS packs for languages found in the scan (default)
+  devos/<pack>     a specific bundled pack
+  p/<pack>, r/...  semgrep.dev registry configs, read from the local cache when
+                   pulled with 'd-guard rules pull' (required with --offline)
+  ./path           local rule files or directories, relative to .d-guard.yaml
+
+Semgrep always runs with --metrics=off.`,
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "Show bundled packs (and which apply to this repository) and cached registry configs",
+		Run: func(cmd *cobra.Command, args []string) {
+			detected := map[string]bool{}
+			if root, err := git.GetRepoRoot(); err == nil {
+				if files, err := git.ListFiles(root); err == nil {
+					for _, p := range tools.DetectPacks(files) {
+						detected[p] = true
+					}
+				}
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "CONFIG\tSOURCE\tTHIS REPOSITORY")
+			for _, p := range tools.RulePacks() {
+				used := "-"
+				if detected[p] {
+					used = "used by 'devos'"
+				}
+				fmt.Fprintf(w, "devos/%s\tbundled\t%s\n", p, used)
+			}
+			filepath.WalkDir(tools.RulesCacheDir(), func(path string, d os.DirEntry, err error) error {
+				if err != nil || d.IsDir() || !strings.HasSuffix(path, ".yaml") {
+					return nil
+				}
+				rel, _ := filepath.Rel(tools.RulesCacheDir(), path)
+				fmt.Fprintf(w, "%s\tcache: %s\t-\n", strings.TrimSuffix(filepath.ToSlash(rel), ".yaml"), path)
+				return nil
+			})
+			w.Flush()
+		},
+	}
+
+	var from string
+	pullCmd := &cobra.Command{
+		Use:   "pull CONFIG...",
+		Short: "Cache semgrep.dev registry configs (e.g. p/ci, p/golang) for offline scans",
+		Long: `Cache semgrep.dev registry configs for offline scans.
+
+Configs are downloaded from https://semgrep.dev/c/<config>. On machines
+without network access, copy a previously downloaded rules file and import
+it with --from.`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if from != "" && len(args) > 1 {
+				fmt.Println("❌ --from imports a single config")
+				os.Exit(gate.ExitError)
+			}
+			if from == "" && cfg.Offline {
+				fmt.Println("❌ --offline: refusing to download rules (use --from with a local file)")
+				os.Exit(gate.ExitError)
+			}
+			failed := 0
+			for _, config := range args {
+				path, n, err := tools.PullRules(cmd.Context(), config, from)
+				exitIfInterrupted(cmd.Context())
+				if err != nil {
+					fmt.Printf("❌ %s: %v\n", config, err)
+					failed++
+					continue
+				}
+				fmt.Printf("✅ %s: %d rules cached in %s\n", config, n, path)
+			}
+			if failed > 0 {
+				os.Exit(gate.ExitError)
+			}
+		},
+	}
+	pullCmd.Flags().StringVar(&from, "from", "", "Import the config from a local rules file instead of downloading it")
+
+	rulesCmd.AddCommand(listCmd, pullCmd)
+	return rulesCmd
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/devos-os/d-guard/internal"
+	"github.com/devos-os/d-guard/internal/gate"
+	"github.com/spf13/cobra"
+)
+
+func newRuntimeCmd() *cobra.Command {
+	runtimeCmd := &cobra.Command{
+		Use:   "runtime",
+		Short: "Audit running Docker/Podman containers (CIS Docker Benchmark style checks)",
+		Long: `Audit running Docker/Podman containers (CIS Docker Benchmark style checks).
+
+The engine is found via --host, DOCKER_HOST, CONTAINER_HOST or the default
+Docker and Podman (rootful and rootless) sockets. With --from-inspect the
+output of 'docker inspect' is audited offline.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			res, err := internal.RunRuntime(cmd.Context(), cfg)
+			exitIfInterrupted(cmd.Context())
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(gate.ExitError)
+			}
+			// Baseline относится к файлам репозитория, для контейнеров не применяется
+			finish(res, res.Issues)
+		},
+	}
+	runtimeCmd.Flags().StringVar(&cfg.RuntimeHost, "host", "", "Engine API endpoint, e.g. unix:///run/podman/podman.sock (default: auto-detect)")
+	runtimeCmd.Flags().BoolVarP(&cfg.RuntimeAll, "all-containers", "a", false, "Include stopped containers")
+	runtimeCmd.Flags().StringVar(&cfg.InspectFile, "from-inspect", "", "Audit a saved 'docker inspect' JSON dump instead of a live engine")
+	return runtimeCmd
+}
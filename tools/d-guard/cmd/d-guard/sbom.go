@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/devos-os/d-guard/internal/gate"
+	"github.com/devos-os/d-guard/internal/git"
+	"github.com/devos-os/d-guard/internal/sbom"
+	"github.com/devos-os/d-guard/internal/source"
+	"github.com/spf13/cobra"
+)
+
+func newSBOMCmd() *cobra.Command {
+	var format, output string
+
+	sbomCmd := &cobra.Command{
+		Use:   "sbom [DIR]",
+		Short: "Generate a software bill of materials from Go, Rust, Node and Python lockfiles",
+		Long: `Generate a software bill of materials (SBOM) for the repository.
+
+Components come from the same lockfiles as the native SCA scanner: go.mod/go.sum,
+Cargo.lock, package-lock.json, yarn.lock, poetry.lock and pinned requirements*.txt.
+Each component has a purl and the hashes recorded in the lockfile. Licenses are
+taken from package-lock.json or from local package caches (Go module cache,
+Cargo registry, node_modules, .venv); the network is never used.
+
+DIR defaults to the git repository root (all tracked and untracked, not ignored
+files) or the current directory outside git.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			root, files, err := sbomFiles(args)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+				os.Exit(gate.ExitError)
+			}
+			bom, err := sbom.Build(root, files, source.OS{})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+				os.Exit(gate.ExitError)
+			}
+			for _, w := range bom.Warnings {
+				fmt.Fprintf(os.Stderr, "⚠️  %s\n", w)
+			}
+
+			out := os.Stdout
+			if output != "" && output != "-" {
+				if out, err = os.Create(output); err != nil {
+					fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+					os.Exit(gate.ExitError)
+				}
+				defer out.Close()
+			}
+			if err := sbom.Write(out, format, bom); err != nil {
+				fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+				os.Exit(gate.ExitError)
+			}
+			// Сводка в stderr: stdout может быть самим SBOM
+			fmt.Fprintf(os.Stderr, "📦 SBOM (%s): %s\n", format, sbomSummary(bom))
+			if output != "" && output != "-" {
+				fmt.Fprintf(os.Stderr, "📄 Written to %s\n", output)
+			}
+		},
+	}
+	// Локальный --format перекрывает глобальный формат отчета
+	sbomCmd.Flags().StringVar(&format, "format", sbom.FormatCycloneDX, "SBOM format: "+strings.Join(sbom.Formats, ", "))
+	sbomCmd.Flags().StringVarP(&output, "output", "o", "", "Output file (default: stdout)")
+	return sbomCmd
+}
+
+// sbomFiles — корень и файлы проекта: git ls-files в репозитории, обход каталога вне git
+func sbomFiles(args []string) (string, []string, error) {
+	root := ""
+	if len(args) > 0 {
+		abs, err := filepath.Abs(args[0])
+		if err != nil {
+			return "", nil, err
+		}
+		root = abs
+	} else if r, err := git.GetRepoRoot(); err == nil {
+		root = r
+	} else if root, err = os.Getwd(); err != nil {
+		return "", nil, err
+	}
+	if files, err := git.ListFiles(root); err == nil && len(files) > 0 {
+		return root, files, nil
+	}
+	files, err := sbom.Files(root)
+	return root, files, err
+}
+
+func sbomSummary(b *sbom.BOM) string {
+	counts := map[string]int{}
+	licensed := 0
+	for _, c := range b.Components {
+		counts[c.Ecosystem]++
+		if c.License != "" {
+			licensed++
+		}
+	}
+	if len(counts) == 0 {
+		return "no components (no supported lockfiles found)"
+	}
+	var ecos []string
+	for eco, n := range counts {
+		ecos = append(ecos, fmt.Sprintf("%s %d", eco, n))
+	}
+	sort.Strings(ecos)
+	return fmt.Sprintf("%d components (%s), %d with a known license", len(b.Components), strings.Join(ecos, ", "), licensed)
+}
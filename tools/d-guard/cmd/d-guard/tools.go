@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/devos-os/d-guard/internal/gate"
+	"github.com/devos-os/d-guard/internal/tools"
+	"github.com/spf13/cobra"
+)
+
+func newToolsCmd() *cobra.Command {
+	var manifestPath string
+
+	toolsCmd := &cobra.Command{
+		Use:   "tools",
+		Short: "Manage pinned external scanners (Gitleaks, Semgrep, Trivy)",
+		Long: `Manage pinned external scanners (Gitleaks, Semgrep, Trivy).
+
+Versions and SHA-256 checksums of release archives come from the tools
+manifest: the built-in one overlaid with ` + tools.DefaultManifestPath() + `
+(or --manifest). Installed versions live in ~/.cache/devos/bin/<tool>/<version>
+and take precedence over tools found in PATH.`,
+	}
+	toolsCmd.PersistentFlags().StringVar(&manifestPath, "manifest", "", "Tools manifest overlaid on the built-in one (default: "+tools.DefaultManifestPath()+", if present)")
+
+	load := func(args []string) (*tools.Manifest, []string) {
+		m, err := tools.LoadManifest(manifestPath)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(gate.ExitError)
+		}
+		tools.UseManifest(m)
+		names := args
+		if len(names) == 0 {
+			names = m.Names()
+		}
+		for _, name := range names {
+			if _, err := m.Get(name); err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(gate.ExitError)
+			}
+		}
+		return m, names
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "Show pinned versions, checksums and which binary scans will use",
+		Run: func(cmd *cobra.Command, args []string) {
+			m, names := load(args)
+			if m.Path != "" {
+				fmt.Printf("⚙️  Manifest: %s (over built-in)\n\n", m.Path)
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintf(w, "TOOL\tPINNED\tSHA-256 (%s)\tUSED\tVERSION\n", tools.Platform())
+			for _, name := range names {
+				t, _ := m.Get(name)
+				sum := "missing"
+				if t.Checksum() != "" {
+					sum = t.Checksum()[:12] + "…"
+				} else if t.Source() == "" {
+					sum = "-"
+				}
+				used, version := "not installed", "-"
+				if path, from, ok := tools.Resolve(name); ok {
+					used = fmt.Sprintf("%s (%s)", path, from)
+					if v := tools.ToolVersion(cmd.Context(), name); v != "" {
+						version = v
+					}
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", name, t.Version, sum, used, version)
+			}
+			w.Flush()
+		},
+	}
+
+	var opts tools.InstallOptions
+	installCmd := &cobra.Command{
+		Use:   "install [TOOL...]",
+		Short: "Install pinned versions from release URLs or a local mirror, verifying SHA-256",
+		Long: `Install pinned versions of external scanners.
+
+Archives are downloaded from the manifest URL or taken from --mirror (a
+directory or base URL holding the same file names, e.g. for air-gapped
+networks). The archive SHA-256 must match the manifest before anything is
+extracted; tools without a pinned checksum for this platform are refused.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			m, names := load(args)
+			opts.Offline = cfg.Offline
+			failed := 0
+			for _, name := range names {
+				rec, err := tools.Install(cmd.Context(), m, name, opts)
+				exitIfInterrupted(cmd.Context())
+				if err != nil {
+					fmt.Printf("❌ %s: %v\n", name, err)
+					failed++
+					continue
+				}
+				fmt.Printf("✅ %s %s: %s (sha256 %s)\n", name, rec.Version, rec.Path, rec.ArchiveSHA256)
+			}
+			if failed > 0 {
+				os.Exit(gate.ExitError)
+			}
+		},
+	}
+	installCmd.Flags().StringVar(&opts.Mirror, "mirror", "", "Directory or base URL with release archives (file names as in the manifest URLs)")
+	installCmd.Flags().BoolVar(&opts.Force, "force", false, "Reinstall even if the pinned version is already installed")
+
+	verifyCmd := &cobra.Command{
+		Use:   "verify [TOOL...]",
+		Short: "Check installed pinned tools against the manifest checksums",
+		Run: func(cmd *cobra.Command, args []string) {
+			m, names := load(args)
+			failed := 0
+			for _, name := range names {
+				rec, err := tools.Verify(m, name)
+				if err != nil {
+					fmt.Printf("❌ %s: %v\n", name, err)
+					failed++
+					continue
+				}
+				fmt.Printf("✅ %s %s: %s (installed from %s)\n", name, rec.Version, rec.Path, rec.Source)
+			}
+			if failed > 0 {
+				os.Exit(gate.ExitError)
+			}
+		},
+	}
+
+	var pinFrom, pinWrite string
+	pinCmd := &cobra.Command{
+		Use:   "pin [TOOL...]",
+		Short: "Pin SHA-256 checksums of release archives from the release checksums file",
+		Long: `Pin SHA-256 checksums of release archives for the manifest version.
+
+Checksums for ` + strings.Join(tools.PinPlatforms, ", ") + ` are read from the
+release checksums file (tools.<name>.checksums in the manifest, or --from with
+a local file or URL) and written to --write (default: --manifest, or
+` + tools.DefaultManifestPath() + `). Review the diff: 'tools install' trusts
+these checksums from now on.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			m, names := load(args)
+			if pinFrom != "" && len(names) != 1 {
+				fmt.Println("❌ --from requires exactly one tool")
+				os.Exit(gate.ExitError)
+			}
+			dest := pinWrite
+			if dest == "" {
+				dest = manifestPath
+			}
+			if dest == "" {
+				dest = tools.DefaultManifestPath()
+			}
+			failed := 0
+			for _, name := range names {
+				t, _ := m.Get(name)
+				if t.URL == "" && len(args) == 0 {
+					continue // Нечего закреплять (e.g. semgrep ставится через pip)
+				}
+				pins, err := tools.Pin(cmd.Context(), m, name, pinFrom)
+				exitIfInterrupted(cmd.Context())
+				if err == nil {
+					err = tools.WritePins(dest, name, t.Version, pins)
+				}
+				if err != nil {
+					fmt.Printf("❌ %s: %v\n", name, err)
+					failed++
+					continue
+				}
+				fmt.Printf("📌 %s %s: %d platforms pinned in %s\n", name, t.Version, len(pins), dest)
+			}
+			if failed > 0 {
+				os.Exit(gate.ExitError)
+			}
+		},
+	}
+	pinCmd.Flags().StringVar(&pinFrom, "from", "", "Release checksums file (path or URL) instead of tools.<name>.checksums")
+	pinCmd.Flags().StringVar(&pinWrite, "write", "", "Manifest file to update (default: --manifest or "+tools.DefaultManifestPath()+")")
+
+	toolsCmd.AddCommand(listCmd, installCmd, verifyCmd, pinCmd)
+	return toolsCmd
+}
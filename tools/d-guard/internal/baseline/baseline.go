@@ -0,0 +1,179 @@
+package baseline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/devos-os/d-guard/internal/core"
+	"github.com/devos-os/d-guard/internal/source"
+)
+
+// DefaultFile имя baseline-файла в корне репозитория (подхватывается автоматически)
+const DefaultFile = ".d-guard-baseline.json"
+
+const formatVersion = 1
+
+// Entry — одна известная (принятая) проблема
+type Entry struct {
+	Fingerprint string `json:"fingerprint"`
+	Scanner     string `json:"scanner"`
+	RuleID      string `json:"rule_id,omitempty"`
+	File        string `json:"file,omitempty"`
+	Line        int    `json:"line,omitempty"`   // Только для справки, в fingerprint не входит
+	Commit      string `json:"commit,omitempty"` // Находка из --history
+	Message     string `json:"message"`
+}
+
+// Baseline — снимок технического долга, который не должен ломать --strict
+type Baseline struct {
+	Version int       `json:"version"`
+	Created time.Time `json:"created"`
+	Entries []Entry   `json:"entries"`
+}
+
+// Create строит baseline из результатов скана и сохраняет его в path
+func Create(issues []core.Issue, lines *source.Lines, path string) (*Baseline, error) {
+	fp := newFingerprinter(lines)
+	b := &Baseline{Version: formatVersion, Created: time.Now().UTC(), Entries: []Entry{}}
+	for _, is := range issues {
+		// Подавленное inline уже принято осознанно, в baseline не нужно
+		if is.Suppressed {
+			continue
+		}
+		b.Entries = append(b.Entries, Entry{
+			Fingerprint: fp.of(is),
+			Scanner:     is.Scanner,
+			RuleID:      is.RuleID,
+			File:        fp.rel(is.File),
+			Line:        is.Line,
+			Commit:      is.Commit,
+			Message:     is.Message,
+		})
+	}
+
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Load читает baseline-файл
+func Load(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("%s: invalid baseline: %w", path, err)
+	}
+	if b.Version != formatVersion {
+		return nil, fmt.Errorf("%s: unsupported baseline version %d (expected %d)", path, b.Version, formatVersion)
+	}
+	return &b, nil
+}
+
+// Filter вычитает baseline из результатов скана.
+// Возвращает новые (или измененные) проблемы и записи baseline, которые больше
+// не воспроизводятся. Подавленные inline проблемы проходят без изменений.
+// scanned ограничивает проверку "устаревших" записей файлами,
+// которые реально сканировались (nil — весь репозиторий).
+func (b *Baseline) Filter(issues []core.Issue, lines *source.Lines, scanned []string) (fresh []core.Issue, stale []Entry) {
+	fp := newFingerprinter(lines)
+
+	// Multiset: одинаковые строки в файле дают одинаковый fingerprint
+	known := map[string]int{}
+	for _, e := range b.Entries {
+		known[e.Fingerprint]++
+	}
+	seen := map[string]int{}
+
+	for _, is := range issues {
+		if is.Suppressed {
+			fresh = append(fresh, is)
+			continue
+		}
+		f := fp.of(is)
+		if seen[f] < known[f] {
+			seen[f]++
+			continue
+		}
+		fresh = append(fresh, is)
+	}
+
+	inScope := map[string]bool{}
+	for _, path := range scanned {
+		inScope[fp.rel(path)] = true
+	}
+	for _, e := range b.Entries {
+		if seen[e.Fingerprint] > 0 {
+			seen[e.Fingerprint]--
+			continue
+		}
+		if scanned != nil && !inScope[e.File] {
+			continue
+		}
+		stale = append(stale, e)
+	}
+	return fresh, stale
+}
+
+// fingerprinter кэширует содержимое файлов, чтобы не читать их на каждую проблему
+type fingerprinter struct {
+	root  string
+	lines *source.Lines
+}
+
+func newFingerprinter(lines *source.Lines) *fingerprinter {
+	return &fingerprinter{root: lines.Root(), lines: lines}
+}
+
+// of = sha256(scanner + rule + file + sha256(нормализованная строка)).
+// Номер строки не участвует: сдвиг кода не должен "оживлять" старые проблемы.
+func (f *fingerprinter) of(is core.Issue) string {
+	content := f.lineContent(is.File, is.Line)
+	if is.Commit != "" {
+		// Строка из истории: рабочая копия к ней отношения не имеет, коммит неизменен
+		content = "commit:" + is.Commit
+	} else if content == "" {
+		// Нет строки (runtime, зависимости) — опираемся на текст сообщения
+		content = is.Message
+	}
+	lineHash := sha256.Sum256([]byte(content))
+
+	h := sha256.New()
+	for _, part := range []string{is.Scanner, is.RuleID, f.rel(is.File), hex.EncodeToString(lineHash[:])} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (f *fingerprinter) rel(path string) string {
+	if path == "" || f.root == "" || !filepath.IsAbs(path) {
+		return filepath.ToSlash(path)
+	}
+	if rel, err := filepath.Rel(f.root, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(path)
+}
+
+func (f *fingerprinter) lineContent(path string, line int) string {
+	text, ok := f.lines.Line(path, line)
+	if !ok {
+		return ""
+	}
+	// Нормализация: пробелы/отступы не влияют на fingerprint
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,157 @@
+package baseline
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/devos-os/d-guard/internal/core"
+	"github.com/devos-os/d-guard/internal/source"
+)
+
+// repo — корень с одним файлом app.py; Lines создается заново, чтобы не брать содержимое из кэша
+func repo(t *testing.T, root, content string) *source.Lines {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(root, "app.py"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return source.NewLines(root)
+}
+
+func issue(root string, line int) core.Issue {
+	return core.Issue{Scanner: "Secrets", RuleID: "generic-password", File: filepath.Join(root, "app.py"), Line: line, Message: "Hardcoded password"}
+}
+
+func TestFingerprint(t *testing.T) {
+	root := t.TempDir()
+	orig := newFingerprinter(repo(t, root, "import os\n\npassword = \"hunter2\"\n")).of(issue(root, 3))
+
+	// Строка сдвинулась и переотступилась — fingerprint тот же
+	moved := newFingerprinter(repo(t, root, "import os\nimport sys\n\ndef f():\n    password   =  \"hunter2\"\n")).of(issue(root, 5))
+	if moved != orig {
+		t.Error("fingerprint changed when the line moved")
+	}
+
+	// Содержимое строки изменилось — это другая проблема
+	changed := newFingerprinter(repo(t, root, "import os\n\npassword = \"hunter3\"\n")).of(issue(root, 3))
+	if changed == orig {
+		t.Error("fingerprint did not change with the line content")
+	}
+
+	fp := newFingerprinter(repo(t, root, "import os\n\npassword = \"hunter2\"\n"))
+	other := issue(root, 3)
+	other.RuleID = "generic-api-key"
+	if fp.of(other) == orig {
+		t.Error("fingerprint does not depend on the rule")
+	}
+	relative := issue(root, 3)
+	relative.File = "app.py"
+	if fp.of(relative) != orig {
+		t.Error("absolute and repo-relative paths give different fingerprints")
+	}
+
+	// Находка из истории привязана к коммиту, а не к рабочей копии
+	hist := issue(root, 3)
+	hist.Commit = "0123456789abcdef0123456789abcdef01234567"
+	fromHistory := fp.of(hist)
+	if fromHistory == orig {
+		t.Error("history finding must not use the worktree line")
+	}
+	if fp2 := newFingerprinter(repo(t, root, "rewritten\n")); fp2.of(hist) != fromHistory {
+		t.Error("history fingerprint depends on the worktree")
+	}
+
+	// Без строки (зависимости, runtime) — по сообщению
+	dep := core.Issue{Scanner: "Deps", RuleID: "GHSA-1", File: "go.mod", Message: "golang.org/x/net 0.1.0"}
+	dep2 := dep
+	dep2.Message = "golang.org/x/net 0.2.0"
+	if fp.of(dep) == fp.of(dep2) {
+		t.Error("issues without a line must differ by message")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	root := t.TempDir()
+	line := "password = \"hunter2\"\n"
+	path := filepath.Join(t.TempDir(), DefaultFile)
+
+	// В baseline две одинаковые строки: fingerprint совпадает, учитывается количество
+	lines := repo(t, root, line+line)
+	suppressed := issue(root, 1)
+	suppressed.Suppressed = true
+	b, err := Create([]core.Issue{issue(root, 1), issue(root, 2), suppressed}, lines, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.Entries) != 2 {
+		t.Fatalf("entries = %d, want 2 (suppressed issues are not baselined)", len(b.Entries))
+	}
+	if b.Entries[0].Fingerprint != b.Entries[1].Fingerprint || b.Entries[0].File != "app.py" {
+		t.Fatalf("entries = %+v", b.Entries)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		content   string
+		issues    []int // строки находок
+		suppress  bool  // добавить подавленную находку на строке 1
+		scanned   []string
+		wantFresh []int
+		wantStale int
+	}{
+		{"same issues moved down", "\n\n" + line + line, []int{3, 4}, false, nil, nil, 0},
+		{"third copy is new", line + line + line, []int{1, 2, 3}, false, nil, []int{3}, 0},
+		{"one copy fixed", line, []int{1}, false, nil, nil, 1},
+		{"all fixed", "", nil, false, nil, nil, 2},
+		{"fixed outside scanned files", "", nil, false, []string{filepath.Join(root, "other.py")}, nil, 0},
+		{"fixed in scanned file", "", nil, false, []string{filepath.Join(root, "app.py")}, nil, 2},
+		{"suppressed passes through", line + line, []int{1, 2}, true, nil, []int{1}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var issues []core.Issue
+			for _, n := range tt.issues {
+				issues = append(issues, issue(root, n))
+			}
+			if tt.suppress {
+				issues = append(issues, suppressed)
+			}
+			fresh, stale := loaded.Filter(issues, repo(t, root, tt.content), tt.scanned)
+			var got []int
+			for _, is := range fresh {
+				got = append(got, is.Line)
+			}
+			if !reflect.DeepEqual(got, tt.wantFresh) {
+				t.Errorf("fresh lines = %v, want %v", got, tt.wantFresh)
+			}
+			if len(stale) != tt.wantStale {
+				t.Errorf("stale = %d, want %d", len(stale), tt.wantStale)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	for name, tt := range map[string]struct{ content, wantErr string }{
+		"valid":   {`{"version": 1, "entries": []}`, ""},
+		"version": {`{"version": 2, "entries": []}`, "unsupported baseline version 2"},
+		"json":    {`{"version": 1,`, "invalid baseline"},
+	} {
+		path := filepath.Join(dir, name+".json")
+		if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := Load(path)
+		if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%s: Load error = %v, want %q", name, err, tt.wantErr)
+		}
+	}
+}
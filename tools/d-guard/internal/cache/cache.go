@@ -0,0 +1,163 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/devos-os/d-guard/internal/core"
+)
+
+// Кэш результатов нативных сканеров уровня файла (scanner.FileScanner).
+// Раскладка: <dir>/<scanner>/<fingerprint>.json, где fingerprint — версия d-guard
+// и набор правил; внутри записи по ключу "путь + хэш содержимого".
+
+// maxAge — записи и файлы наборов правил, не использованные дольше, удаляются
+const maxAge = 30 * 24 * time.Hour
+
+// DefaultDir — ~/.cache/devos/d-guard (XDG_CACHE_HOME и аналоги других ОС).
+// Общий корень данных d-guard: results/ (этот кэш), osv/ (зеркало OSV), semgrep/.
+func DefaultDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "devos", "d-guard")
+	}
+	return filepath.Join(dir, "devos", "d-guard")
+}
+
+// ResultsDir — каталог кэша результатов. Отдельный подкаталог: Open удаляет
+// старые файлы, и соседние данные (зеркало OSV) не должны под это попадать.
+func ResultsDir() string {
+	return filepath.Join(DefaultDir(), "results")
+}
+
+// Store — каталог кэша
+type Store struct {
+	Dir string
+}
+
+// Open возвращает кэш в dir и удаляет давно не использованные наборы правил
+func Open(dir string) *Store {
+	old, _ := filepath.Glob(filepath.Join(dir, "*", "*.json"))
+	for _, path := range old {
+		if st, err := os.Stat(path); err == nil && time.Since(st.ModTime()) > maxAge {
+			os.Remove(path)
+		}
+	}
+	return &Store{Dir: dir}
+}
+
+// Entry — находки по одному файлу (пустой список — файл чист)
+type Entry struct {
+	Used   int64        `json:"used"` // Unix-время последнего использования
+	Issues []core.Issue `json:"issues"`
+}
+
+// Bucket — записи одного сканера с одним набором правил
+type Bucket struct {
+	path    string
+	mu      sync.Mutex
+	entries map[string]*Entry
+	dirty   bool
+}
+
+// Bucket загружает записи сканера; поврежденный или отсутствующий файл — пустой кэш
+func (s *Store) Bucket(scanner, fingerprint string) *Bucket {
+	b := &Bucket{path: filepath.Join(s.Dir, scanner, fingerprint+".json"), entries: map[string]*Entry{}}
+	if data, err := os.ReadFile(b.path); err == nil {
+		if json.Unmarshal(data, &b.entries) != nil {
+			b.entries = map[string]*Entry{}
+		}
+	}
+	return b
+}
+
+// Get возвращает находки по ключу и отмечает запись использованной
+func (b *Bucket) Get(key string) ([]core.Issue, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e, ok := b.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e.Used, b.dirty = time.Now().Unix(), true
+	return append([]core.Issue(nil), e.Issues...), true
+}
+
+// Put сохраняет находки по файлу
+func (b *Bucket) Put(key string, issues []core.Issue) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.entries[key] = &Entry{Used: time.Now().Unix(), Issues: issues}
+	b.dirty = true
+}
+
+// Save пишет изменения атомарно, отбрасывая устаревшие записи
+func (b *Bucket) Save() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.dirty {
+		return nil
+	}
+	cutoff := time.Now().Add(-maxAge).Unix()
+	for key, e := range b.entries {
+		if e.Used < cutoff {
+			delete(b.entries, key)
+		}
+	}
+	data, err := json.Marshal(b.entries)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(b.path), 0o755); err != nil {
+		return err
+	}
+	tmp := b.path + "." + strconv.Itoa(os.Getpid()) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	b.dirty = false
+	return os.Rename(tmp, b.path)
+}
+
+// Key — ключ записи: путь относительно корня (от него зависят правила по путям) и содержимое
+func Key(rel string, data []byte) string {
+	h := sha256.New()
+	h.Write([]byte(rel))
+	h.Write([]byte{0})
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Fingerprint — отпечаток версии d-guard и набора правил сканера. У сборок без
+// версии ("dev") учитывается сам бинарник, чтобы правка правил сбрасывала кэш.
+func Fingerprint(parts ...string) string {
+	h := sha256.New()
+	h.Write([]byte(core.Version))
+	if core.Version == "dev" {
+		h.Write([]byte(binaryStamp()))
+	}
+	for _, p := range parts {
+		h.Write([]byte{0})
+		h.Write([]byte(p))
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// binaryStamp — размер и время изменения исполняемого файла
+func binaryStamp() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+	st, err := os.Stat(exe)
+	if err != nil {
+		return ""
+	}
+	return strconv.FormatInt(st.Size(), 10) + "/" + strconv.FormatInt(st.ModTime().UnixNano(), 10)
+}
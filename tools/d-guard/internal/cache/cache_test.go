@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Open чистит только кэш результатов: зеркало OSV и правила Semgrep живут рядом
+func TestOpenPrunesOnlyResults(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	old := time.Now().Add(-2 * maxAge)
+	files := map[string]bool{ // путь -> должен остаться
+		filepath.Join(ResultsDir(), "secrets", "stale.json"):   false,
+		filepath.Join(ResultsDir(), "secrets", "fresh.json"):   true,
+		filepath.Join(DefaultDir(), "osv", "meta.json"):        true,
+		filepath.Join(DefaultDir(), "osv", "Go.json"):          true,
+		filepath.Join(DefaultDir(), "semgrep", "p", "ci.yaml"): true,
+	}
+	for path := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("{}"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if filepath.Base(path) != "fresh.json" {
+			if err := os.Chtimes(path, old, old); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	Open(ResultsDir())
+
+	for path, keep := range files {
+		_, err := os.Stat(path)
+		if keep && err != nil {
+			t.Errorf("%s was removed", path)
+		}
+		if !keep && err == nil {
+			t.Errorf("%s was not pruned", path)
+		}
+	}
+}
+
+func TestBucketRoundTrip(t *testing.T) {
+	s := Open(t.TempDir())
+	b := s.Bucket("code", "fp")
+	if _, ok := b.Get("main.go:abc"); ok {
+		t.Fatal("empty bucket returned an entry")
+	}
+	b.Put("main.go:abc", nil)
+	if err := b.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if issues, ok := s.Bucket("code", "fp").Get("main.go:abc"); !ok || len(issues) != 0 {
+		t.Errorf("Get after Save = %v, %v", issues, ok)
+	}
+	if _, ok := s.Bucket("code", "other").Get("main.go:abc"); ok {
+		t.Error("entries must not leak across rule fingerprints")
+	}
+}
@@ -0,0 +1,101 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/devos-os/d-guard/internal/cache"
+	"github.com/devos-os/d-guard/internal/core"
+	"github.com/devos-os/d-guard/internal/scanner"
+)
+
+// executeCached запускает файловый сканер только по файлам, которых нет в кэше.
+// Ключ записи — путь и содержимое файла, бакет — версия d-guard, правила и опции сканера.
+func executeCached(ctx context.Context, fs scanner.FileScanner, s scanner.Scanner, t scanner.Target, store *cache.Store) (core.ScannerRun, []core.Issue) {
+	rules, err := fs.RulesHash(t)
+	if err != nil {
+		// Ошибку набора правил покажет сам Scan
+		return execute(ctx, s, t)
+	}
+	bucket := store.Bucket(s.Name(), cache.Fingerprint(append([]string{s.Name(), rules}, optionParts(t.Options)...)...))
+
+	start := time.Now()
+	var hits []core.Issue
+	var misses []string
+	keys := map[string]string{} // Относительный путь → ключ записи
+	for _, f := range t.Files {
+		data, err := t.Source().ReadFile(f)
+		if err != nil {
+			// Удаленные и нечитаемые файлы сканер обработает сам
+			misses = append(misses, f)
+			continue
+		}
+		key := cache.Key(relPath(t.Root, f), data)
+		if issues, ok := bucket.Get(key); ok {
+			for _, is := range issues {
+				is.File = f
+				hits = append(hits, is)
+			}
+			continue
+		}
+		keys[relPath(t.Root, f)] = key
+		misses = append(misses, f)
+	}
+
+	run := core.ScannerRun{Name: s.Name(), Version: core.Version, Status: core.StatusOK}
+	var res []core.Issue
+	if len(misses) > 0 {
+		rescan := t
+		rescan.Files = misses
+		if run, res = execute(ctx, s, rescan); run.Status == core.StatusSkipped {
+			if len(hits) == 0 {
+				return run, nil
+			}
+			run.Status, run.Reason = core.StatusOK, ""
+		}
+		if run.Status == core.StatusOK {
+			remember(bucket, keys, res, t.Root)
+		}
+	}
+	for i := range hits {
+		hits[i].ScannerID = s.Name()
+	}
+
+	res = append(hits, res...)
+	run.Duration = time.Since(start)
+	run.Issues = len(res)
+	run.CacheHits, run.CacheMisses = len(t.Files)-len(misses), len(misses)
+	if err := bucket.Save(); err != nil {
+		fmt.Printf("  ⚠️  %s: cache not saved: %v\n", s.Name(), err)
+	}
+	return run, res
+}
+
+// remember раскладывает находки по файлам и сохраняет их, включая чистые файлы.
+// Находки без файла из keys не привязать к содержимому — такой прогон не кэшируется.
+func remember(bucket *cache.Bucket, keys map[string]string, issues []core.Issue, root string) {
+	byFile := map[string][]core.Issue{}
+	for _, is := range issues {
+		rel := relPath(root, is.File)
+		if _, ok := keys[rel]; !ok {
+			return
+		}
+		is.ScannerID = ""
+		byFile[rel] = append(byFile[rel], is)
+	}
+	for rel, key := range keys {
+		bucket.Put(key, append([]core.Issue{}, byFile[rel]...))
+	}
+}
+
+// optionParts — опции сканера в стабильном порядке для отпечатка
+func optionParts(options map[string]string) []string {
+	var parts []string
+	for k, v := range options {
+		parts = append(parts, k+"="+v)
+	}
+	sort.Strings(parts)
+	return parts
+}
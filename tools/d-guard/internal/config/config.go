@@ -0,0 +1,366 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/devos-os/d-guard/internal/core"
+	"gopkg.in/yaml.v3"
+)
+
+// FileNames — имена конфига в корне репозитория (в порядке приоритета)
+var FileNames = []string{".d-guard.yaml", ".d-guard.yml"}
+
+const currentVersion = 1
+
+// Project — содержимое .d-guard.yaml
+type Project struct {
+	Version  int                      `yaml:"version"`
+	Scanners map[string]ScannerConfig `yaml:"scanners"`
+	Paths    Paths                    `yaml:"paths"`
+	Severity map[string]string        `yaml:"severity"` // RuleID -> новый уровень
+	FailOn   string                   `yaml:"fail_on"`  // Порог для --strict/--ci
+	Secrets  Secrets                  `yaml:"secrets"`
+	Licenses Licenses                 `yaml:"licenses"`
+
+	// Путь, откуда загружен конфиг (пусто — конфига нет)
+	Path string `yaml:"-"`
+
+	failOn     core.Severity
+	thresholds map[string]core.Severity
+	overrides  map[string]core.Severity
+	include    []*regexp.Regexp
+	exclude    []*regexp.Regexp
+}
+
+// ScannerConfig — настройки одного сканера: scanners.<name>
+type ScannerConfig struct {
+	Enabled *bool             `yaml:"enabled"`
+	FailOn  string            `yaml:"fail_on"` // Порог только для этого сканера
+	Options map[string]string `yaml:",inline"` // Остальные ключи — опции сканера
+}
+
+// Paths — glob-фильтры путей относительно корня репозитория (поддерживается **)
+type Paths struct {
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
+}
+
+// Secrets — пользовательские правила для нативного сканера секретов
+type Secrets struct {
+	Rules []SecretRule `yaml:"rules"`
+}
+
+// SecretRule — правило в формате gitleaks (id, regex, secretGroup, entropy, keywords)
+type SecretRule struct {
+	ID          string   `yaml:"id"`
+	Description string   `yaml:"description"`
+	Regex       string   `yaml:"regex"`
+	SecretGroup int      `yaml:"secretGroup"` // Группа regex с секретом (0 — первая непустая)
+	Entropy     float64  `yaml:"entropy"`     // Минимальная энтропия Шеннона секрета
+	Keywords    []string `yaml:"keywords"`    // Быстрый фильтр строк
+	Allowlist   []string `yaml:"allowlist"`   // Regex значений, которые не считаются секретом
+	Severity    string   `yaml:"severity"`
+}
+
+// Licenses — политика лицензий зависимостей для сканера licenses
+type Licenses struct {
+	Allow    []string `yaml:"allow"`    // Разрешенные лицензии (SPDX ID); непустой список запрещает остальные
+	Deny     []string `yaml:"deny"`     // Запрещенные лицензии (SPDX ID)
+	Copyleft string   `yaml:"copyleft"` // warn (по умолчанию), deny или off
+	Unknown  string   `yaml:"unknown"`  // Пакеты без найденной лицензии: off (по умолчанию), warn или deny
+	Ignore   []string `yaml:"ignore"`   // Пакеты вне политики: имя или имя@версия
+}
+
+// LicenseActions — допустимые значения licenses.copyleft и licenses.unknown
+var LicenseActions = []string{"warn", "deny", "off"}
+
+// Load ищет и читает конфиг. explicit — путь из --config (обязан существовать),
+// иначе ищем FileNames в root; отсутствие файла не ошибка.
+func Load(root, explicit string) (*Project, error) {
+	path := explicit
+	if path == "" {
+		for _, name := range FileNames {
+			candidate := filepath.Join(root, name)
+			if _, err := os.Stat(candidate); err == nil {
+				path = candidate
+				break
+			}
+		}
+	}
+	if path == "" {
+		p := &Project{Version: currentVersion}
+		return p, p.Validate()
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	p.Path = path
+	return p, nil
+}
+
+// Parse разбирает и валидирует YAML. Неизвестные ключи верхнего уровня — ошибка.
+func Parse(data []byte) (*Project, error) {
+	p := &Project{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(p); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if p.Version == 0 {
+		p.Version = currentVersion
+	}
+	return p, p.Validate()
+}
+
+// Validate проверяет значения и предкомпилирует glob/regex
+func (p *Project) Validate() error {
+	var errs []string
+	add := func(format string, args ...any) { errs = append(errs, fmt.Sprintf(format, args...)) }
+
+	if p.Version != currentVersion {
+		add("version: unsupported config version %d (expected %d)", p.Version, currentVersion)
+	}
+
+	if p.FailOn != "" {
+		sev, err := core.ParseSeverity(p.FailOn)
+		if err != nil {
+			add("fail_on: %v", err)
+		}
+		p.failOn = sev
+	}
+
+	p.thresholds = map[string]core.Severity{}
+	for _, name := range sortedKeys(p.Scanners) {
+		if v := p.Scanners[name].FailOn; v != "" {
+			sev, err := core.ParseSeverity(v)
+			if err != nil {
+				add("scanners.%s.fail_on: %v", name, err)
+				continue
+			}
+			p.thresholds[name] = sev
+		}
+	}
+
+	p.overrides = map[string]core.Severity{}
+	for _, rule := range sortedKeys(p.Severity) {
+		sev, err := core.ParseSeverity(p.Severity[rule])
+		if err != nil {
+			add("severity.%s: %v", rule, err)
+			continue
+		}
+		p.overrides[rule] = sev
+	}
+
+	p.include, p.exclude = nil, nil
+	for i, g := range p.Paths.Include {
+		re, err := globToRegexp(g)
+		if err != nil {
+			add("paths.include[%d]: %v", i, err)
+			continue
+		}
+		p.include = append(p.include, re)
+	}
+	for i, g := range p.Paths.Exclude {
+		re, err := globToRegexp(g)
+		if err != nil {
+			add("paths.exclude[%d]: %v", i, err)
+			continue
+		}
+		p.exclude = append(p.exclude, re)
+	}
+
+	ids := map[string]bool{}
+	for i, r := range p.Secrets.Rules {
+		where := fmt.Sprintf("secrets.rules[%d]", i)
+		if r.ID == "" {
+			add("%s.id: required", where)
+		} else if ids[r.ID] {
+			add("%s.id: duplicate rule id %q", where, r.ID)
+		}
+		ids[r.ID] = true
+		if r.Regex == "" {
+			add("%s.regex: required", where)
+		} else if re, err := regexp.Compile(r.Regex); err != nil {
+			add("%s.regex: %v", where, err)
+		} else if r.SecretGroup < 0 || r.SecretGroup > re.NumSubexp() {
+			add("%s.secretGroup: %d out of range (regex has %d groups)", where, r.SecretGroup, re.NumSubexp())
+		}
+		if r.Entropy < 0 || r.Entropy > 8 {
+			add("%s.entropy: %v out of range 0..8", where, r.Entropy)
+		}
+		for j, a := range r.Allowlist {
+			if _, err := regexp.Compile(a); err != nil {
+				add("%s.allowlist[%d]: %v", where, j, err)
+			}
+		}
+		if r.Severity != "" {
+			if _, err := core.ParseSeverity(r.Severity); err != nil {
+				add("%s.severity: %v", where, err)
+			}
+		}
+	}
+
+	for _, opt := range []struct{ key, value string }{{"copyleft", p.Licenses.Copyleft}, {"unknown", p.Licenses.Unknown}} {
+		if opt.value != "" && !contains(LicenseActions, opt.value) {
+			add("licenses.%s: %q is not one of %s", opt.key, opt.value, strings.Join(LicenseActions, ", "))
+		}
+	}
+	for i, id := range p.Licenses.Allow {
+		if contains(p.Licenses.Deny, id) {
+			add("licenses.allow[%d]: %s is also in licenses.deny", i, id)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration:\n  - %s", strings.Join(errs, "\n  - "))
+	}
+	return nil
+}
+
+// CheckScanners сверяет секцию scanners с реестром: known — имя сканера -> поддерживаемые опции
+func (p *Project) CheckScanners(known map[string][]string) error {
+	var errs []string
+	for _, name := range sortedKeys(p.Scanners) {
+		opts, ok := known[name]
+		if !ok {
+			errs = append(errs, fmt.Sprintf("scanners.%s: unknown scanner (available: %s)", name, strings.Join(sortedKeys(known), ", ")))
+			continue
+		}
+		for _, key := range sortedKeys(p.Scanners[name].Options) {
+			if !contains(opts, key) {
+				hint := "no options supported"
+				if len(opts) > 0 {
+					hint = "supported: " + strings.Join(opts, ", ")
+				}
+				errs = append(errs, fmt.Sprintf("scanners.%s.%s: unknown option (%s)", name, key, hint))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration:\n  - %s", strings.Join(errs, "\n  - "))
+	}
+	return nil
+}
+
+// Disabled возвращает сканеры с enabled: false
+func (p *Project) Disabled() []string {
+	var names []string
+	for _, name := range sortedKeys(p.Scanners) {
+		if e := p.Scanners[name].Enabled; e != nil && !*e {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Options опции сканера (nil, если не заданы)
+func (p *Project) Options(scanner string) map[string]string {
+	return p.Scanners[scanner].Options
+}
+
+// FailThreshold порог из fail_on (пусто, если не задан)
+func (p *Project) FailThreshold() core.Severity {
+	return p.failOn
+}
+
+// ScannerThresholds пороги scanners.<name>.fail_on
+func (p *Project) ScannerThresholds() map[string]core.Severity {
+	return p.thresholds
+}
+
+// Override возвращает переопределенный уровень для правила
+func (p *Project) Override(ruleID string) (core.Severity, bool) {
+	sev, ok := p.overrides[ruleID]
+	return sev, ok
+}
+
+// PathAllowed применяет paths.include/exclude к пути относительно корня
+func (p *Project) PathAllowed(rel string) bool {
+	rel = filepath.ToSlash(rel)
+	if len(p.include) > 0 && !matchAny(p.include, rel) {
+		return false
+	}
+	return !matchAny(p.exclude, rel)
+}
+
+func matchAny(list []*regexp.Regexp, path string) bool {
+	for _, re := range list {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// globToRegexp: "**" — любое число каталогов, "*" и "?" — в пределах сегмента.
+// Шаблон без "/" применяется к имени файла на любой глубине (как в .gitignore),
+// ведущий "/" привязывает его к корню.
+func globToRegexp(glob string) (*regexp.Regexp, error) {
+	if glob == "" {
+		return nil, fmt.Errorf("empty pattern")
+	}
+	g := strings.TrimPrefix(filepath.ToSlash(glob), "./")
+	anchored := strings.HasPrefix(g, "/")
+	g = strings.TrimPrefix(g, "/")
+	if !anchored && !strings.Contains(strings.TrimSuffix(g, "/"), "/") {
+		g = "**/" + g
+	}
+	if strings.HasSuffix(g, "/") {
+		g += "**"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(g); i++ {
+		c := g[i]
+		switch {
+		case c == '*' && strings.HasPrefix(g[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case c == '*' && strings.HasPrefix(g[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	// Каталог совпадает вместе со всем содержимым
+	sb.WriteString("(?:/.*)?$")
+	return regexp.Compile(sb.String())
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,280 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/devos-os/d-guard/internal/core"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr []string // подстроки ошибки; пусто — конфиг валиден
+	}{
+		{"empty", "", nil},
+		{"full", `version: 1
+fail_on: high
+scanners:
+  semgrep:
+    enabled: false
+    fail_on: critical
+    config: p/ci
+severity:
+  generic-api-key: low
+paths:
+  include: [src/]
+  exclude: ["**/testdata/**"]
+secrets:
+  rules:
+    - id: internal-token
+      regex: 'itk_([a-z0-9]{32})'
+      secretGroup: 1
+      entropy: 3.5
+licenses:
+  deny: [AGPL-3.0-only]
+  copyleft: deny
+`, nil},
+		{"unknown top-level key", "fail_on: high\nfailOn: low\n", []string{"field failOn not found"}},
+		{"unknown nested key", "paths:\n  includes: [src/]\n", []string{"field includes not found"}},
+		{"unsupported version", "version: 2\n", []string{"version: unsupported config version 2"}},
+		{"bad severities", `fail_on: urgent
+scanners:
+  code:
+    fail_on: major
+severity:
+  some-rule: info
+`, []string{"fail_on: unknown severity \"urgent\"", "scanners.code.fail_on: unknown severity \"major\"", "severity.some-rule: unknown severity \"info\""}},
+		{"empty glob", "paths:\n  exclude: [\"\"]\n", []string{"paths.exclude[0]: empty pattern"}},
+		{"secret rules", `secrets:
+  rules:
+    - id: a
+      regex: '(x'
+    - id: a
+      regex: 'x(y)'
+      secretGroup: 2
+      entropy: 9
+    - regex: 'z'
+      allowlist: ['[']
+      severity: none
+`, []string{
+			"secrets.rules[0].regex: error parsing regexp",
+			"secrets.rules[1].id: duplicate rule id \"a\"",
+			"secrets.rules[1].secretGroup: 2 out of range (regex has 1 groups)",
+			"secrets.rules[1].entropy: 9 out of range 0..8",
+			"secrets.rules[2].id: required",
+			"secrets.rules[2].allowlist[0]: error parsing regexp",
+			"secrets.rules[2].severity: unknown severity",
+		}},
+		{"licenses", `licenses:
+  allow: [MIT, GPL-3.0-only]
+  deny: [GPL-3.0-only]
+  copyleft: block
+  unknown: ignore
+`, []string{
+			"licenses.copyleft: \"block\" is not one of warn, deny, off",
+			"licenses.unknown: \"ignore\" is not one of",
+			"licenses.allow[1]: GPL-3.0-only is also in licenses.deny",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Parse([]byte(tt.yaml))
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("Parse: %v", err)
+				}
+				if p.Version != currentVersion {
+					t.Errorf("Version = %d, want %d", p.Version, currentVersion)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Parse succeeded, want an error")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestProjectAccessors(t *testing.T) {
+	p, err := Parse([]byte(`fail_on: high
+scanners:
+  semgrep:
+    enabled: false
+    fail_on: critical
+    config: p/ci
+  code:
+    enabled: true
+severity:
+  generic-api-key: low
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := p.FailThreshold(); got != core.SevHigh {
+		t.Errorf("FailThreshold = %s", got)
+	}
+	if got := p.ScannerThresholds(); len(got) != 1 || got["semgrep"] != core.SevCritical {
+		t.Errorf("ScannerThresholds = %v", got)
+	}
+	if got := p.Disabled(); len(got) != 1 || got[0] != "semgrep" {
+		t.Errorf("Disabled = %v", got)
+	}
+	if got := p.Options("semgrep"); len(got) != 1 || got["config"] != "p/ci" {
+		t.Errorf("Options = %v", got)
+	}
+	if sev, ok := p.Override("generic-api-key"); !ok || sev != core.SevLow {
+		t.Errorf("Override = %s, %v", sev, ok)
+	}
+	if _, ok := p.Override("other"); ok {
+		t.Error("Override for a rule without severity")
+	}
+}
+
+func TestCheckScanners(t *testing.T) {
+	known := map[string][]string{"semgrep": {"config"}, "code": nil}
+	p, err := Parse([]byte(`scanners:
+  semgrep:
+    config: p/ci
+    rules: x
+  code:
+    depth: 2
+  trivvy:
+    enabled: false
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = p.CheckScanners(known)
+	if err == nil {
+		t.Fatal("CheckScanners succeeded")
+	}
+	for _, want := range []string{
+		"scanners.code.depth: unknown option (no options supported)",
+		"scanners.semgrep.rules: unknown option (supported: config)",
+		"scanners.trivvy: unknown scanner (available: code, semgrep)",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "semgrep.config") {
+		t.Errorf("supported option reported: %v", err)
+	}
+}
+
+func TestGlobToRegexp(t *testing.T) {
+	tests := []struct {
+		glob  string
+		match []string
+		miss  []string
+	}{
+		// Без "/" — имя на любой глубине
+		{"*.min.js", []string{"app.min.js", "web/static/app.min.js"}, []string{"app.js", "app.min.jsx"}},
+		{"vendor", []string{"vendor", "vendor/x/y.go", "sub/vendor/a.go"}, []string{"vendors/a.go", "myvendor"}},
+		// С "/" внутри или в начале — якорь на корне
+		{"docs/*.md", []string{"docs/a.md"}, []string{"docs/sub/a.md", "x/docs/a.md", "docs/a.mdx"}},
+		{"/src/", []string{"src/main.go", "src/a/b.go"}, []string{"lib/src/a.go", "srcs/a.go"}},
+		{"/Makefile", []string{"Makefile"}, []string{"sub/Makefile"}},
+		{"./src/", []string{"src/main.go", "lib/src/a.go"}, []string{"srcs/a.go"}},
+		// "**" — любое число каталогов, включая ноль
+		{"**/testdata/**", []string{"testdata/x", "a/b/testdata/c/d.json"}, []string{"testdatas/x", "a/testdata"}},
+		{"src/**/*.go", []string{"src/main.go", "src/a/b/c.go"}, []string{"main.go", "src/a/b/c.py"}},
+		{"build/**", []string{"build/a", "build/a/b"}, []string{"rebuild/a"}},
+		// "*" и "?" не пересекают "/"
+		{"cmd/*/main.go", []string{"cmd/app/main.go"}, []string{"cmd/main.go", "cmd/a/b/main.go"}},
+		{"file?.txt", []string{"file1.txt", "a/fileX.txt"}, []string{"file10.txt", "file/.txt"}},
+		// Метасимволы regex экранируются
+		{"a+b(1).txt", []string{"a+b(1).txt"}, []string{"aab1.txt"}},
+	}
+	for _, tt := range tests {
+		re, err := globToRegexp(tt.glob)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.glob, err)
+		}
+		for _, p := range tt.match {
+			if !re.MatchString(p) {
+				t.Errorf("%q (%s) does not match %q", tt.glob, re, p)
+			}
+		}
+		for _, p := range tt.miss {
+			if re.MatchString(p) {
+				t.Errorf("%q (%s) matches %q", tt.glob, re, p)
+			}
+		}
+	}
+}
+
+func TestPathAllowed(t *testing.T) {
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		allowed map[string]bool
+	}{
+		{"no filters", nil, nil, map[string]bool{"main.go": true, "a/b/c.go": true}},
+		{"include only", []string{"src/"}, nil, map[string]bool{"src/main.go": true, "cmd/main.go": false}},
+		{"exclude only", nil, []string{"*_test.go"}, map[string]bool{"a/x_test.go": false, "a/x.go": true}},
+		// Exclude важнее include
+		{"exclude wins", []string{"src/"}, []string{"src/gen/"}, map[string]bool{
+			"src/main.go": true, "src/gen/api.go": false, "lib/main.go": false,
+		}},
+		{"windows separators", nil, []string{"third_party/"}, map[string]bool{filepath.FromSlash("third_party/x.c"): false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Project{Version: currentVersion, Paths: Paths{Include: tt.include, Exclude: tt.exclude}}
+			if err := p.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			for path, want := range tt.allowed {
+				if got := p.PathAllowed(path); got != want {
+					t.Errorf("PathAllowed(%q) = %v, want %v", path, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	root := t.TempDir()
+
+	p, err := Load(root, "")
+	if err != nil || p.Path != "" || p.Version != currentVersion {
+		t.Fatalf("no config: %+v, %v", p, err)
+	}
+
+	if _, err := Load(root, filepath.Join(root, "missing.yaml")); err == nil {
+		t.Error("explicit --config that does not exist must fail")
+	}
+
+	write := func(name, content string) string {
+		t.Helper()
+		path := filepath.Join(root, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	yml := write(".d-guard.yml", "fail_on: low\n")
+	if p, err := Load(root, ""); err != nil || p.Path != yml {
+		t.Errorf(".yml fallback: %+v, %v", p, err)
+	}
+	yaml := write(".d-guard.yaml", "fail_on: high\n")
+	if p, err := Load(root, ""); err != nil || p.Path != yaml || p.FailThreshold() != core.SevHigh {
+		t.Errorf(".yaml takes precedence: %+v, %v", p, err)
+	}
+
+	bad := write("bad.yaml", "fail_on: urgent\n")
+	if _, err := Load(root, bad); err == nil || !strings.HasPrefix(err.Error(), bad+":") {
+		t.Errorf("error must name the file: %v", err)
+	}
+}
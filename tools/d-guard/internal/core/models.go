@@ -1,6 +1,13 @@
 package core
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Version версия d-guard (переопределяется при сборке через -ldflags "-X")
+var Version = "dev"
 
 // Severity levels
 type Severity string
@@ -12,25 +19,140 @@ const (
 	SevLow      Severity = "LOW"
 )
 
+// Severities — уровни по возрастанию важности
+var Severities = []Severity{SevLow, SevMedium, SevHigh, SevCritical}
+
+// Rank числовой вес уровня (0 для неизвестного)
+func (s Severity) Rank() int {
+	for i, v := range Severities {
+		if v == s { return i + 1 }
+	}
+	return 0
+}
+
+// AtLeast сообщает, что s не ниже порога min
+func (s Severity) AtLeast(min Severity) bool {
+	return s.Rank() >= min.Rank()
+}
+
+// ParseSeverity разбирает уровень без учета регистра ("high" -> SevHigh)
+func ParseSeverity(v string) (Severity, error) {
+	s := Severity(strings.ToUpper(strings.TrimSpace(v)))
+	if s.Rank() == 0 {
+		return "", fmt.Errorf("unknown severity %q (expected one of: LOW, MEDIUM, HIGH, CRITICAL)", v)
+	}
+	return s, nil
+}
+
 // Issue представляет одну найденную проблему
 type Issue struct {
 	Scanner     string   // Имя сканера (e.g., "Secrets", "Docker")
+	ScannerID   string   // Имя в реестре сканеров (e.g., "secrets"), проставляет оркестратор
+	RuleID      string   // Идентификатор правила (e.g., "generic-api-key", CVE-ID, check_id Semgrep)
 	Severity    Severity
 	Message     string
 	File        string
 	Line        int
 	Description string   // Подробное описание или ссылка на CVE
 	Suggestion  string   // Как исправить
+
+	Suppressed    bool   // Подавлено комментарием d-guard:ignore
+	Justification string // Обоснование подавления (reason="...")
+
+	// Режим --history: находка в коммите, File/Line — в версии файла из этого коммита
+	Commit string
+	Author string
+	Date   time.Time
 }
 
 func (i Issue) String() string {
+	if i.Commit != "" {
+		return fmt.Sprintf("[%s][%s] %s (%s:%d @ %s)", i.Scanner, i.Severity, i.Message, i.File, i.Line, ShortSHA(i.Commit))
+	}
 	return fmt.Sprintf("[%s][%s] %s (%s:%d)", i.Scanner, i.Severity, i.Message, i.File, i.Line)
 }
 
+// ShortSHA сокращает SHA коммита для вывода
+func ShortSHA(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	return sha
+}
+
+// ScanStatus итог запуска одного сканера
+type ScanStatus string
+
+const (
+	StatusOK      ScanStatus = "ok"
+	StatusSkipped ScanStatus = "skipped" // Не применим (нет бинарника, нет файлов) или отключен
+	StatusFailed  ScanStatus = "failed"  // Ошибка выполнения: результаты неполные
+	StatusTimeout ScanStatus = "timeout" // Превышен --timeout: результатов нет
+)
+
+// ScannerRun — структурированный результат запуска сканера
+type ScannerRun struct {
+	Name     string        // Имя в реестре (e.g. "semgrep")
+	Status   ScanStatus
+	Reason   string        // Причина пропуска
+	Error    string        // Текст ошибки для StatusFailed и StatusTimeout
+	Duration time.Duration
+	Version  string        // Версия инструмента (gitleaks/semgrep/trivy) или d-guard для нативных
+	Issues   int
+	CacheHits   int // Файлы, результаты по которым взяты из кэша
+	CacheMisses int // Файлы, просканированные заново
+}
+
+// Incomplete — прогон не дал полных результатов (сбой или таймаут)
+func (r ScannerRun) Incomplete() bool {
+	return r.Status == StatusFailed || r.Status == StatusTimeout
+}
+
+// Режимы сканирования (ScanInfo.Mode)
+const (
+	ModeLocal   = "local"   // Незакоммиченные изменения (аудит рабочей копии)
+	ModeStaged  = "staged"  // Только индекс: то, что войдет в коммит (pre-commit)
+	ModeCI      = "ci"      // Diff относительно базовой ветки
+	ModeAll     = "all"     // Весь репозиторий
+	ModeFiles   = "files"   // Явный список файлов (e.g. baseline)
+	ModeHistory = "history" // Добавленные строки коммитов (--history)
+	ModeRuntime = "runtime" // Запущенные контейнеры (d-guard runtime)
+)
+
+// ScanInfo метаданные прогона для отчетов
+type ScanInfo struct {
+	Root     string
+	Commit   string // HEAD (пусто, если коммитов еще нет)
+	Branch   string
+	Mode     string
+	Base     string // Базовая ветка для ModeCI или диапазон коммитов для ModeHistory
+	Files    int    // Сколько файлов передано сканерам (0 при ModeAll)
+	Started  time.Time
+	Duration time.Duration
+}
+
 // Config конфигурация запуска
 type Config struct {
-	IsCI        bool     // Режим CI/CD (без UI, strict exit codes)
-	ScanAll     bool     // Сканировать всё или только изменения?
-	BaseBranch  string   // С чем сравнивать (обычно main или master)
-	OutputFmt   string   // json, sarif, table
+	IsCI         bool     // Режим CI/CD (без UI, strict exit codes)
+	ScanAll      bool     // Сканировать всё или только изменения?
+	BaseBranch   string   // С чем сравнивать (обычно main или master)
+	OutputFmt    string   // Формат отчета --report: html, sarif, json, junit
+	ConfigFile   string   // Путь к .d-guard.yaml (по умолчанию ищется в корне репо)
+	Files        []string // Явный список файлов (вместо diff), e.g. для baseline
+	Only         []string // Запускать только эти сканеры (имена из реестра)
+	Skip         []string // Исключить сканеры
+	Staged       bool     // Только staged-изменения (pre-commit hook)
+	ChangedLines bool     // Показывать только проблемы на измененных строках (ханки diff)
+	History      bool     // Сканировать историю git вместо рабочей копии
+	HistoryRange string   // Диапазон коммитов для --history (пусто — все ветки)
+	Offline      bool     // Не ходить в сеть: Trivy без обновления БД, SCA только по локальному зеркалу OSV
+	NoCache      bool     // Не использовать кэш результатов нативных сканеров (--no-cache)
+	Workers      int      // Размер пула нативных модулей (0 — число ядер)
+	Timeout      time.Duration            // Лимит времени сканера по умолчанию (0 — без лимита)
+	Timeouts     map[string]time.Duration // Лимиты отдельных сканеров (--timeout semgrep=2m)
+
+	// d-guard runtime
+	RuntimeHost  string // API Docker/Podman (пусто — DOCKER_HOST, CONTAINER_HOST или известные сокеты)
+	RuntimeAll   bool   // Включая остановленные контейнеры
+	InspectFile  string // Офлайн-аудит дампа 'docker inspect' вместо живого API
 }
\ No newline at end of file
@@ -0,0 +1,134 @@
+package gate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/devos-os/d-guard/internal/config"
+	"github.com/devos-os/d-guard/internal/core"
+	"github.com/devos-os/d-guard/internal/scanner"
+)
+
+// Коды завершения d-guard (документированы в `d-guard --help`)
+const (
+	ExitOK          = 0   // Нет проблем выше порога (или gating выключен)
+	ExitFindings    = 1   // Найдены проблемы на уровне порога или выше
+	ExitError       = 2   // Ошибка выполнения: сканер упал, неверный конфиг, не git-репозиторий
+	ExitInterrupted = 130 // Прервано Ctrl+C / SIGTERM (128 + SIGINT)
+)
+
+// Policy — пороги, при которых прогон считается проваленным
+type Policy struct {
+	Enabled    bool                     // --strict/--ci/--fail-on
+	Default    core.Severity            // Общий порог (пусто — любая проблема)
+	PerScanner map[string]core.Severity // scanner (имя в реестре) -> порог
+}
+
+// NewPolicy объединяет CLI и .d-guard.yaml: CLI всегда важнее.
+// Имена сканеров сверяются с реестром: опечатка иначе молча отключила бы порог.
+func NewPolicy(enabled bool, failOn string, perScanner map[string]string, project *config.Project) (Policy, error) {
+	p := Policy{Enabled: enabled || failOn != "", PerScanner: map[string]core.Severity{}}
+
+	p.Default = project.FailThreshold()
+	if failOn != "" {
+		sev, err := core.ParseSeverity(failOn)
+		if err != nil {
+			return p, fmt.Errorf("--fail-on: %w", err)
+		}
+		p.Default = sev
+	}
+
+	known := scanner.Names()
+	thresholds := project.ScannerThresholds()
+	for _, name := range sortedKeys(thresholds) {
+		if !contains(known, name) {
+			return p, fmt.Errorf("scanners.%s.fail_on: unknown scanner %q (available: %s)", name, name, strings.Join(known, ", "))
+		}
+		p.PerScanner[name] = thresholds[name]
+	}
+	for _, name := range sortedKeys(perScanner) {
+		if !contains(known, name) {
+			return p, fmt.Errorf("--fail-on-scanner %s: unknown scanner %q (available: %s)", name, name, strings.Join(known, ", "))
+		}
+		sev, err := core.ParseSeverity(perScanner[name])
+		if err != nil {
+			return p, fmt.Errorf("--fail-on-scanner %s: %w", name, err)
+		}
+		p.PerScanner[name] = sev
+	}
+	if len(perScanner) > 0 {
+		p.Enabled = true
+	}
+	return p, nil
+}
+
+// Threshold возвращает порог для сканера
+func (p Policy) Threshold(scanner string) core.Severity {
+	if sev, ok := p.PerScanner[scanner]; ok {
+		return sev
+	}
+	return p.Default
+}
+
+// Failing возвращает активные проблемы, которые "ломают" прогон
+func (p Policy) Failing(issues []core.Issue) []core.Issue {
+	if !p.Enabled {
+		return nil
+	}
+	var failing []core.Issue
+	for _, is := range issues {
+		if is.Suppressed {
+			continue
+		}
+		min := p.Threshold(is.ScannerID)
+		if min == "" || is.Severity.AtLeast(min) {
+			failing = append(failing, is)
+		}
+	}
+	return failing
+}
+
+// Describe — человекочитаемое описание порогов для итоговой сводки
+func (p Policy) Describe() string {
+	if !p.Enabled {
+		return "report only (use --strict or --fail-on to gate)"
+	}
+	def := string(p.Default)
+	if def == "" {
+		def = "any severity"
+	} else {
+		def += "+"
+	}
+	if len(p.PerScanner) == 0 {
+		return "fail on " + def
+	}
+	var names []string
+	for name := range p.PerScanner {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var parts []string
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s: %s+", name, p.PerScanner[name]))
+	}
+	return fmt.Sprintf("fail on %s (%s)", def, strings.Join(parts, ", "))
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package gate
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/devos-os/d-guard/internal/config"
+	"github.com/devos-os/d-guard/internal/core"
+	"github.com/devos-os/d-guard/internal/scanner"
+)
+
+type fake string
+
+func (f fake) Name() string                                             { return string(f) }
+func (fake) Applicable(scanner.Target) bool                             { return true }
+func (fake) Scan(context.Context, scanner.Target) ([]core.Issue, error) { return nil, nil }
+
+func init() {
+	scanner.Register(fake("secrets"))
+	scanner.Register(fake("semgrep"))
+}
+
+func TestNewPolicyScannerNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		cli     map[string]string
+		want    map[string]core.Severity
+		wantErr string
+	}{
+		{"cli", "", map[string]string{"secrets": "high"}, map[string]core.Severity{"secrets": core.SevHigh}, ""},
+		{"config overridden by cli", "scanners:\n  semgrep:\n    fail_on: low\n  secrets:\n    fail_on: low\n",
+			map[string]string{"secrets": "critical"},
+			map[string]core.Severity{"semgrep": core.SevLow, "secrets": core.SevCritical}, ""},
+		{"unknown cli scanner", "", map[string]string{"secret": "high"}, nil,
+			`--fail-on-scanner secret: unknown scanner "secret" (available: secrets, semgrep)`},
+		{"unknown config scanner", "scanners:\n  semgerp:\n    fail_on: high\n", nil, nil,
+			`scanners.semgerp.fail_on: unknown scanner "semgerp"`},
+		{"bad severity", "", map[string]string{"secrets": "urgent"}, nil, "--fail-on-scanner secrets: unknown severity"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project, err := config.Parse([]byte(tt.yaml))
+			if err != nil {
+				t.Fatal(err)
+			}
+			p, err := NewPolicy(false, "", tt.cli, project)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(p.PerScanner) != len(tt.want) {
+				t.Errorf("PerScanner = %v, want %v", p.PerScanner, tt.want)
+			}
+			for name, sev := range tt.want {
+				if p.PerScanner[name] != sev {
+					t.Errorf("PerScanner[%s] = %s, want %s", name, p.PerScanner[name], sev)
+				}
+			}
+		})
+	}
+}
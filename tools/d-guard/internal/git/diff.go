@@ -2,6 +2,7 @@ package git
 
 import (
 	"fmt"
+	"io"
 	"os/exec"
 	"path/filepath"
 	"strings"
@@ -71,6 +72,38 @@ func GetChangedFiles(isCI bool, baseBranch string) ([]string, error) {
 	return absFiles, nil
 }
 
+// ListFiles возвращает абсолютные пути всех отслеживаемых и новых (не игнорируемых) файлов
+func ListFiles(root string) ([]string, error) {
+	out, err := runGit(root, "ls-files", "--cached", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, f := range parseOutput(out) {
+		if f == "" { continue }
+		files = append(files, filepath.Join(root, f))
+	}
+	return files, nil
+}
+
+// HeadCommit возвращает SHA текущего коммита (пусто в репозитории без коммитов)
+func HeadCommit(root string) string {
+	out, err := runGit(root, "rev-parse", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+// CurrentBranch возвращает имя текущей ветки (пусто для detached HEAD)
+func CurrentBranch(root string) string {
+	out, err := runGit(root, "symbolic-ref", "--short", "-q", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
 func runGit(dir string, args ...string) (string, error) {
 	cmd := exec.Command("git", args...)
 	cmd.Dir = dir // Важно: выполняем команды от корня репо
@@ -80,4 +113,134 @@ func runGit(dir string, args ...string) (string, error) {
 
 func parseOutput(raw string) []string {
 	return strings.Split(strings.TrimSpace(raw), "\n")
-}
\ No newline at end of file
+}
+// LineRange — диапазон строк новой версии файла (включительно)
+type LineRange struct {
+	Start, End int
+}
+
+// Hunks — измененные строки одного файла
+type Hunks struct {
+	Whole  bool // Новый неотслеживаемый файл: изменены все строки
+	Ranges []LineRange
+}
+
+// Contains сообщает, что строка line попадает в изменения
+func (h *Hunks) Contains(line int) bool {
+	if h.Whole {
+		return true
+	}
+	for _, r := range h.Ranges {
+		if line >= r.Start && line <= r.End {
+			return true
+		}
+	}
+	return false
+}
+
+// GetChangedLines разбирает ханки `git diff -U0` для тех же изменений, что и
+// GetChangedFiles. Ключ — путь относительно корня репозитория.
+func GetChangedLines(isCI bool, baseBranch string) (map[string]*Hunks, error) {
+	root, err := GetRepoRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	if isCI {
+		if baseBranch == "" {
+			baseBranch = "origin/main"
+		}
+		changes := map[string]*Hunks{}
+		if err := diffLines(root, changes, baseBranch+"...HEAD"); err != nil {
+			return nil, err
+		}
+		return changes, nil
+	}
+	return localChangedLines(root)
+}
+
+// localChangedLines — staged и unstaged изменения одним `git diff -U0 HEAD`: номера строк
+// обоих в координатах рабочей копии (по отдельности staged-ханки считались бы от индекса
+// и сдвигались бы при unstaged-правках выше). Без коммитов сравниваем с пустым деревом.
+func localChangedLines(root string) (map[string]*Hunks, error) {
+	base := "HEAD"
+	if HeadCommit(root) == "" {
+		tree, err := runGit(root, "hash-object", "-t", "tree", "--stdin")
+		if err != nil {
+			return nil, err
+		}
+		base = strings.TrimSpace(tree)
+	}
+	changes := map[string]*Hunks{}
+	if err := diffLines(root, changes, base); err != nil {
+		return nil, err
+	}
+	out, _ := runGit(root, "ls-files", "--others", "--exclude-standard")
+	for _, f := range parseOutput(out) {
+		if f != "" {
+			changes[f] = &Hunks{Whole: true}
+		}
+	}
+	return changes, nil
+}
+
+// GetStagedFiles возвращает абсолютные пути файлов в индексе (то, что войдет в коммит)
+func GetStagedFiles() ([]string, error) {
+	root, err := GetRepoRoot()
+	if err != nil {
+		return nil, err
+	}
+	out, err := runGit(root, "diff", "--name-only", "--cached", "--diff-filter=d")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, f := range parseOutput(out) {
+		if f != "" {
+			files = append(files, filepath.Join(root, f))
+		}
+	}
+	return files, nil
+}
+
+// GetStagedLines — ханки только staged-изменений (git diff --cached -U0)
+func GetStagedLines() (map[string]*Hunks, error) {
+	root, err := GetRepoRoot()
+	if err != nil {
+		return nil, err
+	}
+	changes := map[string]*Hunks{}
+	if err := diffLines(root, changes, "--cached"); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// diffLines добавляет в changes добавленные строки из `git diff -U0 <args>`
+func diffLines(root string, changes map[string]*Hunks, args ...string) error {
+	out, err := runGit(root, append([]string{"-c", "core.quotePath=false", "diff", "-U0", "--no-color", "--no-ext-diff", "--diff-filter=d"}, args...)...)
+	if err != nil {
+		return err
+	}
+	return collectHunks(strings.NewReader(out), changes)
+}
+
+// collectHunks добавляет в changes добавленные строки из вывода `git diff -U0`
+func collectHunks(r io.Reader, changes map[string]*Hunks) error {
+	return parsePatches(r, func(_ Commit, p FilePatch) error {
+		h, ok := changes[p.Path]
+		if !ok {
+			h = &Hunks{}
+			changes[p.Path] = h
+		}
+		for _, l := range p.Lines {
+			// Соседние строки склеиваем в один диапазон
+			if n := len(h.Ranges); n > 0 && l.N >= h.Ranges[n-1].Start && l.N <= h.Ranges[n-1].End+1 {
+				h.Ranges[n-1].End = max(h.Ranges[n-1].End, l.N)
+				continue
+			}
+			h.Ranges = append(h.Ranges, LineRange{Start: l.N, End: l.N})
+		}
+		return nil
+	})
+}
@@ -0,0 +1,210 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseHunk(t *testing.T) {
+	tests := []struct {
+		line                      string
+		start, oldCount, newCount int
+	}{
+		{"@@ -10,2 +10,3 @@ func main() {", 10, 2, 3},
+		{"@@ -5 +5 @@", 5, 1, 1},
+		{"@@ -0,0 +1,4 @@", 1, 0, 4}, // Новый файл
+		{"@@ -7,3 +6,0 @@", 6, 3, 0}, // Только удаление
+		{"@@ -1 +1,2 @@ package x", 1, 1, 2},
+		{"@@ broken", 0, 0, 0},
+	}
+	for _, tt := range tests {
+		start, oldCount, newCount := parseHunk(tt.line)
+		if start != tt.start || oldCount != tt.oldCount || newCount != tt.newCount {
+			t.Errorf("parseHunk(%q) = %d, %d, %d; want %d, %d, %d",
+				tt.line, start, oldCount, newCount, tt.start, tt.oldCount, tt.newCount)
+		}
+	}
+}
+
+// diffU0 — вывод `git diff -U0`: изменение, вставка двух соседних ханков,
+// удаление, строка "++" (не заголовок файла) и удаленный файл
+const diffU0 = `diff --git a/go.sum b/go.sum
+index 1111111..2222222 100644
+--- a/go.sum
++++ b/go.sum
+@@ -3 +3 @@ example.com/a v1.0.0 h1:x
+-example.com/b v1.0.0 h1:old
++example.com/b v1.0.1 h1:new
+@@ -40,0 +41,2 @@ example.com/c v1.0.0 h1:x
++example.com/d v0.1.0 h1:d
++example.com/d v0.1.0/go.mod h1:d
+@@ -42,0 +44 @@
++++ not a header
+@@ -60,2 +61,0 @@
+-removed
+-removed
+\ No newline at end of file
+diff --git a/old.txt b/old.txt
+deleted file mode 100644
+--- a/old.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-bye
+diff --git a/dir/new file.go b/dir/new file.go
+new file mode 100644
+--- /dev/null
++++ b/dir/new file.go
+@@ -0,0 +1,2 @@
++package dir
++
+`
+
+func TestCollectHunks(t *testing.T) {
+	changes := map[string]*Hunks{}
+	if err := collectHunks(strings.NewReader(diffU0), changes); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]*Hunks{
+		"go.sum":          {Ranges: []LineRange{{3, 3}, {41, 42}, {44, 44}}},
+		"dir/new file.go": {Ranges: []LineRange{{1, 2}}},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		for path, h := range changes {
+			t.Logf("%s: %+v", path, *h)
+		}
+		t.Fatalf("collectHunks: unexpected hunks")
+	}
+
+	h := changes["go.sum"]
+	for line, in := range map[int]bool{2: false, 3: true, 40: false, 41: true, 42: true, 43: false, 44: true, 61: false} {
+		if h.Contains(line) != in {
+			t.Errorf("Contains(%d) = %v, want %v", line, !in, in)
+		}
+	}
+	if !(&Hunks{Whole: true}).Contains(1000) {
+		t.Error("Whole hunks must contain every line")
+	}
+}
+
+// Склейка диапазонов: staged и unstaged ханки одного файла накладываются
+func TestCollectHunksMerge(t *testing.T) {
+	changes := map[string]*Hunks{}
+	first := "--- a/x\n+++ b/x\n@@ -1,0 +2,2 @@\n+a\n+b\n"
+	second := "--- a/x\n+++ b/x\n@@ -3,0 +4 @@\n+c\n"
+	for _, d := range []string{first, second} {
+		if err := collectHunks(strings.NewReader(d), changes); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := changes["x"].Ranges, []LineRange{{2, 4}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ranges = %v, want %v", got, want)
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	root := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "commit.gpgsign=false"}, args...)...)
+		cmd.Dir = root
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run("init", "-q")
+	write("a.txt", "1\n2\n3\n4\n5\n")
+	run("add", "a.txt")
+	run("commit", "-qm", "init")
+
+	write("a.txt", "1\ntwo\n3\n4\n5\n6\n")
+	run("add", "a.txt")
+	write("a.txt", "1\ntwo\n3\n4\n5\n6\n7\n")
+
+	staged := map[string]*Hunks{}
+	if err := diffLines(root, staged, "--cached"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := staged["a.txt"].Ranges, []LineRange{{2, 2}, {6, 6}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("staged ranges = %v, want %v", got, want)
+	}
+
+}
+
+// Staged-правка на строке 10 и три unstaged-строки в начале: staged-строка в рабочей копии
+// уже 13-я, и все диапазоны должны быть в координатах рабочей копии
+func TestLocalChangedLines(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	root := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "commit.gpgsign=false"}, args...)...)
+		cmd.Dir = root
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	lines := func(from, to int, edit map[int]string) string {
+		var b strings.Builder
+		for i := from; i <= to; i++ {
+			if s, ok := edit[i]; ok {
+				b.WriteString(s + "\n")
+				continue
+			}
+			b.WriteString(strconv.Itoa(i) + "\n")
+		}
+		return b.String()
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run("init", "-q")
+	write("a.txt", lines(1, 20, nil))
+	run("add", "a.txt")
+
+	// Репозиторий без коммитов: сравнение с пустым деревом
+	changes, err := localChangedLines(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := changes["a.txt"].Ranges, []LineRange{{1, 20}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("before first commit: ranges = %v, want %v", got, want)
+	}
+
+	run("commit", "-qm", "init")
+	write("a.txt", lines(1, 20, map[int]string{10: "staged"}))
+	run("add", "a.txt")
+	write("a.txt", "x\ny\nz\n"+lines(1, 20, map[int]string{10: "staged"}))
+	write("new.txt", "untracked\n")
+
+	if changes, err = localChangedLines(root); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := changes["a.txt"].Ranges, []LineRange{{1, 3}, {13, 13}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("staged+unstaged ranges = %v, want %v", got, want)
+	}
+	if h := changes["new.txt"]; h == nil || !h.Whole {
+		t.Errorf("untracked file: %+v", h)
+	}
+}
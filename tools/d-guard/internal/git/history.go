@@ -0,0 +1,175 @@
+package git
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Commit — метаданные коммита из git log
+type Commit struct {
+	SHA    string
+	Author string // "Name <email>"
+	Date   time.Time
+}
+
+// AddedLine — строка, добавленная коммитом (номер — в новой версии файла)
+type AddedLine struct {
+	N    int
+	Text string
+}
+
+// FilePatch — добавленные строки одного файла в коммите
+type FilePatch struct {
+	Path  string // Относительно корня репозитория
+	Lines []AddedLine
+}
+
+// commitMarker отделяет заголовок коммита от патча (NUL не встречается в тексте diff).
+// В аргументы git его передаем как %x00: NUL в argv недопустим.
+const commitMarker = "\x00commit\x00"
+
+// WalkHistory обходит коммиты из rng (пусто — все ветки и теги, --all) и вызывает fn.
+// rng — аргументы git log через пробел (e.g. "A..B" или "<sha> --not --remotes")
+// для каждого измененного файла с добавленными строками. Удаленные строки
+// не интересны: секрет, который удаляют, был добавлен в более раннем коммите.
+func WalkHistory(ctx context.Context, root, rng string, fn func(c Commit, p FilePatch) error) error {
+	args := []string{"-c", "core.quotePath=false", "log", "-p", "-U0", "--no-color", "--no-ext-diff",
+		"--no-renames", "--format=%x00commit%x00%H%x00%an <%ae>%x00%aI"}
+	if rng == "" {
+		args = append(args, "--all")
+	} else {
+		args = append(append(args, strings.Fields(rng)...), "--")
+	}
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = root
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	perr := parsePatches(out, fn)
+	if perr != nil {
+		// Остановка по ошибке fn: дочитывать вывод не нужно
+		cmd.Process.Kill()
+		cmd.Wait()
+		return perr
+	}
+	if err := cmd.Wait(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("git log %s: %s", rng, msg)
+		}
+		return fmt.Errorf("git log %s: %w", rng, err)
+	}
+	return nil
+}
+
+// parsePatches разбирает вывод `git log -p -U0`. Число строк в ханке берется из
+// заголовка @@, поэтому добавленная строка "++ x" не путается с заголовком "+++ b/x".
+func parsePatches(r io.Reader, fn func(c Commit, p FilePatch) error) error {
+	br := bufio.NewReaderSize(r, 1<<20)
+
+	var commit Commit
+	var patch *FilePatch
+	var newLine, oldLeft, newLeft int
+
+	flush := func() error {
+		if patch != nil && len(patch.Lines) > 0 {
+			if err := fn(commit, *patch); err != nil {
+				return err
+			}
+		}
+		patch = nil
+		return nil
+	}
+
+	for {
+		raw, err := br.ReadString('\n')
+		if raw == "" && err != nil {
+			break
+		}
+		line := strings.TrimSuffix(strings.TrimSuffix(raw, "\n"), "\r")
+
+		// Внутри ханка: строки считаем по заголовку
+		if oldLeft > 0 || newLeft > 0 {
+			switch {
+			case strings.HasPrefix(line, "+") && newLeft > 0:
+				if patch != nil {
+					patch.Lines = append(patch.Lines, AddedLine{N: newLine, Text: line[1:]})
+				}
+				newLine++
+				newLeft--
+				continue
+			case strings.HasPrefix(line, "-") && oldLeft > 0:
+				oldLeft--
+				continue
+			case strings.HasPrefix(line, `\`): // "\ No newline at end of file"
+				continue
+			}
+			oldLeft, newLeft = 0, 0
+		}
+
+		switch {
+		case strings.HasPrefix(line, commitMarker):
+			if err := flush(); err != nil {
+				return err
+			}
+			parts := strings.SplitN(strings.TrimPrefix(line, commitMarker), "\x00", 3)
+			commit = Commit{SHA: parts[0]}
+			if len(parts) == 3 {
+				commit.Author = parts[1]
+				commit.Date, _ = time.Parse(time.RFC3339, parts[2])
+			}
+		case strings.HasPrefix(line, "diff --git "):
+			if err := flush(); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "+++ "):
+			path := strings.TrimPrefix(line, "+++ ")
+			if path == "/dev/null" {
+				patch = nil // Файл удален
+				continue
+			}
+			patch = &FilePatch{Path: strings.TrimPrefix(path, "b/")}
+		case strings.HasPrefix(line, "@@ "):
+			newLine, oldLeft, newLeft = parseHunk(line)
+		}
+
+		if err != nil {
+			break
+		}
+	}
+	return flush()
+}
+
+// parseHunk: "@@ -a[,b] +c[,d] @@" -> начало новой версии, число удаленных и добавленных строк
+func parseHunk(line string) (start, oldCount, newCount int) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return 0, 0, 0
+	}
+	_, oldCount = parseRange(strings.TrimPrefix(fields[1], "-"))
+	start, newCount = parseRange(strings.TrimPrefix(fields[2], "+"))
+	return start, oldCount, newCount
+}
+
+func parseRange(s string) (start, count int) {
+	count = 1
+	if a, b, ok := strings.Cut(s, ","); ok {
+		s = a
+		count, _ = strconv.Atoi(b)
+	}
+	start, _ = strconv.Atoi(s)
+	return start, count
+}
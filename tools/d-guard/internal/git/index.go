@@ -0,0 +1,83 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// StagedBlobs читает содержимое файлов из индекса (то, что войдет в коммит),
+// а не из рабочей копии. files — абсолютные пути; ключи результата — они же.
+// Один процесс `git cat-file --batch` на все файлы.
+func StagedBlobs(root string, files []string) (map[string][]byte, error) {
+	blobs := map[string][]byte{}
+	if len(files) == 0 {
+		return blobs, nil
+	}
+
+	var in bytes.Buffer
+	for _, f := range files {
+		rel, err := filepath.Rel(root, f)
+		if err != nil {
+			return nil, err
+		}
+		// ":<path>" — объект из индекса (stage 0)
+		fmt.Fprintf(&in, ":%s\n", filepath.ToSlash(rel))
+	}
+
+	cmd := exec.Command("git", "cat-file", "--batch")
+	cmd.Dir = root
+	cmd.Stdin = &in
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git cat-file: %w", err)
+	}
+
+	// Ответ на каждый запрос: "<sha> <type> <size>\n<content>\n" или "<query> missing\n"
+	r := bufio.NewReader(bytes.NewReader(out))
+	for _, f := range files {
+		header, err := r.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("git cat-file: unexpected end of output")
+		}
+		fields := strings.Fields(header)
+		if len(fields) != 3 {
+			continue // missing / ambiguous: файла нет в индексе
+		}
+		size, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("git cat-file: bad header %q", strings.TrimSpace(header))
+		}
+		data := make([]byte, size)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, fmt.Errorf("git cat-file: %w", err)
+		}
+		r.ReadByte() // Завершающий перевод строки
+		if fields[1] == "blob" {
+			blobs[filepath.Clean(f)] = data
+		}
+	}
+	return blobs, nil
+}
+
+// CheckoutIndex выгружает staged-версии файлов в dir (для внешних инструментов,
+// которые читают файлы с диска сами). files — абсолютные пути внутри root.
+func CheckoutIndex(root, dir string, files []string) error {
+	args := []string{"checkout-index", "--force", "--prefix=" + strings.TrimSuffix(dir, "/") + "/", "--"}
+	for _, f := range files {
+		rel, err := filepath.Rel(root, f)
+		if err != nil {
+			return err
+		}
+		args = append(args, filepath.ToSlash(rel))
+	}
+	if _, err := runGit(root, args...); err != nil {
+		return fmt.Errorf("git checkout-index: %w", err)
+	}
+	return nil
+}
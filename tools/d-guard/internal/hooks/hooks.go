@@ -0,0 +1,203 @@
+package hooks
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Types — поддерживаемые git hooks
+var Types = []string{"pre-commit", "pre-push"}
+
+// marker помечает hook, установленный d-guard (по нему uninstall отличает наш файл от чужого)
+const marker = "# d-guard:managed-hook"
+
+// ChainedSuffix — суффикс, с которым сохраняется существующий hook; наш hook вызывает его первым
+const ChainedSuffix = ".pre-d-guard"
+
+// State состояние одного hook
+type State string
+
+const (
+	StateMissing   State = "not installed"
+	StateInstalled State = "installed"
+	StateChained   State = "installed (chains existing hook)"
+	StateForeign   State = "foreign hook (not managed by d-guard)"
+	StateRestored  State = "removed, previous hook restored"
+)
+
+// Status — результат проверки hook
+type Status struct {
+	Type  string
+	Path  string
+	State State
+}
+
+// Dir возвращает каталог hooks с учетом core.hooksPath и worktree
+func Dir(root string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--git-path", "hooks")
+	cmd.Dir = root
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve git hooks directory: %w", err)
+	}
+	dir := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(root, dir)
+	}
+	return dir, nil
+}
+
+// Install записывает hook. Чужой hook переименовывается в <hook>.pre-d-guard
+// и вызывается перед d-guard; повторная установка просто обновляет скрипт.
+func Install(dir, hookType, binary string) (Status, error) {
+	path := filepath.Join(dir, hookType)
+	script, err := Script(hookType, binary)
+	if err != nil {
+		return Status{}, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return Status{}, err
+	}
+
+	state := StateInstalled
+	if data, err := os.ReadFile(path); err == nil && !managed(data) {
+		chained := path + ChainedSuffix
+		if _, err := os.Stat(chained); err == nil {
+			return Status{}, fmt.Errorf("%s: both a foreign hook and %s exist, resolve manually", path, filepath.Base(chained))
+		}
+		if err := os.Rename(path, chained); err != nil {
+			return Status{}, err
+		}
+	}
+	if _, err := os.Stat(path + ChainedSuffix); err == nil {
+		state = StateChained
+	}
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		return Status{}, err
+	}
+	return Status{Type: hookType, Path: path, State: state}, nil
+}
+
+// Uninstall удаляет наш hook и возвращает на место сохраненный чужой
+func Uninstall(dir, hookType string) (Status, error) {
+	path := filepath.Join(dir, hookType)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Status{Type: hookType, Path: path, State: StateMissing}, nil
+	}
+	if err != nil {
+		return Status{}, err
+	}
+	if !managed(data) {
+		return Status{Type: hookType, Path: path, State: StateForeign}, nil
+	}
+	if err := os.Remove(path); err != nil {
+		return Status{}, err
+	}
+	if _, err := os.Stat(path + ChainedSuffix); err == nil {
+		if err := os.Rename(path+ChainedSuffix, path); err != nil {
+			return Status{}, err
+		}
+		return Status{Type: hookType, Path: path, State: StateRestored}, nil
+	}
+	return Status{Type: hookType, Path: path, State: StateMissing}, nil
+}
+
+// Check сообщает состояние hook
+func Check(dir, hookType string) Status {
+	path := filepath.Join(dir, hookType)
+	st := Status{Type: hookType, Path: path, State: StateMissing}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return st
+	}
+	if !managed(data) {
+		st.State = StateForeign
+		return st
+	}
+	st.State = StateInstalled
+	if _, err := os.Stat(path + ChainedSuffix); err == nil {
+		st.State = StateChained
+	}
+	return st
+}
+
+func managed(data []byte) bool {
+	return strings.Contains(string(data), marker)
+}
+
+// Script генерирует POSIX sh скрипт hook. binary — путь к d-guard
+// (если d-guard пропадет из PATH, hook не должен молча пропускать проверку).
+func Script(hookType, binary string) (string, error) {
+	var body string
+	switch hookType {
+	case "pre-commit":
+		// Только то, что реально попадет в коммит: индекс, а не рабочая копия
+		body = `"$DGUARD" --staged --strict`
+	case "pre-push":
+		// Секреты в отправляемых коммитах (stdin: <local ref> <local sha> <remote ref> <remote sha>)
+		body = `zero=$(git hash-object --stdin </dev/null | tr '0-9a-f' '0')
+status=0
+printf '%s\n' "$input" | while read -r local_ref local_sha remote_ref remote_sha; do
+	[ -z "$local_sha" ] && continue
+	[ "$local_sha" = "$zero" ] && continue # Удаление ветки
+	# Новая ветка или remote_sha нет локально (чужой push, force-push): коммиты, которых нет ни в одной remote-ветке
+	if [ "$remote_sha" = "$zero" ] || ! git cat-file -e "$remote_sha^{commit}" 2>/dev/null; then
+		range="$local_sha --not --remotes"
+	else
+		range="$remote_sha..$local_sha"
+	fi
+	"$DGUARD" --history-range "$range" --strict || exit 1
+done || status=1
+exit $status`
+	default:
+		return "", fmt.Errorf("unsupported hook type %q (supported: %s)", hookType, strings.Join(Types, ", "))
+	}
+
+	return fmt.Sprintf(`#!/bin/sh
+%s (%s). Remove with: d-guard hook uninstall
+DGUARD=%s
+command -v "$DGUARD" >/dev/null 2>&1 || DGUARD=d-guard
+
+hook_dir=$(dirname "$0")
+input=""
+[ "%s" = "pre-push" ] && input=$(cat)
+if [ -x "$hook_dir/%s%s" ]; then
+	printf '%%s\n' "$input" | "$hook_dir/%s%s" "$@" || exit $?
+fi
+
+%s
+`, marker, hookType, shellQuote(binary), hookType, hookType, ChainedSuffix, hookType, ChainedSuffix, body), nil
+}
+
+// PreCommitHooksYAML — определения для pre-commit framework (.pre-commit-hooks.yaml).
+// language: system — d-guard должен быть установлен в системе.
+func PreCommitHooksYAML() string {
+	return `# Generated by 'd-guard hook pre-commit-config'
+- id: d-guard
+  name: d-guard (staged changes)
+  description: Security scan of staged changes (secrets, SAST, Dockerfile checks)
+  entry: d-guard --staged --strict
+  language: system
+  pass_filenames: false
+  always_run: true
+  stages: [pre-commit]
+
+- id: d-guard-push
+  name: d-guard (secrets in pushed commits)
+  description: Scan commits that are about to be pushed for secrets
+  entry: sh -c 'd-guard --history-range "${PRE_COMMIT_FROM_REF:+$PRE_COMMIT_FROM_REF..}${PRE_COMMIT_TO_REF:-HEAD}" --strict'
+  language: system
+  pass_filenames: false
+  always_run: true
+  stages: [pre-push]
+`
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,217 @@
+package hooks
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// gitRepo — пустой репозиторий без влияния глобальных настроек git (core.hooksPath и т.п.)
+func gitRepo(t *testing.T) (root string, git func(args ...string) string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	root = t.TempDir()
+	git = func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "commit.gpgsign=false"}, args...)...)
+		cmd.Dir = root
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q")
+	return root, git
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestInstallUninstall(t *testing.T) {
+	root, _ := gitRepo(t)
+	dir, err := Dir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(root, ".git", "hooks"); dir != want {
+		t.Fatalf("Dir = %s, want %s", dir, want)
+	}
+	path := filepath.Join(dir, "pre-commit")
+	foreign := "#!/bin/sh\necho lint\n"
+	writeFile(t, path, foreign)
+	if st := Check(dir, "pre-commit"); st.State != StateForeign {
+		t.Errorf("Check before install = %s", st.State)
+	}
+
+	// Чужой hook сохраняется как .pre-d-guard и вызывается из нашего
+	st, err := Install(dir, "pre-commit", "/usr/local/bin/d-guard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.State != StateChained || st.Path != path {
+		t.Errorf("Install = %+v", st)
+	}
+	if got := readFile(t, path+ChainedSuffix); got != foreign {
+		t.Errorf("chained hook = %q, want the original", got)
+	}
+	if got := readFile(t, path); !strings.Contains(got, marker) || !strings.Contains(got, "DGUARD='/usr/local/bin/d-guard'") {
+		t.Errorf("hook script:\n%s", got)
+	}
+
+	// Повторная установка обновляет скрипт и не трогает сохраненный hook
+	if st, err := Install(dir, "pre-commit", "/opt/d-guard"); err != nil || st.State != StateChained {
+		t.Fatalf("reinstall = %+v, %v", st, err)
+	}
+	if got := readFile(t, path); !strings.Contains(got, "DGUARD='/opt/d-guard'") {
+		t.Errorf("reinstall did not update the script:\n%s", got)
+	}
+	if st := Check(dir, "pre-commit"); st.State != StateChained {
+		t.Errorf("Check = %s", st.State)
+	}
+
+	// Uninstall возвращает исходный hook на место
+	if st, err := Uninstall(dir, "pre-commit"); err != nil || st.State != StateRestored {
+		t.Fatalf("Uninstall = %+v, %v", st, err)
+	}
+	if got := readFile(t, path); got != foreign {
+		t.Errorf("restored hook = %q, want %q", got, foreign)
+	}
+	if _, err := os.Stat(path + ChainedSuffix); !os.IsNotExist(err) {
+		t.Errorf("%s left behind: %v", ChainedSuffix, err)
+	}
+	// Чужой hook Uninstall не удаляет
+	if st, err := Uninstall(dir, "pre-commit"); err != nil || st.State != StateForeign || readFile(t, path) != foreign {
+		t.Errorf("Uninstall of a foreign hook = %+v, %v", st, err)
+	}
+
+	// Без чужого hook: установка и удаление без следов
+	if st, err := Install(dir, "pre-push", "d-guard"); err != nil || st.State != StateInstalled {
+		t.Fatalf("Install pre-push = %+v, %v", st, err)
+	}
+	if st, err := Uninstall(dir, "pre-push"); err != nil || st.State != StateMissing {
+		t.Errorf("Uninstall pre-push = %+v, %v", st, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "pre-push")); !os.IsNotExist(err) {
+		t.Errorf("pre-push left behind: %v", err)
+	}
+	if st, err := Uninstall(dir, "pre-push"); err != nil || st.State != StateMissing {
+		t.Errorf("Uninstall of a missing hook = %+v, %v", st, err)
+	}
+}
+
+func TestInstallConflict(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "pre-commit"), "#!/bin/sh\n")
+	writeFile(t, filepath.Join(dir, "pre-commit"+ChainedSuffix), "#!/bin/sh\n")
+	if _, err := Install(dir, "pre-commit", "d-guard"); err == nil || !strings.Contains(err.Error(), "resolve manually") {
+		t.Errorf("Install over foreign hook and %s: %v", ChainedSuffix, err)
+	}
+	if _, err := Install(dir, "post-merge", "d-guard"); err == nil {
+		t.Error("unsupported hook type accepted")
+	}
+}
+
+// fakeDGuard — вместо d-guard пишет аргументы в $LOG
+func fakeDGuard(t *testing.T) (bin, log string) {
+	t.Helper()
+	dir := t.TempDir()
+	bin, log = filepath.Join(dir, "d-guard"), filepath.Join(dir, "calls.log")
+	writeFile(t, bin, "#!/bin/sh\necho \"d-guard $*\" >> \"$LOG\"\n")
+	t.Setenv("LOG", log)
+	return bin, log
+}
+
+func runHook(t *testing.T, root, path, stdin string) error {
+	t.Helper()
+	cmd := exec.Command(path, "origin", "https://example.com/repo.git")
+	cmd.Dir = root
+	cmd.Stdin = strings.NewReader(stdin)
+	return cmd.Run()
+}
+
+func TestPreCommitChains(t *testing.T) {
+	root, _ := gitRepo(t)
+	dir, _ := Dir(root)
+	bin, log := fakeDGuard(t)
+	writeFile(t, filepath.Join(dir, "pre-commit"), "#!/bin/sh\necho \"lint $*\" >> \"$LOG\"\nexit ${LINT_EXIT:-0}\n")
+	if _, err := Install(dir, "pre-commit", bin); err != nil {
+		t.Fatal(err)
+	}
+	hook := filepath.Join(dir, "pre-commit")
+
+	if err := runHook(t, root, hook, ""); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := readFile(t, log), "lint origin https://example.com/repo.git\nd-guard --staged --strict\n"; got != want {
+		t.Errorf("calls:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Упавший чужой hook останавливает коммит с его кодом, d-guard не запускается
+	os.Remove(log)
+	t.Setenv("LINT_EXIT", "3")
+	err := runHook(t, root, hook, "")
+	if exit, ok := err.(*exec.ExitError); !ok || exit.ExitCode() != 3 {
+		t.Errorf("hook error = %v, want exit status 3", err)
+	}
+	if got := readFile(t, log); strings.Contains(got, "d-guard") {
+		t.Errorf("d-guard ran after the chained hook failed:\n%s", got)
+	}
+}
+
+func TestPrePushRanges(t *testing.T) {
+	root, git := gitRepo(t)
+	dir, _ := Dir(root)
+	bin, log := fakeDGuard(t)
+	if _, err := Install(dir, "pre-push", bin); err != nil {
+		t.Fatal(err)
+	}
+	git("commit", "-q", "--allow-empty", "-m", "first")
+	first := git("rev-parse", "HEAD")
+	git("commit", "-q", "--allow-empty", "-m", "second")
+	head := git("rev-parse", "HEAD")
+	zero := strings.Repeat("0", len(head))
+	unknown := strings.Repeat("1", len(head)) // Чужой коммит, которого нет локально
+
+	tests := []struct {
+		name, remote, local, want string
+	}{
+		{"known remote sha", first, head, "d-guard --history-range " + first + ".." + head + " --strict\n"},
+		{"new branch", zero, head, "d-guard --history-range " + head + " --not --remotes --strict\n"},
+		{"remote sha missing locally", unknown, head, "d-guard --history-range " + head + " --not --remotes --strict\n"},
+		{"branch deletion", head, zero, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Remove(log)
+			stdin := "refs/heads/main " + tt.local + " refs/heads/main " + tt.remote + "\n"
+			if err := runHook(t, root, filepath.Join(dir, "pre-push"), stdin); err != nil {
+				t.Fatalf("hook: %v", err)
+			}
+			got, _ := os.ReadFile(log)
+			if string(got) != tt.want {
+				t.Errorf("calls = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+package license
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/devos-os/d-guard/internal/osv"
+)
+
+// Resolver ищет лицензии пакетов в vendor-каталогах проекта и локальных кэшах
+// менеджеров пакетов. Сеть не используется: чего нет на диске, остается без лицензии.
+type Resolver struct {
+	root         string
+	goModCache   string
+	cargoSources []string // $CARGO_HOME/registry/src/<index>
+}
+
+func NewResolver(root string) *Resolver {
+	r := &Resolver{root: root, goModCache: GoModCache()}
+	cargo := os.Getenv("CARGO_HOME")
+	if home, _ := os.UserHomeDir(); cargo == "" && home != "" {
+		cargo = filepath.Join(home, ".cargo")
+	}
+	if cargo != "" {
+		r.cargoSources, _ = filepath.Glob(filepath.Join(cargo, "registry", "src", "*"))
+	}
+	return r
+}
+
+// GoModCache — $GOMODCACHE, $GOPATH/pkg/mod или ~/go/pkg/mod (без вызова 'go env')
+func GoModCache() string {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return dir
+	}
+	if gopath := filepath.SplitList(os.Getenv("GOPATH")); len(gopath) > 0 && gopath[0] != "" {
+		return filepath.Join(gopath[0], "pkg", "mod")
+	}
+	if home, _ := os.UserHomeDir(); home != "" {
+		return filepath.Join(home, "go", "pkg", "mod")
+	}
+	return ""
+}
+
+// Resolve — лицензия пакета экосистемы OSV; dir — каталог lockfile (там vendor/, node_modules/, .venv)
+func (r *Resolver) Resolve(ecosystem, name, version, dir string) string {
+	switch ecosystem {
+	case osv.Go:
+		// go mod vendor: vendor/<module>/LICENSE
+		if l := licenseFile(filepath.Join(dir, "vendor", filepath.FromSlash(name))); l != "" {
+			return l
+		}
+		if r.goModCache != "" {
+			return licenseFile(filepath.Join(r.goModCache, EscapeModule(name)+"@"+EscapeModule(version)))
+		}
+	case osv.NPM:
+		pkg := filepath.Join(dir, "node_modules", filepath.FromSlash(name))
+		if l := npmPackage(pkg); l != "" {
+			return l
+		}
+		return licenseFile(pkg)
+	case osv.Crates:
+		// cargo vendor: vendor/<name> или vendor/<name>-<version> при нескольких версиях
+		dirs := []string{filepath.Join(dir, "vendor", name+"-"+version), filepath.Join(dir, "vendor", name)}
+		for _, src := range r.cargoSources {
+			dirs = append(dirs, filepath.Join(src, name+"-"+version))
+		}
+		for _, d := range dirs {
+			if l := cargoManifest(filepath.Join(d, "Cargo.toml")); l != "" {
+				return l
+			}
+			if l := licenseFile(d); l != "" {
+				return l
+			}
+		}
+	case osv.PyPI:
+		for _, base := range []string{dir, r.root} {
+			if l := pythonPackage(base, name, version); l != "" {
+				return l
+			}
+		}
+	}
+	return ""
+}
+
+// --- Go: файл лицензии модуля в vendor/ или GOMODCACHE ---
+
+// licenseFile узнает лицензию по файлам LICENSE*, LICENCE*, COPYING* каталога пакета
+func licenseFile(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	for _, e := range entries {
+		name := strings.ToUpper(e.Name())
+		if e.IsDir() || !(strings.HasPrefix(name, "LICENSE") || strings.HasPrefix(name, "LICENCE") || strings.HasPrefix(name, "COPYING")) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err == nil {
+			if id := classify(string(data)); id != "" {
+				return id
+			}
+		}
+	}
+	return ""
+}
+
+// EscapeModule — кодирование пути модуля в кэше: заглавные буквы как "!" + строчная
+func EscapeModule(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.IsUpper(r) {
+			b.WriteByte('!')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// licenseTexts — узнаваемые фразы распространенных лицензий (порядок важен: BSD-3 до BSD-2, ISC до 0BSD)
+var licenseTexts = []struct {
+	id      string
+	phrases []string
+}{
+	{"Apache-2.0", []string{"Apache License", "Version 2.0"}},
+	{"MPL-2.0", []string{"Mozilla Public License", "2.0"}},
+	{"AGPL-3.0-only", []string{"GNU AFFERO GENERAL PUBLIC LICENSE", "Version 3"}},
+	{"LGPL-3.0-only", []string{"GNU LESSER GENERAL PUBLIC LICENSE", "Version 3"}},
+	{"LGPL-2.1-only", []string{"GNU LESSER GENERAL PUBLIC LICENSE", "Version 2.1"}},
+	{"GPL-3.0-only", []string{"GNU GENERAL PUBLIC LICENSE", "Version 3"}},
+	{"GPL-2.0-only", []string{"GNU GENERAL PUBLIC LICENSE", "Version 2"}},
+	{"MIT", []string{"Permission is hereby granted, free of charge"}},
+	{"ISC", []string{"Permission to use, copy, modify, and/or distribute this software for any", "provided that the above copyright notice and this permission notice appear in all copies"}},
+	{"BSD-3-Clause", []string{"Redistribution and use in source and binary forms", "Neither the name"}},
+	{"BSD-2-Clause", []string{"Redistribution and use in source and binary forms"}},
+	{"Unlicense", []string{"This is free and unencumbered software released into the public domain"}},
+	{"0BSD", []string{"Permission to use, copy, modify, and/or distribute this software for any purpose with or without fee"}}, // ISC без условия об уведомлении
+}
+
+// classify определяет лицензию по тексту (пусто — не узнали)
+func classify(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	for _, l := range licenseTexts {
+		ok := true
+		for _, p := range l.phrases {
+			if !strings.Contains(text, p) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return l.id
+		}
+	}
+	return ""
+}
+
+// --- npm: node_modules/<name>/package.json ---
+
+// FromNPM — поле license из package.json/package-lock.json: строка или {"type": "..."}
+func FromNPM(v any) string {
+	switch l := v.(type) {
+	case string:
+		return l
+	case map[string]any:
+		if t, ok := l["type"].(string); ok {
+			return t
+		}
+	}
+	return ""
+}
+
+func npmPackage(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return ""
+	}
+	var pkg struct {
+		License any `json:"license"`
+	}
+	if json.Unmarshal(data, &pkg) != nil {
+		return ""
+	}
+	return FromNPM(pkg.License)
+}
+
+// --- Cargo: license в Cargo.toml распакованного crate ---
+
+var cargoLicense = regexp.MustCompile(`^license\s*=\s*"([^"]+)"`)
+
+func cargoManifest(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	section := ""
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		t := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(t, "[") {
+			section = t
+			continue
+		}
+		if m := cargoLicense.FindStringSubmatch(t); m != nil && section == "[package]" {
+			// Устаревшая запись "MIT/Apache-2.0" означает выбор одной из лицензий
+			return strings.ReplaceAll(m[1], "/", " OR ")
+		}
+	}
+	return ""
+}
+
+// --- PyPI: METADATA установленного пакета в .venv/venv ---
+
+// classifiers — однозначные классификаторы Trove (License :: OSI Approved :: ...)
+var classifiers = map[string]string{
+	"MIT License":                                   "MIT",
+	"Apache Software License":                       "Apache-2.0",
+	"Mozilla Public License 2.0 (MPL 2.0)":          "MPL-2.0",
+	"ISC License (ISCL)":                            "ISC",
+	"The Unlicense (Unlicense)":                     "Unlicense",
+	"Python Software Foundation License":            "PSF-2.0",
+	"GNU General Public License v2 (GPLv2)":         "GPL-2.0-only",
+	"GNU General Public License v3 (GPLv3)":         "GPL-3.0-only",
+	"GNU Lesser General Public License v3 (LGPLv3)": "LGPL-3.0-only",
+	"GNU Lesser General Public License v2 (LGPLv2)": "LGPL-2.0-only",
+	"GNU Affero General Public License v3":          "AGPL-3.0-only",
+}
+
+func pythonPackage(base, name, version string) string {
+	want := osv.NormalizeName(osv.PyPI, name)
+	for _, venv := range []string{".venv", "venv"} {
+		sites, _ := filepath.Glob(filepath.Join(base, venv, "lib", "python*", "site-packages"))
+		for _, site := range sites {
+			entries, err := os.ReadDir(site)
+			if err != nil {
+				continue
+			}
+			for _, e := range entries {
+				// Каталог dist-info называется по имени дистрибутива (Foo_Bar-1.0.dist-info)
+				dist, ver, ok := cutLast(strings.TrimSuffix(e.Name(), ".dist-info"), "-")
+				if e.IsDir() && ok && ver == version && osv.NormalizeName(osv.PyPI, dist) == want {
+					return pythonMetadata(filepath.Join(site, e.Name(), "METADATA"))
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// pythonMetadata: License-Expression (PEP 639), затем классификатор, затем короткое поле License
+func pythonMetadata(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	var license, classifier string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		l := sc.Text()
+		if l == "" {
+			break // Дальше описание пакета
+		}
+		k, v, ok := strings.Cut(l, ": ")
+		if !ok {
+			continue
+		}
+		switch k {
+		case "License-Expression":
+			return strings.TrimSpace(v)
+		case "License":
+			license = strings.TrimSpace(v)
+		case "Classifier":
+			if id, ok := classifiers[strings.TrimPrefix(v, "License :: OSI Approved :: ")]; ok && classifier == "" {
+				classifier = id
+			}
+		}
+	}
+	if classifier != "" {
+		return classifier
+	}
+	if len(license) > 64 || strings.EqualFold(license, "UNKNOWN") {
+		return "" // Полный текст лицензии вместо идентификатора
+	}
+	return license
+}
+
+func cutLast(s, sep string) (string, string, bool) {
+	i := strings.LastIndex(s, sep)
+	if i < 0 {
+		return s, "", false
+	}
+	return s[:i], s[i+len(sep):], true
+}
@@ -0,0 +1,176 @@
+package license
+
+import "strings"
+
+// knownIDs — идентификаторы SPDX, которые встречаются в метаданных пакетов. Форматы SBOM
+// требуют валидные ID: остальное уходит в CycloneDX license.name и SPDX NOASSERTION.
+var knownIDs = map[string]bool{
+	"MIT": true, "MIT-0": true, "Apache-2.0": true, "BSD-2-Clause": true, "BSD-3-Clause": true, "0BSD": true,
+	"ISC": true, "MPL-2.0": true, "Unlicense": true, "CC0-1.0": true, "CC-BY-4.0": true, "CC-BY-SA-4.0": true,
+	"Zlib": true, "BSL-1.0": true, "PSF-2.0": true, "Python-2.0": true, "BlueOak-1.0.0": true,
+	"Unicode-DFS-2016": true, "Unicode-3.0": true, "WTFPL": true, "Artistic-2.0": true, "EPL-1.0": true, "EPL-2.0": true,
+	"CDDL-1.0": true, "CDDL-1.1": true, "EUPL-1.2": true, "OSL-3.0": true, "SSPL-1.0": true,
+	"GPL-2.0": true, "GPL-2.0-only": true, "GPL-2.0-or-later": true, "GPL-3.0": true, "GPL-3.0-only": true, "GPL-3.0-or-later": true,
+	"LGPL-2.0-only": true, "LGPL-2.1": true, "LGPL-2.1-only": true, "LGPL-2.1-or-later": true,
+	"LGPL-3.0": true, "LGPL-3.0-only": true, "LGPL-3.0-or-later": true,
+	"AGPL-3.0": true, "AGPL-3.0-only": true, "AGPL-3.0-or-later": true,
+	"LLVM-exception": true, "Classpath-exception-2.0": true,
+}
+
+// Valid проверяет, что лицензия — SPDX-выражение из известных ID ("MIT OR Apache-2.0").
+// single — выражение из одного ID.
+func Valid(license string) (ok, single bool) {
+	tokens := tokenize(license)
+	if len(tokens) == 0 {
+		return false, false
+	}
+	n := 0
+	for _, t := range tokens {
+		if t == "(" || t == ")" {
+			continue
+		}
+		if n%2 == 1 {
+			if t != "AND" && t != "OR" && t != "WITH" {
+				return false, false
+			}
+		} else if !knownIDs[strings.TrimSuffix(t, "+")] {
+			return false, false
+		}
+		n++
+	}
+	return n%2 == 1, n == 1
+}
+
+func tokenize(s string) []string {
+	return strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(s))
+}
+
+// Expr — разобранное SPDX-выражение: лист (ID, возможно "ID WITH исключение") или AND/OR
+type Expr struct {
+	Op   string // "AND", "OR"; пусто — лист
+	ID   string
+	Args []*Expr
+}
+
+// Parse разбирает выражение (AND связывает сильнее OR). Текст, не являющийся
+// SPDX-выражением ("SEE LICENSE IN LICENSE"), становится одним листом.
+func Parse(license string) *Expr {
+	license = strings.TrimSpace(license)
+	if ok, _ := Valid(license); !ok {
+		return &Expr{ID: license}
+	}
+	p := &exprParser{tokens: tokenize(license)}
+	return p.or()
+}
+
+type exprParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *exprParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *exprParser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *exprParser) or() *Expr  { return p.binary("OR", p.and) }
+func (p *exprParser) and() *Expr { return p.binary("AND", p.leaf) }
+
+func (p *exprParser) binary(op string, operand func() *Expr) *Expr {
+	e := operand()
+	for p.peek() == op {
+		p.next()
+		if e.Op != op {
+			e = &Expr{Op: op, Args: []*Expr{e}}
+		}
+		e.Args = append(e.Args, operand())
+	}
+	return e
+}
+
+func (p *exprParser) leaf() *Expr {
+	if p.peek() == "(" {
+		p.next()
+		e := p.or()
+		p.next() // ")"
+		return e
+	}
+	id := p.next()
+	if p.peek() == "WITH" {
+		p.next()
+		id += " WITH " + p.next()
+	}
+	return &Expr{ID: id}
+}
+
+// Satisfied — выражение выполнено, если ok для листьев: OR — хотя бы один вариант, AND — все
+func (e *Expr) Satisfied(ok func(id string) bool) bool {
+	switch e.Op {
+	case "OR":
+		for _, a := range e.Args {
+			if a.Satisfied(ok) {
+				return true
+			}
+		}
+		return false
+	case "AND":
+		for _, a := range e.Args {
+			if !a.Satisfied(ok) {
+				return false
+			}
+		}
+		return true
+	}
+	return ok(e.ID)
+}
+
+// Normalize приводит ID к виду для сравнения: регистр, устаревшие "GPL-2.0"/"GPL-2.0+"
+// равны "GPL-2.0-only"/"GPL-2.0-or-later"
+func Normalize(id string) string {
+	id = strings.ToLower(strings.TrimSpace(id))
+	if strings.HasSuffix(id, "+") {
+		return strings.TrimSuffix(id, "+") + "-or-later"
+	}
+	return strings.TrimSuffix(id, "-only")
+}
+
+// Копилефт: strong требует открывать производную работу целиком,
+// weak — только изменения самой библиотеки
+const (
+	CopyleftStrong = "strong"
+	CopyleftWeak   = "weak"
+)
+
+var (
+	strongCopyleft = []string{"gpl-", "agpl-", "sspl-", "osl-", "eupl-", "cc-by-sa-"}
+	weakCopyleft   = []string{"lgpl-", "mpl-", "epl-", "cddl-", "cpl-", "ms-rl"}
+)
+
+// Copyleft — вид копилефта лицензии-листа (пусто — разрешительная или неизвестная).
+// GPL с исключением (Classpath, LLVM) считается слабым копилефтом.
+func Copyleft(id string) string {
+	base, exception, _ := strings.Cut(id, " WITH ")
+	base = Normalize(base)
+	for _, p := range weakCopyleft {
+		if strings.HasPrefix(base, p) {
+			return CopyleftWeak
+		}
+	}
+	for _, p := range strongCopyleft {
+		if strings.HasPrefix(base, p) {
+			if exception != "" {
+				return CopyleftWeak
+			}
+			return CopyleftStrong
+		}
+	}
+	return ""
+}
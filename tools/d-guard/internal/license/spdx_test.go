@@ -0,0 +1,136 @@
+package license
+
+import (
+	"strings"
+	"testing"
+)
+
+// render — выражение со скобками вокруг каждого AND/OR, чтобы проверить приоритет
+func render(e *Expr) string {
+	if e.Op == "" {
+		return e.ID
+	}
+	var args []string
+	for _, a := range e.Args {
+		args = append(args, render(a))
+	}
+	return "(" + strings.Join(args, " "+e.Op+" ") + ")"
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		license    string
+		ok, single bool
+	}{
+		{"MIT", true, true},
+		{"GPL-2.0+", true, true},
+		{"MIT OR Apache-2.0", true, false},
+		{"(MIT OR Apache-2.0) AND BSD-3-Clause", true, false},
+		{"GPL-2.0-only WITH Classpath-exception-2.0", true, false},
+		{"Apache-2.0 WITH LLVM-exception OR MIT", true, false},
+		{"", false, false},
+		{"MIT OR", false, false},
+		{"MIT Apache-2.0", false, false},
+		{"OR MIT", false, false},
+		{"Proprietary", false, false},
+		{"SEE LICENSE IN LICENSE", false, false},
+		{"mit", false, false}, // ID в SBOM — с точным регистром
+	}
+	for _, tt := range tests {
+		if ok, single := Valid(tt.license); ok != tt.ok || single != tt.single {
+			t.Errorf("Valid(%q) = %v, %v; want %v, %v", tt.license, ok, single, tt.ok, tt.single)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := map[string]string{
+		"MIT":                         "MIT",
+		"MIT OR Apache-2.0":           "(MIT OR Apache-2.0)",
+		"MIT OR Apache-2.0 OR ISC":    "(MIT OR Apache-2.0 OR ISC)",
+		"MIT AND ISC OR Apache-2.0":   "((MIT AND ISC) OR Apache-2.0)", // AND связывает сильнее
+		"MIT OR ISC AND Apache-2.0":   "(MIT OR (ISC AND Apache-2.0))",
+		"(MIT OR ISC) AND Apache-2.0": "((MIT OR ISC) AND Apache-2.0)",
+		"GPL-2.0-only WITH Classpath-exception-2.0 OR MIT": "(GPL-2.0-only WITH Classpath-exception-2.0 OR MIT)",
+		" SEE LICENSE IN LICENSE ":                         "SEE LICENSE IN LICENSE", // Не выражение — один лист
+	}
+	for in, want := range tests {
+		if got := render(Parse(in)); got != want {
+			t.Errorf("Parse(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestSatisfied(t *testing.T) {
+	permissive := func(id string) bool { return Copyleft(id) == "" }
+	tests := []struct {
+		license string
+		want    bool
+	}{
+		{"MIT", true},
+		{"GPL-3.0-only", false},
+		{"MIT OR GPL-3.0-only", true},           // Можно выбрать MIT
+		{"MIT AND GPL-3.0-only", false},         // Нужно соблюдать обе
+		{"(MIT OR GPL-3.0-only) AND ISC", true}, // MIT и ISC
+		{"(LGPL-2.1-only OR GPL-3.0-only) AND MIT", false},
+		{"Apache-2.0 WITH LLVM-exception", true},
+	}
+	for _, tt := range tests {
+		if got := Parse(tt.license).Satisfied(permissive); got != tt.want {
+			t.Errorf("%q satisfied = %v, want %v", tt.license, got, tt.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	for in, want := range map[string]string{
+		"MIT":              "mit",
+		"GPL-2.0":          "gpl-2.0",
+		"GPL-2.0-only":     "gpl-2.0",
+		"GPL-2.0+":         "gpl-2.0-or-later",
+		"GPL-2.0-or-later": "gpl-2.0-or-later",
+		" apache-2.0 ":     "apache-2.0",
+	} {
+		if got := Normalize(in); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCopyleft(t *testing.T) {
+	for id, want := range map[string]string{
+		"MIT":               "",
+		"Apache-2.0":        "",
+		"GPL-3.0-only":      CopyleftStrong,
+		"GPL-2.0+":          CopyleftStrong,
+		"AGPL-3.0-or-later": CopyleftStrong,
+		"agpl-3.0":          CopyleftStrong,
+		"SSPL-1.0":          CopyleftStrong,
+		"CC-BY-SA-4.0":      CopyleftStrong,
+		"CC-BY-4.0":         "",
+		"LGPL-2.1-only":     CopyleftWeak,
+		"MPL-2.0":           CopyleftWeak,
+		"EPL-2.0":           CopyleftWeak,
+		"GPL-2.0-only WITH Classpath-exception-2.0": CopyleftWeak, // Исключение ослабляет GPL
+	} {
+		if got := Copyleft(id); got != want {
+			t.Errorf("Copyleft(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	for want, text := range map[string]string{
+		"MIT":           "Copyright (c) 2024\n\nPermission is hereby granted, free of charge, to any person obtaining a copy",
+		"Apache-2.0":    "                                 Apache License\n                           Version 2.0, January 2004",
+		"BSD-3-Clause":  "Redistribution and use in source and binary forms, with or without\nmodification... 3. Neither the name of the copyright holder",
+		"BSD-2-Clause":  "Redistribution and use in source and binary forms, with or without modification, are permitted",
+		"LGPL-2.1-only": "GNU LESSER GENERAL PUBLIC LICENSE\n Version 2.1, February 1999",
+		"GPL-3.0-only":  "GNU GENERAL PUBLIC LICENSE\n Version 3, 29 June 2007",
+		"":              "All rights reserved.",
+	} {
+		if got := classify(text); got != want {
+			t.Errorf("classify(%.40q) = %q, want %q", text, got, want)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package code
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScanFile(t *testing.T) {
+	long := "var bundle = \"" + strings.Repeat("a", 5<<20) + "\"\n"
+	tests := []struct {
+		name, path, data string
+		lines            []int
+	}{
+		{"address", "app.go", "package app\n\nconst api = \"http://localhost:8080\"\n", []int{3}},
+		{"comments", "app.py", "# localhost\n// 127.0.0.1\n * 0.0.0.0\nhost = '0.0.0.0'\n", []int{4}},
+		{"go.sum", "go.sum", "localhost v1.0.0 h1:abc=\n", nil},
+		// Строка длиннее буфера не обрывает проверку остатка файла
+		{"long line", "bundle.js", "fetch('http://127.0.0.1')\n" + long + "fetch('http://localhost')\n", []int{1, 3}},
+		{"long last line", "bundle.js", "fetch('http://localhost')\n" + strings.TrimSuffix(long, "\n"), []int{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var lines []int
+			for _, is := range ScanFile(tt.path, []byte(tt.data)) {
+				lines = append(lines, is.Line)
+			}
+			if !reflect.DeepEqual(lines, tt.lines) {
+				t.Errorf("lines = %v, want %v", lines, tt.lines)
+			}
+		})
+	}
+}
@@ -0,0 +1,320 @@
+package container
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Instruction — одна инструкция Dockerfile после склейки продолжений строк
+type Instruction struct {
+	Cmd      string    // В верхнем регистре: FROM, RUN, COPY...
+	Flags    []string  // --from=build, --chown=app и т.д.
+	Args     string    // Аргументы без флагов (shell-форма или исходный JSON)
+	Exec     []string  // Exec-форма ["a", "b"], если использована
+	Heredocs []Heredoc // RUN/COPY <<EOF ... EOF
+	Line     int       // Первая строка инструкции
+	EndLine  int
+}
+
+// Heredoc — встроенный документ инструкции
+type Heredoc struct {
+	Name string
+	Body string
+}
+
+// Stage — стадия multi-stage сборки (от FROM до следующего FROM)
+type Stage struct {
+	Index        int
+	Name         string // AS <name>
+	Image        string // Образ после подстановки ARG
+	RawImage     string // Как написано в FROM
+	From         Instruction
+	Instructions []Instruction // Без самого FROM
+}
+
+// Dockerfile — разобранный файл
+type Dockerfile struct {
+	Args   map[string]string // Глобальные ARG (до первого FROM)
+	Stages []*Stage
+}
+
+// Final — последняя стадия (из нее собирается итоговый образ)
+func (d *Dockerfile) Final() *Stage {
+	if len(d.Stages) == 0 {
+		return nil
+	}
+	return d.Stages[len(d.Stages)-1]
+}
+
+// StageNamed ищет стадию по имени или номеру (для FROM <stage> и COPY --from)
+func (d *Dockerfile) StageNamed(name string) *Stage {
+	for _, s := range d.Stages {
+		if strings.EqualFold(s.Name, name) || strconv.Itoa(s.Index) == name {
+			return s
+		}
+	}
+	return nil
+}
+
+var dockerfileNames = regexp.MustCompile(`(?i)^(?:(?:docker|container)file(?:\..+)?|.+\.(?:docker|container)file)$`)
+
+// IsDockerfile: Dockerfile, Dockerfile.prod, api.Dockerfile, Containerfile
+func IsDockerfile(path string) bool {
+	return dockerfileNames.MatchString(filepath.Base(path))
+}
+
+var (
+	escapeDirective = regexp.MustCompile(`(?i)^#\s*escape\s*=\s*(\S)`)
+	heredocMarker   = regexp.MustCompile(`<<(-?)(["']?)([A-Za-z_][A-Za-z0-9_]*)(["']?)`)
+)
+
+// ParseDockerfile разбирает Dockerfile: продолжения строк (с учетом # escape=),
+// комментарии, heredoc, exec-форму и подстановку ARG в FROM.
+func ParseDockerfile(data []byte) *Dockerfile {
+	var lines []string
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), 4<<20)
+	for sc.Scan() {
+		lines = append(lines, strings.TrimSuffix(sc.Text(), "\r"))
+	}
+
+	escape := `\`
+	// Директивы парсера допустимы только в самом начале файла
+	for _, l := range lines {
+		t := strings.TrimSpace(l)
+		if !strings.HasPrefix(t, "#") {
+			break
+		}
+		if m := escapeDirective.FindStringSubmatch(t); m != nil && (m[1] == "`" || m[1] == `\`) {
+			escape = m[1]
+		}
+	}
+
+	var instructions []Instruction
+	for i := 0; i < len(lines); i++ {
+		t := strings.TrimSpace(lines[i])
+		if t == "" || strings.HasPrefix(t, "#") {
+			continue
+		}
+
+		start := i
+		var sb strings.Builder
+		for {
+			cur := strings.TrimRight(lines[i], " \t")
+			if strings.HasSuffix(cur, escape) && i+1 < len(lines) {
+				sb.WriteString(strings.TrimSuffix(cur, escape))
+				sb.WriteString(" ")
+				i++
+				// Комментарии и пустые строки внутри продолжения пропускаются
+				for i < len(lines) && (strings.HasPrefix(strings.TrimSpace(lines[i]), "#") || strings.TrimSpace(lines[i]) == "") && i+1 < len(lines) {
+					i++
+				}
+				continue
+			}
+			sb.WriteString(cur)
+			break
+		}
+
+		ins := parseInstruction(strings.TrimSpace(sb.String()))
+		ins.Line = start + 1
+
+		// Heredoc-тела идут сразу после строки инструкции
+		for _, m := range heredocMarker.FindAllStringSubmatch(ins.Args, -1) {
+			strip, name := m[1] == "-", m[3]
+			var body []string
+			for i+1 < len(lines) {
+				i++
+				l := lines[i]
+				if strip {
+					l = strings.TrimLeft(l, "\t")
+				}
+				if l == name {
+					break
+				}
+				body = append(body, l)
+			}
+			ins.Heredocs = append(ins.Heredocs, Heredoc{Name: name, Body: strings.Join(body, "\n")})
+		}
+		ins.EndLine = i + 1
+		instructions = append(instructions, ins)
+	}
+
+	return buildStages(instructions)
+}
+
+func parseInstruction(s string) Instruction {
+	cmd, rest, _ := strings.Cut(s, " ")
+	ins := Instruction{Cmd: strings.ToUpper(cmd)}
+	rest = strings.TrimSpace(rest)
+
+	// Флаги (--from=, --chown=, --mount=) идут перед аргументами
+	for strings.HasPrefix(rest, "--") {
+		flag, tail, _ := strings.Cut(rest, " ")
+		ins.Flags = append(ins.Flags, flag)
+		rest = strings.TrimSpace(tail)
+	}
+	ins.Args = rest
+
+	if strings.HasPrefix(rest, "[") {
+		var exec []string
+		if json.Unmarshal([]byte(rest), &exec) == nil {
+			ins.Exec = exec
+		}
+	}
+	return ins
+}
+
+// Flag возвращает значение флага --name=value
+func (i Instruction) Flag(name string) (string, bool) {
+	for _, f := range i.Flags {
+		if k, v, ok := strings.Cut(strings.TrimPrefix(f, "--"), "="); ok && strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// Command — команда для анализа: shell-форма, exec-форма одной строкой и тела heredoc
+func (i Instruction) Command() string {
+	parts := []string{i.Args}
+	if i.Exec != nil {
+		parts[0] = strings.Join(i.Exec, " ")
+	}
+	for _, h := range i.Heredocs {
+		parts = append(parts, h.Body)
+	}
+	return strings.Join(parts, "\n")
+}
+
+func buildStages(instructions []Instruction) *Dockerfile {
+	d := &Dockerfile{Args: map[string]string{}}
+	var cur *Stage
+	for _, ins := range instructions {
+		switch {
+		case ins.Cmd == "FROM":
+			fields := strings.Fields(ins.Args)
+			cur = &Stage{Index: len(d.Stages), From: ins}
+			if len(fields) > 0 {
+				cur.RawImage = fields[0]
+				cur.Image = expandArgs(fields[0], d.Args)
+			}
+			if len(fields) >= 3 && strings.EqualFold(fields[1], "AS") {
+				cur.Name = fields[2]
+			}
+			d.Stages = append(d.Stages, cur)
+		case cur == nil:
+			// До первого FROM допустимы только ARG
+			if ins.Cmd == "ARG" {
+				for _, kv := range parseArgs(ins.Args) {
+					d.Args[kv.Key] = kv.Value
+				}
+			}
+		default:
+			cur.Instructions = append(cur.Instructions, ins)
+		}
+	}
+	return d
+}
+
+// KeyValue — пара из ARG/ENV
+type KeyValue struct {
+	Key, Value string
+}
+
+// parseArgs разбирает "ARG A=1 B" и "ENV A=1 B=2" / "ENV A 1" в пары имя-значение (в порядке записи)
+func parseArgs(s string) []KeyValue {
+	fields := splitWords(s)
+	if len(fields) >= 2 && !strings.Contains(fields[0], "=") {
+		// Устаревшая форма "ENV KEY value with spaces"
+		return []KeyValue{{fields[0], unquote(strings.Join(fields[1:], " "))}}
+	}
+	var out []KeyValue
+	for _, f := range fields {
+		k, v, _ := strings.Cut(f, "=")
+		out = append(out, KeyValue{k, unquote(v)})
+	}
+	return out
+}
+
+// splitWords делит по пробелам с учетом кавычек
+func splitWords(s string) []string {
+	var words []string
+	var sb strings.Builder
+	var quote rune
+	for _, c := range s {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+			sb.WriteRune(c)
+		case c == '"' || c == '\'':
+			quote = c
+			sb.WriteRune(c)
+		case c == ' ' || c == '\t':
+			if sb.Len() > 0 {
+				words = append(words, sb.String())
+				sb.Reset()
+			}
+		default:
+			sb.WriteRune(c)
+		}
+	}
+	if sb.Len() > 0 {
+		words = append(words, sb.String())
+	}
+	return words
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+var argRef = regexp.MustCompile(`\$(?:\{([A-Za-z_][A-Za-z0-9_]*)(?::?([-+])([^}]*))?\}|([A-Za-z_][A-Za-z0-9_]*))`)
+
+// expandArgs подставляет $VAR, ${VAR}, ${VAR:-default}, ${VAR:+alt}
+func expandArgs(s string, vars map[string]string) string {
+	return argRef.ReplaceAllStringFunc(s, func(ref string) string {
+		m := argRef.FindStringSubmatch(ref)
+		name := m[1]
+		if name == "" {
+			name = m[4]
+		}
+		val, ok := vars[name]
+		switch m[2] {
+		case "-":
+			if !ok || val == "" {
+				return m[3]
+			}
+		case "+":
+			if ok && val != "" {
+				return m[3]
+			}
+			return ""
+		}
+		return val
+	})
+}
+
+// unresolvedArgs: строка ссылается на ARG без значения и без ${VAR:-default}
+// (тег или имя образа известны только при сборке с --build-arg)
+func unresolvedArgs(s string, vars map[string]string) bool {
+	for _, m := range argRef.FindAllStringSubmatch(s, -1) {
+		name := m[1]
+		if name == "" {
+			name = m[4]
+		}
+		if vars[name] == "" && m[2] == "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,237 @@
+package container
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/devos-os/d-guard/internal/core"
+)
+
+func TestParseDockerfile(t *testing.T) {
+	t.Run("continuations and comments", func(t *testing.T) {
+		d := ParseDockerfile([]byte("FROM alpine:3.19\r\n" +
+			"RUN apk add \\\n" +
+			"    # комментарий внутри продолжения\n" +
+			"\n" +
+			"    curl \\\n" +
+			"    git\n" +
+			"# comment\n" +
+			"USER app\n"))
+		ins := d.Final().Instructions
+		if len(ins) != 2 {
+			t.Fatalf("instructions = %+v", ins)
+		}
+		if ins[0].Cmd != "RUN" || strings.Join(strings.Fields(ins[0].Args), " ") != "apk add curl git" || ins[0].Line != 2 || ins[0].EndLine != 6 {
+			t.Errorf("RUN = %+v", ins[0])
+		}
+		if ins[1].Cmd != "USER" || ins[1].Args != "app" || ins[1].Line != 8 {
+			t.Errorf("USER = %+v", ins[1])
+		}
+	})
+
+	t.Run("escape directive", func(t *testing.T) {
+		d := ParseDockerfile([]byte("# escape=`\nFROM mcr.microsoft.com/windows/servercore:ltsc2022\nRUN dir C:\\ `\n    && echo done\n"))
+		ins := d.Final().Instructions
+		if len(ins) != 1 || !strings.Contains(ins[0].Args, `dir C:\`) || !strings.Contains(ins[0].Args, "echo done") {
+			t.Errorf("instructions = %+v", ins)
+		}
+	})
+
+	t.Run("heredocs", func(t *testing.T) {
+		d := ParseDockerfile([]byte("FROM debian:12\n" +
+			"RUN <<EOF\n" +
+			"apt-get update\n" +
+			"apt-get install -y curl\n" +
+			"EOF\n" +
+			"COPY <<-\"CONF\" /etc/app.conf\n" +
+			"\tlisten 8080\n" +
+			"\tCONF\n" +
+			"USER app\n"))
+		ins := d.Final().Instructions
+		if len(ins) != 3 {
+			t.Fatalf("instructions = %+v", ins)
+		}
+		want := []Heredoc{{Name: "EOF", Body: "apt-get update\napt-get install -y curl"}}
+		if !reflect.DeepEqual(ins[0].Heredocs, want) || ins[0].Line != 2 || ins[0].EndLine != 5 {
+			t.Errorf("RUN heredoc = %+v", ins[0])
+		}
+		if !strings.Contains(ins[0].Command(), "apt-get install -y curl") {
+			t.Errorf("Command() = %q", ins[0].Command())
+		}
+		want = []Heredoc{{Name: "CONF", Body: "listen 8080"}}
+		if !reflect.DeepEqual(ins[1].Heredocs, want) || ins[1].EndLine != 8 {
+			t.Errorf("COPY heredoc = %+v", ins[1])
+		}
+		if ins[2].Cmd != "USER" || ins[2].Line != 9 {
+			t.Errorf("after heredoc = %+v", ins[2])
+		}
+	})
+
+	t.Run("exec form and flags", func(t *testing.T) {
+		d := ParseDockerfile([]byte("FROM alpine:3.19\ncopy --from=build --chown=app:app /out/app /app\nCMD [\"/app\", \"--port\", \"8080\"]\n"))
+		ins := d.Final().Instructions
+		if from, ok := ins[0].Flag("from"); !ok || from != "build" || ins[0].Cmd != "COPY" || ins[0].Args != "/out/app /app" {
+			t.Errorf("COPY = %+v", ins[0])
+		}
+		if !reflect.DeepEqual(ins[1].Exec, []string{"/app", "--port", "8080"}) || ins[1].Command() != "/app --port 8080" {
+			t.Errorf("CMD = %+v", ins[1])
+		}
+	})
+
+	t.Run("arg substitution", func(t *testing.T) {
+		d := ParseDockerfile([]byte("ARG REGISTRY=registry.example.com\n" +
+			"ARG VERSION=\"1.22\"\n" +
+			"ARG VARIANT\n" +
+			"FROM ${REGISTRY}/golang:$VERSION-${VARIANT:-alpine} AS build\n" +
+			"ARG VERSION=9.9\n" +
+			"FROM ${REGISTRY:+mirror.example.com/}distroless:${VERSION}\n"))
+		if d.Args["VERSION"] != "1.22" || d.Args["REGISTRY"] != "registry.example.com" {
+			t.Errorf("global args = %v", d.Args)
+		}
+		if s := d.Stages[0]; s.Image != "registry.example.com/golang:1.22-alpine" || s.RawImage != "${REGISTRY}/golang:$VERSION-${VARIANT:-alpine}" {
+			t.Errorf("stage 0 image = %q (raw %q)", s.Image, s.RawImage)
+		}
+		// ARG внутри стадии не влияет на FROM
+		if s := d.Stages[1]; s.Image != "mirror.example.com/distroless:1.22" {
+			t.Errorf("stage 1 image = %q", s.Image)
+		}
+	})
+
+	t.Run("multi-stage", func(t *testing.T) {
+		d := ParseDockerfile([]byte("FROM --platform=$BUILDPLATFORM golang:1.22 AS Build\n" +
+			"RUN go build -o /out/app\n" +
+			"FROM build AS test\n" +
+			"RUN go test ./...\n" +
+			"FROM gcr.io/distroless/static:nonroot\n" +
+			"COPY --from=0 /out/app /app\n"))
+		if len(d.Stages) != 3 {
+			t.Fatalf("stages = %d", len(d.Stages))
+		}
+		if s := d.Stages[0]; s.Name != "Build" || s.Image != "golang:1.22" || len(s.Instructions) != 1 {
+			t.Errorf("stage 0 = %+v", s)
+		}
+		if d.StageNamed("build") != d.Stages[0] || d.StageNamed("0") != d.Stages[0] || d.StageNamed("test") != d.Stages[1] {
+			t.Error("StageNamed: lookup by name (case-insensitive) or index")
+		}
+		if d.Final() != d.Stages[2] || d.Final().Name != "" {
+			t.Errorf("final = %+v", d.Final())
+		}
+	})
+
+	t.Run("env forms", func(t *testing.T) {
+		got := parseArgs(`A=1 B="two words" C`)
+		want := []KeyValue{{"A", "1"}, {"B", "two words"}, {"C", ""}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("parseArgs = %v, want %v", got, want)
+		}
+		if got := parseArgs("PATH /usr/local/bin:/usr/bin"); !reflect.DeepEqual(got, []KeyValue{{"PATH", "/usr/local/bin:/usr/bin"}}) {
+			t.Errorf("legacy ENV = %v", got)
+		}
+	})
+}
+
+func TestIsDockerfile(t *testing.T) {
+	for path, want := range map[string]bool{
+		"Dockerfile": true, "build/Dockerfile.prod": true, "api.Dockerfile": true, "dockerfile": true,
+		"Containerfile": true, "web.containerfile": true,
+		"docker-compose.yml": false, "MyDockerfile": false, "dockerfile_test.go": false,
+	} {
+		if got := IsDockerfile(path); got != want {
+			t.Errorf("IsDockerfile(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+const pinned = "alpine:3.19@sha256:51b67269f354137895d43f3b3d810bfacd3945438e94dc5ac55fdac340352f48"
+
+// tail — окончание чистого Dockerfile: непривилегированный пользователь и HEALTHCHECK
+const tail = "USER app\nHEALTHCHECK CMD wget -qO- http://localhost:8080/health || exit 1\n"
+
+type ruleAt struct {
+	Rule     string
+	Line     int
+	Severity core.Severity
+}
+
+func scan(src string) []ruleAt {
+	out := []ruleAt{}
+	for _, is := range ScanDockerfile("Dockerfile", []byte(src)) {
+		out = append(out, ruleAt{is.RuleID, is.Line, is.Severity})
+	}
+	return out
+}
+
+func TestScanDockerfile(t *testing.T) {
+	base := "FROM " + pinned + "\n"
+	tests := []struct {
+		name string
+		src  string
+		want []ruleAt
+	}{
+		{"clean", base + tail, nil},
+
+		{"no tag", "FROM ubuntu\n" + tail, []ruleAt{{"dockerfile-latest-tag", 1, core.SevMedium}}},
+		{"latest tag", "FROM ubuntu:latest\n" + tail, []ruleAt{{"dockerfile-latest-tag", 1, core.SevMedium}}},
+		{"tag without digest", "FROM ubuntu:22.04\n" + tail, []ruleAt{{"dockerfile-unpinned-digest", 1, core.SevLow}}},
+		{"registry with port", "FROM registry:5000/app\n" + tail, []ruleAt{{"dockerfile-latest-tag", 1, core.SevMedium}}},
+		{"tag from arg", "ARG BASE=node:20\nFROM $BASE\n" + tail, []ruleAt{{"dockerfile-unpinned-digest", 2, core.SevLow}}},
+		{"unresolved arg", "FROM node:${NODE_VERSION}\n" + tail, nil},
+		{"arg declared without value", "ARG TAG\nFROM node:$TAG\n" + tail, nil},
+		{"arg default in reference", "FROM node:${NODE_VERSION:-20}\n" + tail, []ruleAt{{"dockerfile-unpinned-digest", 1, core.SevLow}}},
+		{"scratch", "FROM scratch\n" + tail, nil},
+
+		{"add local file", base + "ADD app.conf /etc/\n" + tail, []ruleAt{{"dockerfile-add-instead-of-copy", 2, core.SevLow}}},
+		{"add url", base + "ADD https://example.com/tool /usr/bin/tool\n" + tail, []ruleAt{{"dockerfile-add-instead-of-copy", 2, core.SevMedium}}},
+		{"add archive", base + "ADD rootfs.tar.gz /\n" + tail, nil},
+
+		{"env secret", base + "ENV APP_PORT=8080 DB_PASSWORD=hunter2\n" + tail, []ruleAt{{"dockerfile-env-secret", 2, core.SevHigh}}},
+		{"arg secret with default", base + "ARG NPM_TOKEN=abc\n" + tail, []ruleAt{{"dockerfile-arg-secret", 2, core.SevHigh}}},
+		{"arg secret without default", base + "ARG GITHUB_TOKEN\n" + tail, []ruleAt{{"dockerfile-arg-secret", 2, core.SevMedium}}},
+		{"arg not a secret", base + "ARG VERSION=1\nARG BUILD_DATE\n" + tail, nil},
+
+		{"copy before install", base + "WORKDIR /app\nCOPY . .\nRUN npm ci\n" + tail, []ruleAt{{"dockerfile-copy-before-install", 3, core.SevLow}}},
+		{"copy manifests first", base + "COPY package.json package-lock.json ./\nRUN npm ci\nCOPY . .\n" + tail, nil},
+		{"copy from stage", base + "COPY --from=build . .\nRUN pip install -r requirements.txt\n" + tail, nil},
+
+		{"apt without no-install-recommends", base + "RUN apt-get update && apt-get install -y curl\n" + tail,
+			[]ruleAt{{"dockerfile-apt-no-install-recommends", 2, core.SevLow}}},
+		{"apt with no-install-recommends", base + "RUN apt-get update && apt-get install -y --no-install-recommends curl\n" + tail, nil},
+		{"apt in heredoc", base + "RUN <<EOF\napt-get update\napt-get install -y curl\nEOF\n" + tail,
+			[]ruleAt{{"dockerfile-apt-no-install-recommends", 2, core.SevLow}}},
+
+		{"curl pipe sh", base + "RUN curl -fsSL https://get.example.com | sh\n" + tail, []ruleAt{{"dockerfile-curl-pipe-shell", 2, core.SevHigh}}},
+		{"wget pipe sudo bash", base + "RUN wget -qO- https://x.example.com/i.sh | sudo /bin/bash -s\n" + tail,
+			[]ruleAt{{"dockerfile-curl-pipe-shell", 2, core.SevHigh}}},
+		{"curl to file", base + "RUN curl -fsSLo /tmp/i.sh https://x.example.com/i.sh && sha256sum -c sums && sh /tmp/i.sh\n" + tail, nil},
+
+		{"no user", base + "HEALTHCHECK CMD true\n", []ruleAt{{"dockerfile-root-user", 1, core.SevMedium}}},
+		{"user root in final stage", base + "USER app\nRUN id\nUSER root\nHEALTHCHECK CMD true\n",
+			[]ruleAt{{"dockerfile-root-user", 4, core.SevMedium}}},
+		{"user 0", base + "USER 0:0\nHEALTHCHECK CMD true\n", []ruleAt{{"dockerfile-root-user", 2, core.SevMedium}}},
+		{"root only in build stage", "FROM " + pinned + " AS build\nUSER root\n" + base + tail, nil},
+		{"missing healthcheck", base + "USER app\n", []ruleAt{{"dockerfile-missing-healthcheck", 1, core.SevLow}}},
+		{"healthcheck only in unrelated stage", "FROM " + pinned + " AS build\n" + tail + base + "USER app\n",
+			[]ruleAt{{"dockerfile-missing-healthcheck", 4, core.SevLow}}},
+
+		// USER и HEALTHCHECK наследуются через FROM <стадия>
+		{"inherited via FROM stage", "FROM " + pinned + " AS base\n" + tail + "FROM base AS runtime\nCOPY app /app\n", nil},
+		{"inherited through two stages", "FROM " + pinned + " AS base\n" + tail + "FROM base AS mid\nRUN true\nFROM mid\nCMD [\"/app\"]\n", nil},
+		{"inherited user overridden with root", "FROM " + pinned + " AS base\n" + tail + "FROM base\nUSER root\n",
+			[]ruleAt{{"dockerfile-root-user", 5, core.SevMedium}}},
+		{"root in base, non-root in final", "FROM " + pinned + " AS base\nUSER root\nHEALTHCHECK CMD true\nFROM base\nUSER app\n", nil},
+		{"inherited root user", "FROM " + pinned + " AS base\nUSER root\nHEALTHCHECK CMD true\nFROM base\nRUN true\n",
+			[]ruleAt{{"dockerfile-root-user", 2, core.SevMedium}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.want
+			if want == nil {
+				want = []ruleAt{}
+			}
+			if got := scan(tt.src); !reflect.DeepEqual(got, want) {
+				t.Errorf("findings = %v, want %v\n%s", got, want, tt.src)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package container
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+)
+
+// InspectAPI — минимальный Docker-совместимый API поверх результатов inspect:
+// _ping, version, containers/json, containers/{id}/json. Нужен для офлайн-аудита
+// дампа 'docker inspect' (d-guard runtime --from-inspect) и для тестов Audit.
+func InspectAPI(containers []types.ContainerJSON, engine types.Version) http.Handler {
+	const apiVersion = "1.41" // Поддерживается и Docker 20.10+, и Podman 4+
+
+	if engine.APIVersion == "" {
+		engine.APIVersion = apiVersion
+	}
+	versioned := regexp.MustCompile(`^/v[0-9.]+`)
+
+	find := func(id string) *types.ContainerJSON {
+		for i, c := range containers {
+			if c.ContainerJSONBase == nil {
+				continue
+			}
+			if c.ID == id || strings.TrimPrefix(c.Name, "/") == id || (len(id) >= 12 && strings.HasPrefix(c.ID, id)) {
+				return &containers[i]
+			}
+		}
+		return nil
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("API-Version", apiVersion)
+		w.Header().Set("Content-Type", "application/json")
+		path := versioned.ReplaceAllString(r.URL.Path, "")
+
+		switch {
+		case path == "/_ping":
+			w.Write([]byte("OK"))
+		case path == "/version":
+			json.NewEncoder(w).Encode(engine)
+		case path == "/containers/json":
+			all := r.URL.Query().Get("all")
+			list := []types.Container{}
+			for _, c := range containers {
+				if c.ContainerJSONBase == nil {
+					continue
+				}
+				running := c.State == nil || c.State.Running
+				if !running && all != "1" && all != "true" {
+					continue
+				}
+				list = append(list, types.Container{ID: c.ID, Names: []string{c.Name}, Image: c.Image})
+			}
+			json.NewEncoder(w).Encode(list)
+		case strings.HasPrefix(path, "/containers/") && strings.HasSuffix(path, "/json"):
+			id := strings.TrimSuffix(strings.TrimPrefix(path, "/containers/"), "/json")
+			c := find(id)
+			if c == nil {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(map[string]string{"message": "No such container: " + id})
+				return
+			}
+			json.NewEncoder(w).Encode(c)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"message": "page not found"})
+		}
+	})
+}
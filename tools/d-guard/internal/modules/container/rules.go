@@ -0,0 +1,249 @@
+package container
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/devos-os/d-guard/internal/core"
+)
+
+// --- STATIC ANALYSIS ---
+// Правила в духе hadolint поверх разобранного Dockerfile (см. dockerfile.go).
+
+var (
+	secretName    = regexp.MustCompile(`(?i)(passw(or)?d|secret|token|api_?key|access_?key|private_?key|credentials?|(^|_)pwd$)`)
+	curlPipeShell = regexp.MustCompile(`(?i)\b(curl|wget)\b[^|;&\n]*\|\s*(sudo\s+)?(\S*/)?(ba|da|z|k)?sh\b`)
+	depInstall    = regexp.MustCompile(`\b(npm\s+(ci|install|i)|yarn\s+install|pnpm\s+(install|i)|pip3?\s+install|poetry\s+install|pipenv\s+install|go\s+mod\s+download|bundle\s+install|composer\s+install|cargo\s+fetch|mvn\b.*dependency:)\b`)
+	shellSplit    = regexp.MustCompile(`&&|\|\||;|\n`)
+	archiveExt    = regexp.MustCompile(`(?i)\.(tar|tar\.(gz|bz2|xz|zst)|tgz|tbz2?|txz)$`)
+)
+
+// ScanDockerfile — статический анализ одного Dockerfile (runtime — см. Audit)
+func ScanDockerfile(path string, data []byte) []core.Issue {
+	d := ParseDockerfile(data)
+	var issues []core.Issue
+	add := func(line int, sev core.Severity, rule, msg, suggestion string) {
+		issues = append(issues, core.Issue{
+			Scanner: "Docker Static", Severity: sev, File: path, Line: line, RuleID: rule,
+			Message: msg, Suggestion: suggestion,
+		})
+	}
+
+	for _, s := range d.Stages {
+		checkBaseImage(d, s, add)
+
+		copyAll := 0 // Строка COPY . . в текущей стадии
+		for _, ins := range s.Instructions {
+			switch ins.Cmd {
+			case "ADD":
+				checkAdd(ins, add)
+			case "ENV":
+				for _, kv := range parseArgs(ins.Args) {
+					if LooksLikeSecret(kv.Key) {
+						add(ins.Line, core.SevHigh, "dockerfile-env-secret",
+							fmt.Sprintf("Potential secret in ENV variable %s", kv.Key),
+							"ENV is baked into the image. Mount secrets at runtime or use 'RUN --mount=type=secret'")
+					}
+				}
+			case "ARG":
+				for _, kv := range parseArgs(ins.Args) {
+					if !LooksLikeSecret(kv.Key) {
+						continue
+					}
+					sev := core.SevMedium
+					if kv.Value != "" {
+						sev = core.SevHigh // Значение по умолчанию лежит прямо в Dockerfile
+					}
+					add(ins.Line, sev, "dockerfile-arg-secret",
+						fmt.Sprintf("Potential secret passed as build ARG %s", kv.Key),
+						"Build args are visible in 'docker history'. Use 'RUN --mount=type=secret,id=...' instead")
+				}
+			case "COPY":
+				if copyAll == 0 && copiesContext(ins) {
+					copyAll = ins.Line
+				}
+			case "RUN":
+				cmd := ins.Command()
+				if copyAll > 0 && depInstall.MatchString(cmd) {
+					add(copyAll, core.SevLow, "dockerfile-copy-before-install",
+						"'COPY . .' before dependency install invalidates the layer cache on every change",
+						"Copy only dependency manifests (package.json, go.mod, requirements.txt...), install, then copy the rest")
+					copyAll = -1 // Одна находка на стадию
+				}
+				checkRun(ins, cmd, add)
+			}
+		}
+	}
+
+	if final := d.Final(); final != nil {
+		checkFinalStage(d, final, add)
+	}
+	return issues
+}
+
+type emitFunc func(line int, sev core.Severity, rule, msg, suggestion string)
+
+// checkBaseImage: тег latest / отсутствие тега и неприкрепленный digest
+func checkBaseImage(d *Dockerfile, s *Stage, add emitFunc) {
+	image := s.Image
+	if image == "" || strings.EqualFold(image, "scratch") || unresolvedArgs(s.RawImage, d.Args) {
+		return // Неразрешенный ARG — проверить нечего
+	}
+	if prev := d.StageNamed(image); prev != nil && prev.Index < s.Index {
+		return // FROM <предыдущая стадия>
+	}
+
+	_, tag, digest := SplitImage(image)
+	switch {
+	case digest == "" && tag == "":
+		add(s.From.Line, core.SevMedium, "dockerfile-latest-tag",
+			fmt.Sprintf("Base image '%s' has no tag (defaults to ':latest')", image),
+			"Pin specific version (e.g., node:18-alpine) for reproducibility")
+	case digest == "" && tag == "latest":
+		add(s.From.Line, core.SevMedium, "dockerfile-latest-tag",
+			fmt.Sprintf("Base image '%s' uses ':latest' tag", image),
+			"Pin specific version (e.g., node:18-alpine) for reproducibility")
+	case digest == "":
+		add(s.From.Line, core.SevLow, "dockerfile-unpinned-digest",
+			fmt.Sprintf("Base image '%s' is not pinned by digest", image),
+			"Tags are mutable. Pin the image as name:tag@sha256:<digest>")
+	}
+}
+
+// checkAdd: ADD допустим только для распаковки локальных архивов
+func checkAdd(ins Instruction, add emitFunc) {
+	for _, src := range sources(ins) {
+		switch {
+		case strings.Contains(src, "://"):
+			add(ins.Line, core.SevMedium, "dockerfile-add-instead-of-copy",
+				fmt.Sprintf("'ADD' downloads remote URL %s", src),
+				"Download with curl/wget in RUN and verify a checksum, or use 'ADD --checksum=sha256:...'")
+			return
+		case !archiveExt.MatchString(src):
+			add(ins.Line, core.SevLow, "dockerfile-add-instead-of-copy",
+				"Use 'COPY' instead of 'ADD'",
+				"'ADD' can fetch remote URLs and unpack archives unexpectedly")
+			return
+		}
+	}
+}
+
+func checkRun(ins Instruction, cmd string, add emitFunc) {
+	for _, part := range shellSplit.Split(cmd, -1) {
+		f := strings.Fields(part)
+		if len(f) < 2 || !containsWord(f, "apt-get") || !containsWord(f, "install") {
+			continue
+		}
+		if !strings.Contains(part, "--no-install-recommends") && !strings.Contains(part, "Install-Recommends=false") {
+			add(ins.Line, core.SevLow, "dockerfile-apt-no-install-recommends",
+				"'apt-get install' without '--no-install-recommends'",
+				"Add '--no-install-recommends' to avoid pulling unneeded packages into the image")
+			break
+		}
+	}
+
+	if curlPipeShell.MatchString(cmd) {
+		add(ins.Line, core.SevHigh, "dockerfile-curl-pipe-shell",
+			"Remote script is piped straight into a shell",
+			"Download the script, verify its checksum or signature, then execute it")
+	}
+}
+
+// checkFinalStage: USER и HEALTHCHECK наследуются через FROM <стадия>, поэтому идем по цепочке
+func checkFinalStage(d *Dockerfile, final *Stage, add emitFunc) {
+	var user *Instruction
+	healthcheck := false
+	for s := final; s != nil; {
+		for i := len(s.Instructions) - 1; i >= 0; i-- {
+			ins := s.Instructions[i]
+			if ins.Cmd == "USER" && user == nil {
+				user = &s.Instructions[i]
+			}
+			if ins.Cmd == "HEALTHCHECK" {
+				healthcheck = true
+			}
+		}
+		prev := d.StageNamed(s.Image)
+		if prev == nil || prev.Index >= s.Index {
+			break
+		}
+		s = prev
+	}
+
+	switch {
+	case user == nil:
+		add(final.From.Line, core.SevMedium, "dockerfile-root-user",
+			"Running as root (No USER instruction)",
+			"Create a non-root user and switch to it using 'USER'")
+	case isRoot(user.Args):
+		add(user.Line, core.SevMedium, "dockerfile-root-user",
+			"Final stage switches to 'USER root'",
+			"Switch back to a non-root user after privileged steps")
+	}
+
+	if !healthcheck {
+		add(final.From.Line, core.SevLow, "dockerfile-missing-healthcheck",
+			"Final image has no HEALTHCHECK",
+			"Add 'HEALTHCHECK CMD ...' so the orchestrator can detect a hung container")
+	}
+}
+
+// sources — источники COPY/ADD (все аргументы, кроме последнего)
+func sources(ins Instruction) []string {
+	args := ins.Exec
+	if args == nil {
+		args = splitWords(ins.Args)
+	}
+	if len(args) < 2 {
+		return nil
+	}
+	var out []string
+	for _, a := range args[:len(args)-1] {
+		if !strings.HasPrefix(a, "<<") {
+			out = append(out, unquote(a))
+		}
+	}
+	return out
+}
+
+// copiesContext: COPY всего контекста сборки (не из другой стадии)
+func copiesContext(ins Instruction) bool {
+	if _, ok := ins.Flag("from"); ok {
+		return false
+	}
+	for _, src := range sources(ins) {
+		if src == "." || src == "./" {
+			return true
+		}
+	}
+	return false
+}
+
+// SplitImage делит ссылку на образ (registry:5000/app:1.2@sha256:...) на имя, тег и digest
+func SplitImage(image string) (name, tag, digest string) {
+	name, digest, _ = strings.Cut(image, "@")
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, tag = name[:i], name[i+1:]
+	}
+	return name, tag, digest
+}
+
+// LooksLikeSecret — имя переменной похоже на секрет (ENV/ARG, environment в compose, env в k8s)
+func LooksLikeSecret(name string) bool {
+	return secretName.MatchString(name)
+}
+
+func isRoot(user string) bool {
+	name, _, _ := strings.Cut(strings.TrimSpace(user), ":")
+	return name == "root" || name == "0"
+}
+
+func containsWord(fields []string, w string) bool {
+	for _, f := range fields {
+		if f == w {
+			return true
+		}
+	}
+	return false
+}
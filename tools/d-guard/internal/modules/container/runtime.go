@@ -0,0 +1,230 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/devos-os/d-guard/internal/core"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+)
+
+// --- RUNTIME ANALYSIS ---
+// Аудит запущенных контейнеров в духе CIS Docker Benchmark (раздел 5).
+// Работает с Docker и Podman: оба отдают Docker-совместимый API.
+
+// Endpoint выбирает API-сокет: --host, DOCKER_HOST, CONTAINER_HOST (podman --remote),
+// затем известные сокеты Docker и Podman (rootful и rootless).
+func Endpoint(host string) string {
+	if host != "" {
+		return host
+	}
+	for _, env := range []string{"DOCKER_HOST", "CONTAINER_HOST"} {
+		if h := os.Getenv(env); h != "" {
+			return h
+		}
+	}
+	for _, sock := range sockets() {
+		if st, err := os.Stat(sock); err == nil && st.Mode()&os.ModeSocket != 0 {
+			return "unix://" + sock
+		}
+	}
+	return client.DefaultDockerHost
+}
+
+func sockets() []string {
+	list := []string{"/var/run/docker.sock"}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		list = append(list, filepath.Join(dir, "podman", "podman.sock"), filepath.Join(dir, "docker.sock"))
+	}
+	list = append(list, "/run/podman/podman.sock")
+	if home, err := os.UserHomeDir(); err == nil {
+		list = append(list, filepath.Join(home, ".docker", "run", "docker.sock")) // Docker Desktop
+	}
+	return list
+}
+
+// Connect создает клиент API (TLS-настройки DOCKER_* учитываются через FromEnv)
+func Connect(host string) (*client.Client, error) {
+	// Используем WithAPIVersionNegotiation для совместимости (Podman отдает более старую версию API)
+	return client.NewClientWithOpts(client.FromEnv, client.WithHost(Endpoint(host)), client.WithAPIVersionNegotiation())
+}
+
+// Engine — название и версия движка для отчета ("Docker Engine - Community 24.0.7", "Podman Engine 4.9.3")
+func Engine(ctx context.Context, cli client.APIClient) string {
+	v, err := cli.ServerVersion(ctx)
+	if err != nil {
+		return ""
+	}
+	if v.Platform.Name == "" {
+		return v.Version
+	}
+	return v.Platform.Name + " " + v.Version
+}
+
+// Audit проверяет контейнеры (all — включая остановленные)
+func Audit(ctx context.Context, cli client.APIClient, all bool) ([]core.Issue, error) {
+	containers, err := cli.ContainerList(ctx, types.ContainerListOptions{All: all})
+	if err != nil {
+		return nil, fmt.Errorf("cannot list containers: %w", err)
+	}
+
+	var issues []core.Issue
+	for _, c := range containers {
+		info, err := cli.ContainerInspect(ctx, c.ID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return issues, ctx.Err()
+			}
+			continue // Контейнер мог исчезнуть между list и inspect
+		}
+		issues = append(issues, auditContainer(info)...)
+	}
+	return issues, nil
+}
+
+// auditContainer — проверки одного контейнера по результату inspect
+func auditContainer(info types.ContainerJSON) []core.Issue {
+	if info.ContainerJSONBase == nil || info.HostConfig == nil {
+		return nil
+	}
+	name := strings.TrimPrefix(info.Name, "/")
+	hc := info.HostConfig
+
+	var issues []core.Issue
+	add := func(sev core.Severity, rule, msg, suggestion string) {
+		issues = append(issues, core.Issue{
+			Scanner: "Docker Runtime", Severity: sev, RuleID: rule,
+			File:        "container://" + name,
+			Message:     fmt.Sprintf("Container '%s' %s", name, msg),
+			Description: fmt.Sprintf("Container %s (%s), image %s", name, shortID(info.ID), info.Image),
+			Suggestion:  suggestion,
+		})
+	}
+
+	if hc.Privileged {
+		add(core.SevCritical, "runtime-privileged", "is running in PRIVILEGED mode",
+			"Disable privileged mode unless absolutely necessary")
+	}
+
+	for _, c := range hc.CapAdd {
+		sev := core.SevMedium
+		if DangerousCapability(c) {
+			sev = core.SevHigh
+		}
+		add(sev, "runtime-added-capabilities", fmt.Sprintf("has added capability %s", c),
+			"Run with '--cap-drop=ALL' and add back only what is strictly required")
+	}
+
+	// Старый синтаксис --security-opt использует ':' вместо '='
+	apparmor := info.AppArmorProfile == "unconfined"
+	for _, opt := range hc.SecurityOpt {
+		switch strings.Replace(opt, ":", "=", 1) {
+		case "seccomp=unconfined":
+			add(core.SevHigh, "runtime-seccomp-unconfined", "runs without a seccomp profile",
+				"Remove '--security-opt seccomp=unconfined' (the default profile blocks dangerous syscalls)")
+		case "apparmor=unconfined":
+			apparmor = true
+		}
+	}
+	if apparmor {
+		add(core.SevMedium, "runtime-apparmor-unconfined", "runs without an AppArmor profile",
+			"Remove '--security-opt apparmor=unconfined' and keep the default profile (docker-default)")
+	}
+
+	if !hc.ReadonlyRootfs {
+		add(core.SevLow, "runtime-writable-rootfs", "has a writable root filesystem",
+			"Run with '--read-only' and mount writable tmpfs/volumes only where needed")
+	}
+
+	namespaces := []struct {
+		name string
+		host bool
+	}{{"network", hc.NetworkMode.IsHost()}, {"pid", hc.PidMode.IsHost()}, {"ipc", hc.IpcMode.IsHost()}, {"uts", hc.UTSMode.IsHost()}}
+	for _, ns := range namespaces {
+		if ns.host {
+			add(core.SevHigh, "runtime-host-namespace", fmt.Sprintf("shares the host %s namespace", ns.name),
+				fmt.Sprintf("Do not use '--%s=host'", ns.name))
+		}
+	}
+
+	switch rp := hc.RestartPolicy; {
+	case rp.Name == "always":
+		add(core.SevLow, "runtime-restart-policy", "uses restart policy 'always'",
+			"Use '--restart=on-failure:5' so a crashing container cannot restart forever")
+	case rp.Name == "on-failure" && rp.MaximumRetryCount == 0:
+		add(core.SevLow, "runtime-restart-policy", "restarts on failure without a retry limit",
+			"Use '--restart=on-failure:5'")
+	}
+
+	if hc.Memory == 0 {
+		add(core.SevLow, "runtime-no-memory-limit", "has no memory limit",
+			"Set '--memory' so one container cannot exhaust host memory")
+	}
+
+	if info.Config != nil {
+		user, _, _ := strings.Cut(info.Config.User, ":")
+		if user == "" || user == "root" || user == "0" {
+			add(core.SevMedium, "runtime-root-user", "is running as UID 0",
+				"Run as a non-root user ('--user' or USER in the Dockerfile)")
+		}
+	}
+
+	if info.NetworkSettings != nil {
+		var ports []nat.Port
+		for port := range info.NetworkSettings.Ports {
+			ports = append(ports, port)
+		}
+		sort.Slice(ports, func(i, j int) bool { return ports[i].Int() < ports[j].Int() })
+		for _, port := range ports {
+			bindings := info.NetworkSettings.Ports[port]
+			if port.Port() == "22" && len(bindings) > 0 {
+				add(core.SevHigh, "runtime-ssh-port", "exposes SSH port 22",
+					"Do not run SSH inside containers")
+			}
+			for _, b := range bindings {
+				if b.HostIP == "" || b.HostIP == "0.0.0.0" || b.HostIP == "::" {
+					add(core.SevLow, "runtime-port-all-interfaces", fmt.Sprintf("publishes %s on all interfaces (%s:%s)", port, hostIP(b.HostIP), b.HostPort),
+						"Bind to a specific interface, e.g. '-p 127.0.0.1:8080:80'")
+					break
+				}
+			}
+		}
+	}
+
+	for _, mount := range info.Mounts {
+		if strings.Contains(mount.Source, "docker.sock") || strings.Contains(mount.Source, "podman.sock") {
+			add(core.SevHigh, "runtime-docker-socket", fmt.Sprintf("has %s mounted", filepath.Base(mount.Source)),
+				"This gives full root access to the host. Use API proxy instead.")
+		}
+	}
+
+	return issues
+}
+
+// dangerousCaps — возможности, фактически дающие root на хосте
+var dangerousCaps = map[string]bool{"ALL": true, "SYS_ADMIN": true, "NET_ADMIN": true, "SYS_PTRACE": true, "SYS_MODULE": true, "DAC_READ_SEARCH": true}
+
+// DangerousCapability: SYS_ADMIN, CAP_SYS_ADMIN, ALL и т.п. (для runtime, compose и Kubernetes)
+func DangerousCapability(c string) bool {
+	return dangerousCaps[strings.TrimPrefix(strings.ToUpper(c), "CAP_")]
+}
+
+func hostIP(ip string) string {
+	if ip == "" {
+		return "0.0.0.0"
+	}
+	return ip
+}
+
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
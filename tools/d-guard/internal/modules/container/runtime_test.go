@@ -0,0 +1,182 @@
+package container
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/devos-os/d-guard/internal/core"
+	"github.com/docker/docker/api/types"
+	dcontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+)
+
+// hardened — контейнер, проходящий все проверки; случаи теста портят одно поле
+func hardened(name string) types.ContainerJSON {
+	return types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID:              name + "0123456789abcdef",
+			Name:            "/" + name,
+			Image:           "registry.example.com/app:1.0",
+			State:           &types.ContainerState{Running: true},
+			AppArmorProfile: "docker-default",
+			HostConfig: &dcontainer.HostConfig{
+				ReadonlyRootfs: true,
+				RestartPolicy:  dcontainer.RestartPolicy{Name: "on-failure", MaximumRetryCount: 5},
+				Resources:      dcontainer.Resources{Memory: 256 << 20},
+				NetworkMode:    "bridge",
+			},
+		},
+		Config: &dcontainer.Config{User: "1000:1000"},
+		NetworkSettings: &types.NetworkSettings{NetworkSettingsBase: types.NetworkSettingsBase{
+			Ports: nat.PortMap{"8080/tcp": {{HostIP: "127.0.0.1", HostPort: "8080"}}},
+		}},
+	}
+}
+
+// audit поднимает InspectAPI и проверяет контейнеры через настоящий клиент Docker
+func audit(t *testing.T, all bool, containers ...types.ContainerJSON) []core.Issue {
+	t.Helper()
+	srv := httptest.NewServer(InspectAPI(containers, types.Version{Version: "test"}))
+	defer srv.Close()
+	cli, err := client.NewClientWithOpts(client.WithHost("tcp://"+srv.Listener.Addr().String()), client.WithAPIVersionNegotiation())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+
+	if v := Engine(context.Background(), cli); v != "test" {
+		t.Errorf("Engine = %q, want %q", v, "test")
+	}
+	issues, err := Audit(context.Background(), cli, all)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return issues
+}
+
+type finding struct {
+	Rule     string
+	Severity core.Severity
+}
+
+func findings(issues []core.Issue) []finding {
+	out := []finding{}
+	for _, is := range issues {
+		out = append(out, finding{is.RuleID, is.Severity})
+	}
+	return sorted(out)
+}
+
+func sorted(f []finding) []finding {
+	sort.Slice(f, func(i, j int) bool {
+		if f[i].Rule != f[j].Rule {
+			return f[i].Rule < f[j].Rule
+		}
+		return f[i].Severity < f[j].Severity
+	})
+	return f
+}
+
+func TestAuditRuntime(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *types.ContainerJSON)
+		want   []finding
+	}{
+		{"hardened", func(c *types.ContainerJSON) {}, nil},
+		{"privileged", func(c *types.ContainerJSON) { c.HostConfig.Privileged = true },
+			[]finding{{"runtime-privileged", core.SevCritical}}},
+		{"cap_add", func(c *types.ContainerJSON) { c.HostConfig.CapAdd = []string{"NET_BIND_SERVICE", "CAP_SYS_ADMIN"} },
+			[]finding{{"runtime-added-capabilities", core.SevHigh}, {"runtime-added-capabilities", core.SevMedium}}},
+		{"seccomp unconfined", func(c *types.ContainerJSON) { c.HostConfig.SecurityOpt = []string{"seccomp=unconfined"} },
+			[]finding{{"runtime-seccomp-unconfined", core.SevHigh}}},
+		{"seccomp unconfined, old syntax", func(c *types.ContainerJSON) { c.HostConfig.SecurityOpt = []string{"seccomp:unconfined"} },
+			[]finding{{"runtime-seccomp-unconfined", core.SevHigh}}},
+		{"apparmor unconfined option", func(c *types.ContainerJSON) { c.HostConfig.SecurityOpt = []string{"apparmor=unconfined"} },
+			[]finding{{"runtime-apparmor-unconfined", core.SevMedium}}},
+		{"apparmor unconfined profile", func(c *types.ContainerJSON) { c.AppArmorProfile = "unconfined" },
+			[]finding{{"runtime-apparmor-unconfined", core.SevMedium}}},
+		{"host pid", func(c *types.ContainerJSON) { c.HostConfig.PidMode = "host" },
+			[]finding{{"runtime-host-namespace", core.SevHigh}}},
+		{"host ipc", func(c *types.ContainerJSON) { c.HostConfig.IpcMode = "host" },
+			[]finding{{"runtime-host-namespace", core.SevHigh}}},
+		{"host network", func(c *types.ContainerJSON) { c.HostConfig.NetworkMode = "host" },
+			[]finding{{"runtime-host-namespace", core.SevHigh}}},
+		{"root by default", func(c *types.ContainerJSON) { c.Config.User = "" },
+			[]finding{{"runtime-root-user", core.SevMedium}}},
+		{"root by name", func(c *types.ContainerJSON) { c.Config.User = "root" },
+			[]finding{{"runtime-root-user", core.SevMedium}}},
+		{"uid 0 with group", func(c *types.ContainerJSON) { c.Config.User = "0:1000" },
+			[]finding{{"runtime-root-user", core.SevMedium}}},
+		{"bind 0.0.0.0", func(c *types.ContainerJSON) {
+			c.NetworkSettings.Ports = nat.PortMap{"80/tcp": {{HostIP: "0.0.0.0", HostPort: "80"}, {HostIP: "::", HostPort: "80"}}}
+		}, []finding{{"runtime-port-all-interfaces", core.SevLow}}},
+		{"bind without host ip", func(c *types.ContainerJSON) {
+			c.NetworkSettings.Ports = nat.PortMap{"443/tcp": {{HostPort: "443"}}}
+		}, []finding{{"runtime-port-all-interfaces", core.SevLow}}},
+		{"exposed but not published", func(c *types.ContainerJSON) {
+			c.NetworkSettings.Ports = nat.PortMap{"22/tcp": nil}
+		}, nil},
+		{"ssh published", func(c *types.ContainerJSON) {
+			c.NetworkSettings.Ports = nat.PortMap{"22/tcp": {{HostIP: "127.0.0.1", HostPort: "2222"}}}
+		}, []finding{{"runtime-ssh-port", core.SevHigh}}},
+		{"restart always", func(c *types.ContainerJSON) { c.HostConfig.RestartPolicy = dcontainer.RestartPolicy{Name: "always"} },
+			[]finding{{"runtime-restart-policy", core.SevLow}}},
+		{"restart on-failure without limit", func(c *types.ContainerJSON) {
+			c.HostConfig.RestartPolicy = dcontainer.RestartPolicy{Name: "on-failure"}
+		}, []finding{{"runtime-restart-policy", core.SevLow}}},
+		{"restart unless-stopped", func(c *types.ContainerJSON) {
+			c.HostConfig.RestartPolicy = dcontainer.RestartPolicy{Name: "unless-stopped"}
+		}, nil},
+		{"no memory limit", func(c *types.ContainerJSON) { c.HostConfig.Memory = 0 },
+			[]finding{{"runtime-no-memory-limit", core.SevLow}}},
+		{"writable rootfs", func(c *types.ContainerJSON) { c.HostConfig.ReadonlyRootfs = false },
+			[]finding{{"runtime-writable-rootfs", core.SevLow}}},
+		{"docker socket", func(c *types.ContainerJSON) {
+			c.Mounts = []types.MountPoint{{Source: "/var/run/docker.sock", Destination: "/var/run/docker.sock"}}
+		}, []finding{{"runtime-docker-socket", core.SevHigh}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := hardened("app")
+			tt.modify(&c)
+			got := findings(audit(t, false, c))
+			want := sorted(append([]finding{}, tt.want...))
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("findings = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestAuditStoppedContainers(t *testing.T) {
+	running := hardened("running")
+	running.HostConfig.Privileged = true
+	stopped := hardened("stopped")
+	stopped.State = &types.ContainerState{Running: false, Status: "exited"}
+	stopped.HostConfig.Privileged = true
+
+	if got := audit(t, false, running, stopped); len(got) != 1 || got[0].File != "container://running" {
+		t.Errorf("running only: %v", got)
+	}
+	if got := audit(t, true, running, stopped); len(got) != 2 {
+		t.Errorf("--all: %d issues, want 2", len(got))
+	}
+}
+
+func TestInspectAPINotFound(t *testing.T) {
+	srv := httptest.NewServer(InspectAPI(nil, types.Version{}))
+	defer srv.Close()
+	cli, err := client.NewClientWithOpts(client.WithHost("tcp://"+srv.Listener.Addr().String()), client.WithAPIVersionNegotiation())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+	if _, err := cli.ContainerInspect(context.Background(), "missing"); !client.IsErrNotFound(err) {
+		t.Errorf("ContainerInspect(missing): err = %v, want not found", err)
+	}
+}
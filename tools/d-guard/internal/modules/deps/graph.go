@@ -0,0 +1,166 @@
+package deps
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/devos-os/d-guard/internal/license"
+	"github.com/devos-os/d-guard/internal/osv"
+	"github.com/devos-os/d-guard/internal/source"
+)
+
+// Graph — пути от проекта до пакетов lockfile по связям Requires
+type Graph struct {
+	pkgs   []Package
+	parent []int // Предок на кратчайшем пути: -1 — прямая зависимость, -2 — путь неизвестен
+}
+
+// NewGraph строит кратчайшие пути обходом в ширину от прямых зависимостей.
+// Связи заданы именами, поэтому при нескольких версиях пакета путь приблизителен.
+func NewGraph(pkgs []Package) *Graph {
+	g := &Graph{pkgs: pkgs, parent: make([]int, len(pkgs))}
+	byName := map[string][]int{}
+	var queue []int
+	for i, p := range pkgs {
+		byName[graphKey(p.Ecosystem, p.Name)] = append(byName[graphKey(p.Ecosystem, p.Name)], i)
+		g.parent[i] = -2
+		if p.Direct {
+			g.parent[i] = -1
+			queue = append(queue, i)
+		}
+	}
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+		for _, name := range pkgs[u].Requires {
+			for _, v := range byName[graphKey(pkgs[u].Ecosystem, name)] {
+				if g.parent[v] == -2 {
+					g.parent[v] = u
+					queue = append(queue, v)
+				}
+			}
+		}
+	}
+	return g
+}
+
+// Path — цепочка name@version от прямой зависимости до pkgs[i] (nil — путь неизвестен)
+func (g *Graph) Path(i int) []string {
+	if g.parent[i] == -2 {
+		return nil
+	}
+	var path []string
+	for ; i >= 0; i = g.parent[i] {
+		path = append([]string{g.pkgs[i].Name + "@" + g.pkgs[i].Version}, path...)
+	}
+	return path
+}
+
+func graphKey(ecosystem, name string) string {
+	return osv.NormalizeName(ecosystem, name)
+}
+
+// withGoGraph берет зависимости модулей из их go.mod в кэше модулей
+// ($GOMODCACHE/cache/download/<module>/@v/<version>.mod). Без кэша граф пуст.
+func withGoGraph(pkgs []Package) {
+	cache := license.GoModCache()
+	if cache == "" {
+		return
+	}
+	for i, p := range pkgs {
+		mod := filepath.Join(cache, "cache", "download", license.EscapeModule(p.Name), "@v", license.EscapeModule(p.Version)+".mod")
+		data, err := os.ReadFile(mod)
+		if err != nil {
+			continue
+		}
+		for _, r := range parseGoMod(data) {
+			pkgs[i].Requires = append(pkgs[i].Requires, r.Name)
+		}
+	}
+}
+
+// markDirect отмечает прямые зависимости по манифесту рядом с lockfile,
+// если сам lockfile их не различает (package-lock v1, yarn v1, poetry.lock)
+func markDirect(fsys source.FS, lockfile string, pkgs []Package) {
+	for _, p := range pkgs {
+		if p.Direct {
+			return
+		}
+	}
+	if len(pkgs) == 0 {
+		return
+	}
+	dir := filepath.Dir(lockfile)
+	var names []string
+	switch filepath.Base(lockfile) {
+	case "package-lock.json", "npm-shrinkwrap.json", "yarn.lock":
+		names = packageJSONDeps(fsys, filepath.Join(dir, "package.json"))
+	case "poetry.lock":
+		names = pyprojectDeps(fsys, filepath.Join(dir, "pyproject.toml"))
+	}
+	direct := map[string]bool{}
+	for _, n := range names {
+		direct[graphKey(pkgs[0].Ecosystem, n)] = true
+	}
+	for i := range pkgs {
+		pkgs[i].Direct = direct[graphKey(pkgs[i].Ecosystem, pkgs[i].Name)]
+	}
+}
+
+func packageJSONDeps(fsys source.FS, path string) []string {
+	data, err := fsys.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var manifest npmPackage
+	if json.Unmarshal(data, &manifest) != nil {
+		return nil
+	}
+	return manifest.requires()
+}
+
+var (
+	// [project] dependencies = ["requests>=2", ...] и [project.optional-dependencies]
+	pep508Name = regexp.MustCompile(`"([A-Za-z0-9][A-Za-z0-9._-]*)`)
+	// [tool.poetry.dependencies], [tool.poetry.group.<name>.dependencies]
+	poetryDeps = regexp.MustCompile(`^\[tool\.poetry\.(dev-dependencies|dependencies|group\.[^.\]]+\.dependencies)\]$`)
+)
+
+// pyprojectDeps — имена зависимостей из pyproject.toml (построчно, без полного TOML)
+func pyprojectDeps(fsys source.FS, path string) []string {
+	data, err := fsys.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	section, inArray := "", false
+	for _, l := range lines(data) {
+		t := strings.TrimSpace(l)
+		if strings.HasPrefix(t, "[") && !inArray {
+			section = t
+			continue
+		}
+		switch {
+		case poetryDeps.MatchString(section):
+			if m := tomlKey.FindStringSubmatch(t); m != nil && m[1] != "python" {
+				names = append(names, m[1])
+			}
+		case section == "[project]" && (inArray || strings.HasPrefix(t, "dependencies")),
+			section == "[project.optional-dependencies]":
+			if section == "[project]" {
+				inArray = !strings.HasSuffix(t, "]")
+			}
+			value := t
+			if _, v, ok := strings.Cut(t, "="); ok && !strings.HasPrefix(t, `"`) {
+				value = v
+			}
+			for _, m := range pep508Name.FindAllStringSubmatch(value, -1) {
+				names = append(names, m[1])
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,577 @@
+package deps
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/devos-os/d-guard/internal/license"
+	"github.com/devos-os/d-guard/internal/osv"
+	"github.com/devos-os/d-guard/internal/source"
+)
+
+// Package — зависимость из lockfile с позицией для отчета
+type Package struct {
+	Ecosystem string
+	Name      string
+	Version   string
+	Line      int
+	Hashes    []Hash // Контрольные суммы артефактов, если lockfile их хранит (для SBOM)
+	License   string // Лицензия из lockfile (только package-lock.json v2/v3)
+
+	Direct   bool     // Прямая зависимость проекта (из lockfile или манифеста рядом)
+	Requires []string // Имена зависимостей пакета: граф для пути "проект → a → b" (см. Graph)
+}
+
+// Hash — контрольная сумма: Alg — SHA-1, SHA-256, SHA-512 (Value в hex)
+// или h1 — хэш дерева модуля из go.sum (Value как есть, "h1:...")
+type Hash struct {
+	Alg   string
+	Value string
+}
+
+// parser разбирает содержимое lockfile
+type parser func(data []byte) []Package
+
+var requirementsName = regexp.MustCompile(`(?i)^requirements.*\.txt$`)
+
+// parserFor выбирает парсер по имени файла (nil — не lockfile)
+func parserFor(path string) parser {
+	base := filepath.Base(path)
+	switch base {
+	case "go.mod":
+		return parseGoMod
+	case "go.sum":
+		return parseGoSum
+	case "Cargo.lock":
+		return parseTOMLPackages(osv.Crates)
+	case "poetry.lock":
+		return parseTOMLPackages(osv.PyPI)
+	case "package-lock.json", "npm-shrinkwrap.json":
+		return parsePackageLock
+	case "yarn.lock":
+		return parseYarnLock
+	}
+	if requirementsName.MatchString(base) {
+		return parseRequirements
+	}
+	return nil
+}
+
+// Parse читает и разбирает lockfile. Модули из go.mod дополняются хэшами из go.sum рядом
+// и графом из кэша модулей; прямые зависимости без пометок в lockfile берутся из манифеста
+// рядом (package.json, pyproject.toml).
+func Parse(fsys source.FS, path string) ([]Package, error) {
+	parse := parserFor(path)
+	if parse == nil {
+		return nil, fmt.Errorf("%s: unsupported lockfile", path)
+	}
+	data, err := fsys.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pkgs := parse(data)
+	if filepath.Base(path) == "go.mod" {
+		withGoSum(fsys, path, pkgs)
+		withGoGraph(pkgs)
+	}
+	markDirect(fsys, path, pkgs)
+	return pkgs, nil
+}
+
+func lines(data []byte) []string {
+	var out []string
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), 4<<20)
+	for sc.Scan() {
+		out = append(out, sc.Text())
+	}
+	return out
+}
+
+// --- Go ---
+
+// parseGoMod: require (одиночные и блоком) с учетом replace на другую версию модуля.
+// Замены на локальный путь убирают модуль: его версия не из реестра.
+func parseGoMod(data []byte) []Package {
+	var reqs []Package
+	replace := map[string]Package{}
+	local := map[string]bool{}
+
+	block := ""
+	for i, raw := range lines(data) {
+		l, _, _ := strings.Cut(raw, "//")
+		l = strings.TrimSpace(l)
+		switch {
+		case l == "":
+			continue
+		case block != "" && l == ")":
+			block = ""
+			continue
+		case strings.HasSuffix(l, "("):
+			block = strings.TrimSpace(strings.TrimSuffix(l, "("))
+			continue
+		}
+
+		kind, rest := block, l
+		if block == "" {
+			kind, rest, _ = strings.Cut(l, " ")
+		}
+		f := strings.Fields(rest)
+		switch kind {
+		case "require":
+			if len(f) >= 2 {
+				_, comment, _ := strings.Cut(raw, "//")
+				direct := !strings.Contains(comment, "indirect")
+				reqs = append(reqs, Package{Ecosystem: osv.Go, Name: f[0], Version: f[1], Line: i + 1, Direct: direct})
+			}
+		case "replace":
+			// old [v] => new [v]
+			arrow := indexOf(f, "=>")
+			if arrow < 1 || arrow+1 >= len(f) {
+				continue
+			}
+			if arrow+2 < len(f) {
+				replace[f[0]] = Package{Ecosystem: osv.Go, Name: f[arrow+1], Version: f[arrow+2]}
+			} else {
+				local[f[0]] = true
+			}
+		}
+	}
+
+	var out []Package
+	for _, p := range reqs {
+		if local[p.Name] {
+			continue
+		}
+		if r, ok := replace[p.Name]; ok {
+			r.Line, r.Direct = p.Line, p.Direct
+			p = r
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// parseGoSum — запасной вариант без go.mod рядом: все версии, кроме записей /go.mod
+func parseGoSum(data []byte) []Package {
+	var out []Package
+	seen := map[string]bool{}
+	for i, l := range lines(data) {
+		f := strings.Fields(l)
+		if len(f) < 3 || strings.HasSuffix(f[1], "/go.mod") || seen[f[0]+"@"+f[1]] {
+			continue
+		}
+		seen[f[0]+"@"+f[1]] = true
+		out = append(out, Package{Ecosystem: osv.Go, Name: f[0], Version: f[1], Line: i + 1, Hashes: []Hash{{Alg: "h1", Value: f[2]}}})
+	}
+	return out
+}
+
+// withGoSum дополняет модули из go.mod хэшами из go.sum того же каталога
+func withGoSum(fsys source.FS, mod string, pkgs []Package) {
+	data, err := fsys.ReadFile(filepath.Join(filepath.Dir(mod), "go.sum"))
+	if err != nil {
+		return
+	}
+	sums := map[string][]Hash{}
+	for _, p := range parseGoSum(data) {
+		sums[p.Name+"@"+p.Version] = p.Hashes
+	}
+	for i := range pkgs {
+		pkgs[i].Hashes = sums[pkgs[i].Name+"@"+pkgs[i].Version]
+	}
+}
+
+func indexOf(list []string, v string) int {
+	for i, s := range list {
+		if s == v {
+			return i
+		}
+	}
+	return -1
+}
+
+// --- Cargo.lock, poetry.lock: [[package]] name = "..." version = "..." ---
+
+var (
+	tomlField = regexp.MustCompile(`^(name|version|checksum|source)\s*=\s*"([^"]*)"`)
+	// poetry.lock: files = [{file = "...", hash = "sha256:..."}] внутри [[package]]
+	tomlHash = regexp.MustCompile(`hash\s*=\s*"(sha256|sha512):([0-9a-fA-F]+)"`)
+	// Cargo.lock: dependencies = [ "serde", "syn 2.0.39", ] — имя и, при нескольких версиях, версия
+	tomlQuoted = regexp.MustCompile(`"([^"\s]+)[^"]*"`)
+	// poetry.lock: [package.dependencies] name = "..." / name = {version = "..."}
+	tomlKey = regexp.MustCompile(`^"?([A-Za-z0-9][A-Za-z0-9._-]*)"?\s*=`)
+)
+
+func parseTOMLPackages(ecosystem string) parser {
+	return func(data []byte) []Package {
+		var out []Package
+		var sourced []bool // Cargo: у пакета есть source (реестр/git), без него — член workspace
+		var cur Package
+		hasSource := false
+		last := -1 // Последний добавленный пакет (для секции [package.dependencies])
+		flush := func() {
+			if cur.Name != "" && cur.Version != "" {
+				out = append(out, cur)
+				sourced = append(sourced, hasSource)
+				last = len(out) - 1
+			}
+			cur, hasSource = Package{Ecosystem: ecosystem}, false
+		}
+		cur.Ecosystem = ecosystem
+		section, inArray := "", false
+		for i, l := range lines(data) {
+			t := strings.TrimSpace(l)
+			if strings.HasPrefix(t, "[") && !inArray {
+				flush()
+				section = t
+				continue
+			}
+			switch section {
+			case "[package.dependencies]":
+				if m := tomlKey.FindStringSubmatch(t); m != nil && last >= 0 {
+					out[last].Requires = append(out[last].Requires, m[1])
+				}
+				continue
+			case "[[package]]":
+			default:
+				continue
+			}
+
+			if inArray || strings.HasPrefix(t, "dependencies = [") {
+				inArray = !strings.HasSuffix(t, "]")
+				for _, m := range tomlQuoted.FindAllStringSubmatch(strings.TrimPrefix(t, "dependencies = "), -1) {
+					cur.Requires = append(cur.Requires, m[1])
+				}
+				continue
+			}
+			if m := tomlField.FindStringSubmatch(t); m != nil {
+				switch m[1] {
+				case "name":
+					cur.Name, cur.Line = m[2], i+1
+				case "version":
+					cur.Version = m[2]
+				case "checksum": // Cargo.lock: sha256 архива .crate
+					cur.Hashes = append(cur.Hashes, Hash{Alg: "SHA-256", Value: m[2]})
+				case "source":
+					hasSource = true
+				}
+				continue
+			}
+			for _, m := range tomlHash.FindAllStringSubmatch(t, -1) {
+				cur.Hashes = append(cur.Hashes, Hash{Alg: hashAlg(m[1]), Value: strings.ToLower(m[2])})
+			}
+		}
+		flush()
+		if ecosystem == osv.Crates {
+			return withoutWorkspace(out, sourced)
+		}
+		return out
+	}
+}
+
+// withoutWorkspace убирает крейты самого проекта (без source): их нет в реестре,
+// а их зависимости — прямые зависимости проекта. Lockfile совсем без source
+// (написанный вручную) остается как есть.
+func withoutWorkspace(pkgs []Package, sourced []bool) []Package {
+	registry := false
+	for _, s := range sourced {
+		registry = registry || s
+	}
+	if !registry {
+		return pkgs
+	}
+	direct := map[string]bool{}
+	var out []Package
+	for i, p := range pkgs {
+		if !sourced[i] {
+			for _, r := range p.Requires {
+				direct[r] = true
+			}
+			continue
+		}
+		out = append(out, p)
+	}
+	for i := range out {
+		out[i].Direct = direct[out[i].Name]
+	}
+	return out
+}
+
+// --- npm ---
+
+type npmLock struct {
+	Packages     map[string]npmPackage `json:"packages"`
+	Dependencies map[string]npmDep     `json:"dependencies"` // lockfileVersion 1
+}
+
+type npmPackage struct {
+	Version   string `json:"version"`
+	Link      bool   `json:"link"`
+	Integrity string `json:"integrity"`
+	License   any    `json:"license"` // Строка; у старых пакетов — объект {type, url}
+
+	Dependencies         map[string]string `json:"dependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"` // Только у корня ""
+}
+
+// requires — имена зависимостей записи (у корня "" вместе с devDependencies)
+func (p npmPackage) requires() []string {
+	var names []string
+	for _, m := range []map[string]string{p.Dependencies, p.OptionalDependencies, p.PeerDependencies, p.DevDependencies} {
+		for name := range m {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+type npmDep struct {
+	Version      string            `json:"version"`
+	Integrity    string            `json:"integrity"`
+	Requires     map[string]string `json:"requires"`
+	Dependencies map[string]npmDep `json:"dependencies"`
+}
+
+// parsePackageLock: "packages" (v2/v3) или вложенные "dependencies" (v1).
+// JSON не хранит позиций, поэтому строка ищется по ключу записи.
+func parsePackageLock(data []byte) []Package {
+	var lock npmLock
+	if json.Unmarshal(data, &lock) != nil {
+		return nil
+	}
+	text := string(data)
+	lineOf := func(key string) int {
+		i := strings.Index(text, `"`+key+`": {`)
+		if i < 0 {
+			return 1
+		}
+		return strings.Count(text[:i], "\n") + 1
+	}
+
+	var out []Package
+	seen := map[string]bool{}
+	add := func(p Package) {
+		if p.Name == "" || p.Version == "" || seen[p.Name+"@"+p.Version] {
+			return
+		}
+		seen[p.Name+"@"+p.Version] = true
+		p.Ecosystem = osv.NPM
+		out = append(out, p)
+	}
+
+	if len(lock.Packages) > 0 {
+		direct := map[string]bool{}
+		for _, name := range lock.Packages[""].requires() {
+			direct[name] = true
+		}
+		for key, p := range lock.Packages {
+			i := strings.LastIndex(key, "node_modules/")
+			if i < 0 || p.Link {
+				continue // "" — сам проект, link — workspace
+			}
+			name := key[i+len("node_modules/"):]
+			add(Package{
+				Name: name, Version: p.Version, Line: lineOf(key), Hashes: integrity(p.Integrity), License: license.FromNPM(p.License),
+				Direct: key == "node_modules/"+name && direct[name], Requires: p.requires(),
+			})
+		}
+	} else {
+		var walk func(map[string]npmDep)
+		walk = func(deps map[string]npmDep) {
+			for name, d := range deps {
+				var requires []string
+				for r := range d.Requires {
+					requires = append(requires, r)
+				}
+				sort.Strings(requires)
+				add(Package{Name: name, Version: d.Version, Line: lineOf(name), Hashes: integrity(d.Integrity), Requires: requires})
+				walk(d.Dependencies)
+			}
+		}
+		walk(lock.Dependencies)
+	}
+	sortPackages(out)
+	return out
+}
+
+// integrity — Subresource Integrity ("sha512-<base64> sha1-<base64>") в hex
+func integrity(sri string) []Hash {
+	var out []Hash
+	for _, part := range strings.Fields(sri) {
+		alg, b64, ok := strings.Cut(part, "-")
+		if !ok {
+			continue
+		}
+		raw, err := base64.StdEncoding.DecodeString(b64)
+		if err != nil || hashAlg(alg) == "" {
+			continue
+		}
+		out = append(out, Hash{Alg: hashAlg(alg), Value: hex.EncodeToString(raw)})
+	}
+	return out
+}
+
+func hashAlg(name string) string {
+	switch strings.ToLower(name) {
+	case "sha1":
+		return "SHA-1"
+	case "sha256":
+		return "SHA-256"
+	case "sha512":
+		return "SHA-512"
+	}
+	return ""
+}
+
+// parseYarnLock: yarn v1 (version "1.2.3") и berry (version: 1.2.3)
+func parseYarnLock(data []byte) []Package {
+	var out []Package
+	seen := map[string]bool{}
+	direct := map[string]bool{} // berry: зависимости workspace-записей (version 0.0.0-use.local)
+	name, line := "", 0
+	last := -1 // Индекс пакета текущей записи в out (для integrity и dependencies после version)
+	workspace, inDeps := false, false
+	for i, l := range lines(data) {
+		if l == "" || strings.HasPrefix(l, "#") {
+			continue
+		}
+		if !strings.HasPrefix(l, " ") {
+			// Заголовок: "@babel/core@^7.0.0", "@babel/core@^7.1.0": (берем первую спецификацию)
+			spec := strings.Trim(strings.TrimSpace(strings.SplitN(strings.TrimSuffix(l, ":"), ",", 2)[0]), `"`)
+			name, line, last, workspace, inDeps = "", i+1, -1, false, false
+			if at := strings.LastIndex(spec, "@"); at > 0 {
+				name = spec[:at]
+			}
+			continue
+		}
+		t := strings.TrimSpace(l)
+		// Элементы dependencies: — отступ 4: v1 `debug "^4.1.0"`, berry `debug: "npm:^4.1.0"`
+		if strings.HasPrefix(l, "    ") {
+			if f := strings.Fields(t); inDeps && len(f) > 0 {
+				dep := strings.Trim(f[0], `":`)
+				if workspace {
+					direct[dep] = true
+				} else if last >= 0 {
+					out[last].Requires = append(out[last].Requires, dep)
+				}
+			}
+			continue
+		}
+		inDeps = t == "dependencies:" || t == "optionalDependencies:" || t == "peerDependencies:"
+		// yarn v1: integrity sha512-... (у berry checksum — не SRI, пропускаем)
+		if last >= 0 && strings.HasPrefix(t, "integrity ") {
+			out[last].Hashes = integrity(strings.Trim(strings.TrimPrefix(t, "integrity "), `"`))
+			continue
+		}
+		if name == "" || !strings.HasPrefix(t, "version") {
+			continue
+		}
+		v := strings.Trim(strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(t, "version"), ":")), `"`)
+		workspace = strings.Contains(v, "use.local")
+		if v != "" && !seen[name+"@"+v] && !workspace {
+			seen[name+"@"+v] = true
+			out = append(out, Package{Ecosystem: osv.NPM, Name: name, Version: v, Line: line})
+			last = len(out) - 1
+		}
+		name = ""
+	}
+	for i := range out {
+		out[i].Direct = direct[out[i].Name]
+	}
+	return out
+}
+
+// --- PyPI ---
+
+var pinned = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(\[[^\]]*\])?\s*===?\s*([^\s;#\\,]+)`)
+
+var pipHash = regexp.MustCompile(`--hash[=\s](sha256|sha512):([0-9a-fA-F]+)`)
+
+// parseRequirements: только точно закрепленные версии (name==1.2.3); диапазоны сопоставить нельзя.
+// Хэши --hash=sha256:... могут идти на строках-продолжениях (\ в конце строки).
+// Комментарии pip-compile "# via flask" дают граф; без них все пакеты считаются прямыми.
+func parseRequirements(data []byte) []Package {
+	var out []Package
+	via := map[int][]string{}
+	last, owner := -1, -1 // owner — пакет, к которому относятся комментарии "# via"
+	cont, inVia := false, false
+	for i, l := range lines(data) {
+		t := strings.TrimSpace(l)
+		if m := pinned.FindStringSubmatch(t); m != nil && !cont {
+			out = append(out, Package{Ecosystem: osv.PyPI, Name: m[1], Version: m[3], Line: i + 1})
+			last, owner, inVia = len(out)-1, len(out)-1, false
+		} else if !cont {
+			last = -1
+		}
+		if last >= 0 {
+			for _, h := range pipHash.FindAllStringSubmatch(t, -1) {
+				out[last].Hashes = append(out[last].Hashes, Hash{Alg: hashAlg(h[1]), Value: strings.ToLower(h[2])})
+			}
+		}
+		cont = strings.HasSuffix(t, "\\")
+
+		switch {
+		case owner < 0 || !strings.HasPrefix(t, "#"):
+			inVia = false
+		case strings.HasPrefix(t, "# via"):
+			inVia = true
+			via[owner] = append(via[owner], viaNames(strings.TrimPrefix(t, "# via"))...)
+		case inVia && strings.HasPrefix(t, "#   "):
+			via[owner] = append(via[owner], viaNames(strings.TrimPrefix(t, "#"))...)
+		default:
+			inVia = false
+		}
+	}
+
+	index := map[string]int{}
+	for i, p := range out {
+		index[osv.NormalizeName(osv.PyPI, p.Name)] = i
+	}
+	for i := range out {
+		parents := via[i]
+		out[i].Direct = len(parents) == 0
+		for _, parent := range parents {
+			// "-r requirements.in" / "-c constraints.txt" — пакет указан в исходном файле
+			if strings.HasPrefix(parent, "-") {
+				out[i].Direct = true
+			} else if j, ok := index[osv.NormalizeName(osv.PyPI, parent)]; ok {
+				out[j].Requires = append(out[j].Requires, out[i].Name)
+			}
+		}
+	}
+	return out
+}
+
+// viaNames: "flask, requests" или "-r requirements.in"
+func viaNames(s string) []string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	if strings.HasPrefix(s, "-") {
+		return []string{s}
+	}
+	return strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' })
+}
+
+// sortPackages — порядок по строкам (обход map в package-lock.json случаен)
+func sortPackages(list []Package) {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Line != list[j].Line {
+			return list[i].Line < list[j].Line
+		}
+		return list[i].Name+"@"+list[i].Version < list[j].Name+"@"+list[j].Version
+	})
+}
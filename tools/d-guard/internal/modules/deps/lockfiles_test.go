@@ -0,0 +1,300 @@
+package deps
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// pkg — сокращенная запись пакета для сравнения (без хэшей и лицензии)
+type pkg struct {
+	Name     string
+	Version  string
+	Line     int
+	Direct   bool
+	Requires []string
+}
+
+func brief(pkgs []Package) []pkg {
+	out := []pkg{}
+	for _, p := range pkgs {
+		out = append(out, pkg{p.Name, p.Version, p.Line, p.Direct, p.Requires})
+	}
+	return out
+}
+
+func check(t *testing.T, got []Package, want []pkg) {
+	t.Helper()
+	if b := brief(got); !reflect.DeepEqual(b, want) {
+		t.Errorf("packages:\n got %+v\nwant %+v", b, want)
+	}
+}
+
+func TestParseGoMod(t *testing.T) {
+	const gomod = `module example.com/app
+
+go 1.22
+
+require github.com/spf13/cobra v1.8.0
+
+require (
+	golang.org/x/net v0.17.0 // indirect
+	example.com/forked v1.0.0
+	example.com/local v0.1.0
+	example.com/kept v1.1.0 // комментарий без пометки
+)
+
+replace example.com/forked => github.com/someone/forked v1.0.1
+
+replace (
+	example.com/local => ../local
+	example.com/unused v1.0.0 => example.com/unused v1.0.1
+)
+`
+	check(t, parseGoMod([]byte(gomod)), []pkg{
+		{"github.com/spf13/cobra", "v1.8.0", 5, true, nil},
+		{"golang.org/x/net", "v0.17.0", 8, false, nil},
+		{"github.com/someone/forked", "v1.0.1", 9, true, nil}, // replace на другой модуль: строка require
+		{"example.com/kept", "v1.1.0", 11, true, nil},         // example.com/local заменен путем — не из реестра
+	})
+}
+
+func TestParseGoSum(t *testing.T) {
+	const gosum = `github.com/spf13/cobra v1.8.0 h1:7aJaZx1B85qltLMc546zn58BxxfZdR/W22ej9CFoEf0=
+github.com/spf13/cobra v1.8.0/go.mod h1:WXLWApfZ71AjXPya3WOlMsY9yMs7YeiHhFVlvLyhcho=
+github.com/spf13/cobra v1.8.0 h1:7aJaZx1B85qltLMc546zn58BxxfZdR/W22ej9CFoEf0=
+golang.org/x/net v0.17.0/go.mod h1:NxSsAGuq816PNPmqtQdLE42eU2Fs7NoRIZrHJAlaCOE=
+`
+	got := parseGoSum([]byte(gosum))
+	check(t, got, []pkg{{"github.com/spf13/cobra", "v1.8.0", 1, false, nil}})
+	if want := []Hash{{Alg: "h1", Value: "h1:7aJaZx1B85qltLMc546zn58BxxfZdR/W22ej9CFoEf0="}}; !reflect.DeepEqual(got[0].Hashes, want) {
+		t.Errorf("hashes = %v, want %v", got[0].Hashes, want)
+	}
+}
+
+func TestParseYarnLock(t *testing.T) {
+	t.Run("v1", func(t *testing.T) {
+		// Строка из одних пробелов в dependencies (склеена, чтобы редактор ее не обрезал) не роняет разбор
+		const lock = `# THIS IS AN AUTOGENERATED FILE. DO NOT EDIT THIS FILE DIRECTLY.
+# yarn lockfile v1
+
+
+"@babel/core@^7.0.0", "@babel/core@^7.1.0":
+  version "7.23.2"
+  resolved "https://registry.yarnpkg.com/@babel/core/-/core-7.23.2.tgz"
+  integrity sha1-AAECAwQFBgcICQoLDA0ODxAREhM=
+  dependencies:
+    debug "^4.1.0"
+    ` + "    " + `
+    semver "^6.3.1"
+
+debug@^4.1.0:
+  version "4.3.4"
+
+semver@^6.3.1:
+  version "6.3.1"
+`
+		got := parseYarnLock([]byte(lock))
+		check(t, got, []pkg{
+			{"@babel/core", "7.23.2", 5, false, []string{"debug", "semver"}},
+			{"debug", "4.3.4", 14, false, nil},
+			{"semver", "6.3.1", 17, false, nil},
+		})
+		if want := []Hash{{Alg: "SHA-1", Value: "000102030405060708090a0b0c0d0e0f10111213"}}; !reflect.DeepEqual(got[0].Hashes, want) {
+			t.Errorf("integrity = %v, want %v", got[0].Hashes, want)
+		}
+	})
+
+	t.Run("berry", func(t *testing.T) {
+		const lock = `__metadata:
+  version: 8
+  cacheKey: 10c0
+
+"app@workspace:.":
+  version: 0.0.0-use.local
+  resolution: "app@workspace:."
+  dependencies:
+    lodash: "npm:^4.17.21"
+  languageName: unknown
+  linkType: soft
+
+"lodash@npm:^4.17.21":
+  version: 4.17.21
+  resolution: "lodash@npm:4.17.21"
+  checksum: 10c0/d8cbea072bb08655bb4c989da418994b073a608dffa608b09ac04b43a791b12aeae7cd7ad919aa4c925f33b48490b5cfe6c1f71d827956071dae2e7bb3a6b74c
+  languageName: node
+  linkType: hard
+
+"lodash@npm:4.17.20":
+  version: 4.17.20
+`
+		got := parseYarnLock([]byte(lock))
+		check(t, got, []pkg{
+			{"lodash", "4.17.21", 13, true, nil}, // Прямая: зависимость workspace-записи
+			{"lodash", "4.17.20", 20, true, nil},
+		})
+		if got[0].Hashes != nil {
+			t.Errorf("berry checksum is not SRI: hashes = %v", got[0].Hashes)
+		}
+	})
+}
+
+func TestParsePackageLock(t *testing.T) {
+	t.Run("v3", func(t *testing.T) {
+		const lock = `{
+  "name": "app",
+  "lockfileVersion": 3,
+  "packages": {
+    "": {
+      "name": "app",
+      "dependencies": {"express": "^4.18.0"},
+      "devDependencies": {"jest": "^29.0.0"}
+    },
+    "node_modules/express": {
+      "version": "4.18.2",
+      "license": "MIT",
+      "integrity": "sha1-AAECAwQFBgcICQoLDA0ODxAREhM=",
+      "dependencies": {"debug": "2.6.9"}
+    },
+    "node_modules/debug": {
+      "version": "2.6.9",
+      "license": {"type": "MIT"}
+    },
+    "node_modules/express/node_modules/debug": {
+      "version": "2.6.8"
+    },
+    "node_modules/jest": {
+      "version": "29.7.0",
+      "dev": true
+    },
+    "node_modules/shared": {
+      "resolved": "packages/shared",
+      "link": true
+    }
+  }
+}`
+		got := parsePackageLock([]byte(lock))
+		check(t, got, []pkg{
+			{"express", "4.18.2", 10, true, []string{"debug"}},
+			{"debug", "2.6.9", 16, false, nil},
+			{"debug", "2.6.8", 20, false, nil}, // Вложенная копия не прямая, даже если имя совпадает
+			{"jest", "29.7.0", 23, true, nil},
+		})
+		if got[0].License != "MIT" || got[1].License != "MIT" {
+			t.Errorf("licenses = %q, %q", got[0].License, got[1].License)
+		}
+		if len(got[0].Hashes) != 1 || got[0].Hashes[0].Alg != "SHA-1" {
+			t.Errorf("integrity = %v", got[0].Hashes)
+		}
+	})
+
+	t.Run("v1", func(t *testing.T) {
+		const lock = `{
+  "name": "app",
+  "lockfileVersion": 1,
+  "dependencies": {
+    "express": {
+      "version": "4.17.1",
+      "requires": {"qs": "6.7.0", "debug": "2.6.9"},
+      "dependencies": {
+        "qs": {
+          "version": "6.7.0"
+        }
+      }
+    },
+    "debug": {
+      "version": "2.6.9"
+    }
+  }
+}`
+		check(t, parsePackageLock([]byte(lock)), []pkg{
+			{"express", "4.17.1", 5, false, []string{"debug", "qs"}},
+			{"qs", "6.7.0", 9, false, nil},
+			{"debug", "2.6.9", 14, false, nil},
+		})
+	})
+
+	if got := parsePackageLock([]byte("{broken")); got != nil {
+		t.Errorf("broken JSON: %v", got)
+	}
+}
+
+func TestParseRequirements(t *testing.T) {
+	sha := func(c string) string { return strings.Repeat(c, 64) }
+	lock := `#
+# This file is autogenerated by pip-compile with Python 3.12
+#
+click==8.1.7 \
+    --hash=sha256:` + sha("a") + ` \
+    --hash=sha256:` + sha("B") + `
+    # via flask
+flask==3.0.0 \
+    --hash=sha256:` + sha("c") + `
+    # via -r requirements.in
+Jinja2[i18n]==3.1.2 ; python_version >= "3.8"
+    # via
+    #   flask
+    #   -r requirements.in
+requests>=2.31
+markupsafe===2.1.3  # закреплено вручную
+`
+	got := parseRequirements([]byte(lock))
+	check(t, got, []pkg{
+		{"click", "8.1.7", 4, false, nil},
+		{"flask", "3.0.0", 8, true, []string{"click", "Jinja2"}},
+		{"Jinja2", "3.1.2", 11, true, nil},
+		{"markupsafe", "2.1.3", 16, true, nil}, // Без "# via" — прямая; requests без точной версии пропущен
+	})
+	if want := []Hash{{"SHA-256", sha("a")}, {"SHA-256", sha("b")}}; !reflect.DeepEqual(got[0].Hashes, want) {
+		t.Errorf("click hashes = %v, want %v", got[0].Hashes, want)
+	}
+	if len(got[1].Hashes) != 1 || got[2].Hashes != nil {
+		t.Errorf("hashes must not leak across packages: flask %v, Jinja2 %v", got[1].Hashes, got[2].Hashes)
+	}
+}
+
+func TestParseCargoLock(t *testing.T) {
+	const lock = `version = 3
+
+[[package]]
+name = "app"
+version = "0.1.0"
+dependencies = [
+ "serde",
+ "syn 2.0.39",
+]
+
+[[package]]
+name = "serde"
+version = "1.0.193"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+checksum = "` + "25dd9975e68d0cb5aa1120c288333fc98731bd1dd12f561e468ea4728c042b89" + `"
+
+[[package]]
+name = "syn"
+version = "2.0.39"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+dependencies = ["proc-macro2"]
+`
+	got := parserFor("Cargo.lock")([]byte(lock))
+	check(t, got, []pkg{ // Крейт проекта (без source) убран, его зависимости — прямые
+		{"serde", "1.0.193", 12, true, nil},
+		{"syn", "2.0.39", 18, true, []string{"proc-macro2"}},
+	})
+	if len(got[0].Hashes) != 1 || got[0].Hashes[0].Alg != "SHA-256" {
+		t.Errorf("checksum = %v", got[0].Hashes)
+	}
+}
+
+func TestParserFor(t *testing.T) {
+	for path, ok := range map[string]bool{
+		"go.mod": true, "sub/go.sum": true, "Cargo.lock": true, "poetry.lock": true,
+		"package-lock.json": true, "npm-shrinkwrap.json": true, "yarn.lock": true,
+		"requirements.txt": true, "requirements-dev.txt": true, "Requirements_test.txt": true,
+		"package.json": false, "requirements.in": false, "pnpm-lock.yaml": false,
+	} {
+		if got := parserFor(path) != nil; got != ok {
+			t.Errorf("parserFor(%q) != nil = %v, want %v", path, got, ok)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package deps
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/devos-os/d-guard/internal/core"
+	"github.com/devos-os/d-guard/internal/osv"
+	"github.com/devos-os/d-guard/internal/scanner"
+	"github.com/devos-os/d-guard/internal/source"
+)
+
+func init() { scanner.Register(depsScanner{}) }
+
+// depsScanner — нативный SCA по локальному зеркалу OSV, если Trivy не установлен или отключен
+type depsScanner struct{}
+
+func (depsScanner) Name() string        { return "deps" }
+func (depsScanner) FallbackFor() string { return "trivy" }
+
+// db — каталог зеркала OSV (по умолчанию кэш пользователя, см. 'd-guard db update')
+func (depsScanner) Options() []string { return []string{"db"} }
+
+func (depsScanner) Applicable(t scanner.Target) bool   { return len(Lockfiles(t.Source(), t.Files)) > 0 }
+func (depsScanner) SkipReason(t scanner.Target) string { return "no dependency lockfiles to scan" }
+
+// staleAfter — после этого срока зеркало считается устаревшим (предупреждение, не ошибка)
+const staleAfter = 7 * 24 * time.Hour
+
+func (depsScanner) Scan(ctx context.Context, t scanner.Target) ([]core.Issue, error) {
+	dir := t.Option("db", osv.DefaultDir())
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(t.Root, dir)
+	}
+	db, err := osv.Open(dir)
+	if err != nil {
+		return nil, scanner.Skip("%v", err)
+	}
+	if age := time.Since(db.Oldest()); age > staleAfter {
+		fmt.Printf("  ⚠️  OSV database is %d days old, run 'd-guard db update'\n", int(age.Hours()/24))
+	}
+	return Scan(ctx, db, t.Source(), t.Files)
+}
+
+// Scan сопоставляет версии из lockfile с записями OSV
+func Scan(ctx context.Context, db *osv.DB, fsys source.FS, files []string) ([]core.Issue, error) {
+	var issues []core.Issue
+	missing := map[string]bool{}
+
+	for _, path := range Lockfiles(fsys, files) {
+		pkgs, err := Parse(fsys, path)
+		if err != nil {
+			continue
+		}
+		for _, p := range pkgs {
+			if !db.Has(p.Ecosystem) {
+				missing[p.Ecosystem] = true
+				continue
+			}
+			matches, err := db.Query(p.Ecosystem, p.Name, p.Version)
+			if err != nil {
+				return issues, err
+			}
+			for _, m := range matches {
+				issues = append(issues, issue(path, p, m))
+			}
+		}
+		if ctx.Err() != nil {
+			return issues, ctx.Err()
+		}
+	}
+
+	if len(missing) > 0 {
+		var ecos []string
+		for e := range missing {
+			ecos = append(ecos, e)
+		}
+		sort.Strings(ecos)
+		fmt.Printf("  ⚠️  OSV database has no data for %s, run 'd-guard db update'\n", strings.Join(ecos, ", "))
+	}
+	return issues, nil
+}
+
+func issue(path string, p Package, m osv.Match) core.Issue {
+	v := m.Vuln
+	suggestion := "No fixed version is available yet: consider an alternative package or mitigation"
+	if m.Fixed != "" {
+		suggestion = fmt.Sprintf("Update %s to %s or later", p.Name, m.Fixed)
+	}
+	summary := v.Summary
+	if summary == "" {
+		summary = firstLine(v.Details)
+	}
+	return core.Issue{
+		Scanner:     "SCA (OSV)",
+		RuleID:      v.CVE(),
+		Severity:    v.Level(),
+		Message:     fmt.Sprintf("%s: %s@%s %s", v.Describe(), p.Name, p.Version, summary),
+		File:        path,
+		Line:        p.Line,
+		Description: fmt.Sprintf("Affected versions: %s. %s", m.Affected, v.Details),
+		Suggestion:  suggestion,
+	}
+}
+
+// Lockfiles — поддерживаемые файлы. go.sum заменяется go.mod из того же каталога
+// (в go.sum есть и версии, не попавшие в сборку).
+func Lockfiles(fsys source.FS, files []string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, f := range files {
+		if parserFor(f) == nil {
+			continue
+		}
+		if filepath.Base(f) == "go.sum" {
+			mod := filepath.Join(filepath.Dir(f), "go.mod")
+			if _, err := fsys.ReadFile(mod); err == nil {
+				f = mod
+			}
+		}
+		if !seen[f] {
+			seen[f] = true
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func firstLine(s string) string {
+	s, _, _ = strings.Cut(strings.TrimSpace(s), "\n")
+	return s
+}
@@ -1,13 +1,47 @@
 package external
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os/exec"
+	"strings"
 
 	"github.com/devos-os/d-guard/internal/core"
+	"github.com/devos-os/d-guard/internal/proc"
+	"github.com/devos-os/d-guard/internal/scanner"
+	"github.com/devos-os/d-guard/internal/tools"
 )
 
+func init() { scanner.Register(trivyScanner{}) }
+
+type trivyScanner struct{}
+
+func (trivyScanner) Name() string { return "trivy" }
+func (trivyScanner) External()    {}
+
+// scanners — значение --scanners Trivy (по умолчанию "vuln,config")
+func (trivyScanner) Options() []string { return []string{"scanners"} }
+
+func (trivyScanner) Applicable(t scanner.Target) bool {
+	_, ok := tools.Lookup("trivy")
+	return ok
+}
+
+func (trivyScanner) SkipReason(t scanner.Target) string {
+	return "trivy is not installed (install via 'd-guard tools install trivy' or 'dnf install trivy'), native deps/iac scanners are used instead"
+}
+
+// Вывод "trivy --version": "Version: 0.48.1"
+func (trivyScanner) Version(ctx context.Context) string {
+	return tools.ToolVersion(ctx, "trivy")
+}
+
+// Trivy лучше работает по всей папке, поэтому список файлов не используется
+func (trivyScanner) Scan(ctx context.Context, t scanner.Target) ([]core.Issue, error) {
+	return RunTrivyFs(ctx, t.Root, t.Option("scanners", "vuln,config"), t.Offline)
+}
+
 // Структуры для парсинга JSON вывода Trivy
 type TrivyReport struct {
 	Results []struct {
@@ -26,34 +60,43 @@ type TrivyReport struct {
 			Severity    string `json:"Severity"`
 			Description string `json:"Description"`
 			Message     string `json:"Message"`
+			Cause       struct {
+				StartLine int `json:"StartLine"`
+			} `json:"CauseMetadata"`
 		} `json:"Misconfigurations"`
 	} `json:"Results"`
 }
 
-func RunTrivyFs(root string) []core.Issue {
+func RunTrivyFs(ctx context.Context, root string, scanners string, offline bool) ([]core.Issue, error) {
 	var issues []core.Issue
 
 	// Проверяем наличие trivy в системе
-	if _, err := exec.LookPath("trivy"); err != nil {
-		fmt.Println("⚠️  Trivy not found (install via 'dnf install trivy' or curl). Skipping SCA.")
-		return nil
+	bin, ok := tools.Lookup("trivy")
+	if !ok {
+		return nil, scanner.Skip("trivy not found (install via 'd-guard tools install trivy' or 'dnf install trivy')")
 	}
 
 	fmt.Println("[Orchestrator] Executing Trivy (External Security Scanner)...")
 
-	// Запускаем: trivy fs . --format json --scanners vuln,config
-	cmd := exec.Command("trivy", "fs", ".", "--format", "json", "--scanners", "vuln,config")
+	// Запускаем: trivy fs . --format json --scanners vuln,config (или из scanners.trivy.scanners)
+	args := []string{"fs", ".", "--format", "json", "--scanners", scanners}
+	if offline {
+		// --offline: только закэшированная БД, без запросов к реестрам
+		args = append(args, "--skip-db-update", "--offline-scan")
+	}
+	cmd := proc.Command(ctx, bin, args...)
 	cmd.Dir = root
 	out, err := cmd.Output()
 	if err != nil {
-		fmt.Printf("⚠️  Trivy execution failed: %v\n", err)
-		return nil
+		if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) > 0 {
+			return nil, fmt.Errorf("trivy execution failed: %v: %s", err, lastLine(ee.Stderr))
+		}
+		return nil, fmt.Errorf("trivy execution failed: %w", err)
 	}
 
 	var report TrivyReport
 	if err := json.Unmarshal(out, &report); err != nil {
-		fmt.Println("⚠️  Failed to parse Trivy output")
-		return nil
+		return nil, fmt.Errorf("failed to parse trivy output: %w", err)
 	}
 
 	// Конвертируем результаты Trivy в формат d-guard
@@ -62,10 +105,11 @@ func RunTrivyFs(root string) []core.Issue {
 		for _, vuln := range res.Vulnerabilities {
 			issues = append(issues, core.Issue{
 				Scanner:     "Trivy (Vuln)",
+				RuleID:      vuln.VulnerabilityID,
 				Severity:    mapSeverity(vuln.Severity),
 				Message:     fmt.Sprintf("%s: %s (%s)", vuln.VulnerabilityID, vuln.PkgName, vuln.InstalledVersion),
 				File:        res.Target,
-				Line:        1, // Trivy часто не дает строку для зависимостей
+				Line:        0, // Уровень файла: Trivy не дает строку зависимости в lockfile
 				Description: vuln.Description,
 				Suggestion:  fmt.Sprintf("Update to version %s", vuln.FixedVersion),
 			})
@@ -74,17 +118,23 @@ func RunTrivyFs(root string) []core.Issue {
 		for _, mis := range res.Misconfigurations {
 			issues = append(issues, core.Issue{
 				Scanner:     "Trivy (IaC)",
+				RuleID:      mis.ID,
 				Severity:    mapSeverity(mis.Severity),
 				Message:     mis.Title,
 				File:        res.Target,
-				Line:        1,
+				Line:        mis.Cause.StartLine, // 0 — проверка всего файла (e.g. нет USER)
 				Description: mis.Description,
 				Suggestion:  mis.Message,
 			})
 		}
 	}
 
-	return issues
+	return issues, nil
+}
+
+func lastLine(b []byte) string {
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
 }
 
 func mapSeverity(s string) core.Severity {
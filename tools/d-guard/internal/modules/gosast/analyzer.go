@@ -0,0 +1,199 @@
+package gosast
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/devos-os/d-guard/internal/core"
+)
+
+// file — разобранный файл и контекст правил
+type file struct {
+	path    string
+	fset    *token.FileSet
+	ast     *ast.File
+	info    *types.Info
+	imports map[string]string // Локальное имя → путь импорта
+	timeout bool              // В файле где-то задается ReadTimeout/ReadHeaderTimeout
+	issues  []core.Issue
+}
+
+// ScanFile разбирает один файл Go и применяет правила (вызывается из пула scanner.EachFile).
+// Синтаксически неверный файл пропускается: это забота компилятора, а не SAST.
+func ScanFile(path string, data []byte) []core.Issue {
+	if generated(data) {
+		return nil
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, data, parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+
+	c := &file{path: path, fset: fset, ast: f, imports: map[string]string{}}
+	for _, imp := range f.Imports {
+		p, _ := strconv.Unquote(imp.Path.Value)
+		name := importName(p)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		c.imports[name] = p
+	}
+	c.check()
+	ast.Inspect(f, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok && serverTimeouts[sel.Sel.Name] {
+			c.timeout = true
+		}
+		return !c.timeout
+	})
+
+	c.inspect()
+	return c.issues
+}
+
+// check — go/types только ради констант: импорты подменяются пустыми пакетами,
+// ошибки (неизвестные функции, типы из других файлов) игнорируются
+func (c *file) check() {
+	c.info = &types.Info{Types: map[ast.Expr]types.TypeAndValue{}}
+	conf := types.Config{
+		Importer: emptyImporter{},
+		Error:    func(error) {},
+	}
+	conf.Check(c.ast.Name.Name, c.fset, []*ast.File{c.ast}, c.info)
+}
+
+type emptyImporter struct{}
+
+func (emptyImporter) Import(p string) (*types.Package, error) {
+	pkg := types.NewPackage(p, importName(p))
+	pkg.MarkComplete()
+	return pkg, nil
+}
+
+// importName — имя пакета по пути: "math/rand/v2" → rand, "gopkg.in/yaml.v3" → yaml
+func importName(p string) string {
+	name := path.Base(p)
+	if strings.HasPrefix(name, "v") && p != name {
+		if _, err := strconv.Atoi(name[1:]); err == nil {
+			name = path.Base(path.Dir(p))
+		}
+	}
+	if i := strings.Index(name, ".v"); i > 0 {
+		name = name[:i]
+	}
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// pkgFunc — вызов функции импортированного пакета: (путь импорта, имя функции)
+func (c *file) pkgFunc(call *ast.CallExpr) (string, string) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", ""
+	}
+	return c.pkgSelector(sel)
+}
+
+func (c *file) pkgSelector(sel *ast.SelectorExpr) (string, string) {
+	id, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return "", ""
+	}
+	p, ok := c.imports[id.Name]
+	if !ok {
+		return "", ""
+	}
+	return p, sel.Sel.Name
+}
+
+// constant — значение известно при компиляции (литерал, константа, их конкатенация)
+func (c *file) constant(e ast.Expr) bool {
+	if tv, ok := c.info.Types[e]; ok && tv.Value != nil {
+		return true
+	}
+	_, ok := e.(*ast.BasicLit)
+	return ok
+}
+
+// stringValue — значение строковой константы ("" — неизвестно)
+func (c *file) stringValue(e ast.Expr) string {
+	if tv, ok := c.info.Types[e]; ok && tv.Value != nil && tv.Value.Kind() == constant.String {
+		return constant.StringVal(tv.Value)
+	}
+	if lit, ok := e.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+		s, _ := strconv.Unquote(lit.Value)
+		return s
+	}
+	return ""
+}
+
+// literals — строковые константы внутри выражения (части конкатенации, формат Sprintf)
+func (c *file) literals(e ast.Expr) string {
+	var b strings.Builder
+	ast.Inspect(e, func(n ast.Node) bool {
+		if x, ok := n.(ast.Expr); ok && c.constant(x) {
+			b.WriteString(c.stringValue(x))
+			b.WriteByte(' ')
+			return false
+		}
+		return true
+	})
+	return b.String()
+}
+
+func (c *file) report(n ast.Node, sev core.Severity, rule, msg, suggestion string) {
+	c.issues = append(c.issues, core.Issue{
+		Scanner: "Go SAST", Severity: sev, File: c.path, Line: c.fset.Position(n.Pos()).Line, RuleID: rule,
+		Message: msg, Suggestion: suggestion,
+	})
+}
+
+// inspect обходит файл, запоминая имя объемлющей функции или переменной (для math/rand)
+func (c *file) inspect() {
+	for _, decl := range c.ast.Decls {
+		scope := ""
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			scope = d.Name.Name
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				if vs, ok := spec.(*ast.ValueSpec); ok {
+					for _, n := range vs.Names {
+						scope += n.Name + " "
+					}
+				}
+			}
+		}
+		c.walk(decl, scope)
+	}
+}
+
+func (c *file) walk(root ast.Node, scope string) {
+	ast.Inspect(root, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			c.checkAssign(n, scope)
+		case *ast.ExprStmt:
+			if call, ok := n.X.(*ast.CallExpr); ok {
+				c.checkDiscarded(call, n)
+			}
+		case *ast.CallExpr:
+			c.checkCall(n, scope)
+		case *ast.CompositeLit:
+			c.checkLiteral(n)
+		}
+		return true
+	})
+}
+
+// sqlKeyword — в константных частях запроса есть SQL (отсекает Exec/Query не-БД API)
+var sqlKeyword = regexp.MustCompile(`(?i)\b(select|insert|update|delete|where|from|into|values|order\s+by|drop|create|alter)\b`)
+
+// tokenName — переменные и функции, где нужна криптостойкая случайность
+var tokenName = regexp.MustCompile(`(?i)(token|secret|passw|key|nonce|salt|session|otp|csrf|reset|invite)`)
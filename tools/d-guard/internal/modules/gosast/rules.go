@@ -0,0 +1,242 @@
+package gosast
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"path"
+	"strings"
+
+	"github.com/devos-os/d-guard/internal/core"
+)
+
+// sqlMethods — методы database/sql и sqlx → индекс аргумента с запросом
+var sqlMethods = map[string]int{
+	"Query": 0, "QueryRow": 0, "Exec": 0, "Prepare": 0,
+	"QueryContext": 1, "QueryRowContext": 1, "ExecContext": 1, "PrepareContext": 1,
+	"Get": 1, "Select": 1, "Queryx": 0, "QueryRowx": 0, "MustExec": 0,
+	"GetContext": 2, "SelectContext": 2, "QueryxContext": 1, "QueryRowxContext": 1,
+}
+
+// shells — интерпретаторы, которым скрипт передается через -c
+var shells = map[string]bool{
+	"sh": true, "bash": true, "zsh": true, "dash": true, "ksh": true,
+	"cmd": true, "powershell": true, "pwsh": true,
+}
+
+// weakCrypto — пакет → функции, создающие слабый хэш или шифр
+var weakCrypto = map[string]map[string]string{
+	"crypto/md5": {"New": "MD5", "Sum": "MD5"},
+	"crypto/des": {"NewCipher": "DES", "NewTripleDESCipher": "3DES"},
+	"crypto/rc4": {"NewCipher": "RC4"},
+}
+
+// randValues — функции math/rand, возвращающие случайные значения
+var randValues = map[string]bool{
+	"Int": true, "Intn": true, "IntN": true, "Int31": true, "Int31n": true, "Int32": true, "Int32N": true,
+	"Int63": true, "Int63n": true, "Int64": true, "Int64N": true, "Uint32": true, "Uint64": true,
+	"Uint": true, "UintN": true, "N": true, "Read": true, "Perm": true, "Shuffle": true,
+}
+
+// sensitive — вызовы, ошибку которых нельзя игнорировать: без нее программа
+// продолжит работу с пустым ключом, непроверенным паролем или прежними правами
+var sensitive = map[string]map[string]bool{
+	"crypto/rand":                {"Read": true, "Int": true, "Prime": true},
+	"golang.org/x/crypto/bcrypt": {"CompareHashAndPassword": true, "GenerateFromPassword": true},
+	"crypto/tls":                 {"LoadX509KeyPair": true, "X509KeyPair": true},
+	"crypto/x509":                {"ParseCertificate": true, "ParseCertificates": true, "ParsePKCS1PrivateKey": true, "ParsePKCS8PrivateKey": true, "ParseECPrivateKey": true, "ParsePKIXPublicKey": true},
+	"syscall":                    {"Setuid": true, "Setgid": true, "Setgroups": true, "Chroot": true},
+	"golang.org/x/sys/unix":      {"Setuid": true, "Setgid": true, "Setgroups": true, "Chroot": true},
+	"os":                         {"Chmod": true, "Chown": true},
+}
+
+// serverTimeouts — поля http.Server, без которых соединение можно держать бесконечно (Slowloris)
+var serverTimeouts = map[string]bool{"ReadTimeout": true, "ReadHeaderTimeout": true}
+
+func (c *file) checkCall(call *ast.CallExpr, scope string) {
+	pkg, fn := c.pkgFunc(call)
+	switch {
+	case pkg == "os/exec" && (fn == "Command" || fn == "CommandContext"):
+		c.checkExec(call, fn == "CommandContext")
+
+	case weakCrypto[pkg][fn] != "":
+		c.report(call, core.SevMedium, "go-weak-crypto", fmt.Sprintf("Weak cryptographic primitive %s (%s.%s)", weakCrypto[pkg][fn], path.Base(pkg), fn),
+			"Use SHA-256 (crypto/sha256) for hashing and AES-GCM (crypto/aes + crypto/cipher) for encryption")
+
+	case (pkg == "math/rand" || pkg == "math/rand/v2") && randValues[fn] && tokenName.MatchString(scope):
+		c.reportRand(call, scope)
+
+	case pkg == "net/http" && (fn == "ListenAndServe" || fn == "ListenAndServeTLS"):
+		c.report(call, core.SevMedium, "go-http-no-timeouts", fmt.Sprintf("http.%s starts a server without timeouts", fn),
+			"Use &http.Server{Addr: ..., ReadHeaderTimeout: 5 * time.Second, ...} and call its ListenAndServe")
+
+	case pkg == "":
+		c.checkSQL(call)
+	}
+}
+
+// checkSQL — запрос собран конкатенацией или fmt.Sprintf из непостоянных частей
+func (c *file) checkSQL(call *ast.CallExpr) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return
+	}
+	i, ok := sqlMethods[sel.Sel.Name]
+	if !ok || len(call.Args) <= i {
+		return
+	}
+	arg := call.Args[i]
+	if c.constant(arg) || !c.built(arg) || !sqlKeyword.MatchString(c.literals(arg)) {
+		return
+	}
+	c.report(arg, core.SevHigh, "go-sql-concat", fmt.Sprintf("SQL query built from strings is passed to %s", sel.Sel.Name),
+		"Use placeholders ($1 / ?) and pass values as query arguments")
+}
+
+// built — конкатенация строк или fmt.Sprintf
+func (c *file) built(e ast.Expr) bool {
+	switch e := ast.Unparen(e).(type) {
+	case *ast.BinaryExpr:
+		return e.Op == token.ADD
+	case *ast.CallExpr:
+		pkg, fn := c.pkgFunc(e)
+		return pkg == "fmt" && fn == "Sprintf"
+	}
+	return false
+}
+
+// checkExec — exec.Command("sh", "-c", <непостоянная строка>)
+func (c *file) checkExec(call *ast.CallExpr, withContext bool) {
+	args := call.Args
+	if withContext && len(args) > 0 {
+		args = args[1:]
+	}
+	if len(args) < 3 {
+		return
+	}
+	shell := strings.TrimSuffix(strings.ToLower(path.Base(strings.ReplaceAll(c.stringValue(args[0]), `\`, "/"))), ".exe")
+	if !shells[shell] {
+		return
+	}
+	for i, a := range args[1 : len(args)-1] {
+		if flag := strings.ToLower(c.stringValue(a)); flag != "-c" && flag != "/c" && flag != "-command" {
+			continue
+		}
+		for _, script := range args[i+2:] {
+			if !c.constant(script) {
+				c.report(call, core.SevHigh, "go-exec-shell", fmt.Sprintf("Command is run through %s -c with a non-constant script", shell),
+					"Call the program directly (exec.Command(name, args...)) and pass user input as separate arguments")
+				return
+			}
+		}
+	}
+}
+
+func (c *file) reportRand(call *ast.CallExpr, name string) {
+	c.report(call, core.SevMedium, "go-math-rand-token", fmt.Sprintf("math/rand is used for %s", strings.TrimSpace(name)),
+		"math/rand is predictable; use crypto/rand (rand.Read or rand.Text) for tokens, keys and secrets")
+}
+
+// checkAssign — InsecureSkipVerify = true, ошибка в "_", math/rand в переменную-токен
+func (c *file) checkAssign(n *ast.AssignStmt, scope string) {
+	for i, lhs := range n.Lhs {
+		if sel, ok := lhs.(*ast.SelectorExpr); ok && sel.Sel.Name == "InsecureSkipVerify" && len(n.Rhs) == len(n.Lhs) && c.isTrue(n.Rhs[i]) {
+			c.reportSkipVerify(n)
+		}
+	}
+
+	if len(n.Rhs) != 1 {
+		return
+	}
+	if call, ok := ast.Unparen(n.Rhs[0]).(*ast.CallExpr); ok {
+		if blank(n.Lhs[len(n.Lhs)-1]) {
+			c.checkDiscarded(call, n)
+		}
+	}
+
+	// Имя функции уже подходит — вызовы найдет checkCall
+	if tokenName.MatchString(scope) {
+		return
+	}
+	for _, lhs := range n.Lhs {
+		id, ok := lhs.(*ast.Ident)
+		if !ok || !tokenName.MatchString(id.Name) {
+			continue
+		}
+		ast.Inspect(n.Rhs[0], func(x ast.Node) bool {
+			if call, ok := x.(*ast.CallExpr); ok {
+				if pkg, fn := c.pkgFunc(call); (pkg == "math/rand" || pkg == "math/rand/v2") && randValues[fn] {
+					c.reportRand(call, id.Name)
+				}
+			}
+			return true
+		})
+		return
+	}
+}
+
+// checkDiscarded — ошибка вызова из sensitive не проверяется (вызов-инструкция или "_")
+func (c *file) checkDiscarded(call *ast.CallExpr, at ast.Node) {
+	pkg, fn := c.pkgFunc(call)
+	if !sensitive[pkg][fn] {
+		return
+	}
+	c.report(at, core.SevMedium, "go-unchecked-security-error", fmt.Sprintf("Error from %s.%s is ignored", path.Base(pkg), fn),
+		"Check the returned error: on failure the result (key, hash, certificate, privileges) must not be used")
+}
+
+// checkLiteral — tls.Config{InsecureSkipVerify: true}, http.Server{} без таймаутов
+func (c *file) checkLiteral(lit *ast.CompositeLit) {
+	sel, ok := lit.Type.(*ast.SelectorExpr)
+	if !ok {
+		return
+	}
+	switch pkg, name := c.pkgSelector(sel); {
+	case pkg == "crypto/tls" && name == "Config":
+		if v := field(lit, "InsecureSkipVerify"); v != nil && c.isTrue(v) {
+			c.reportSkipVerify(v)
+		}
+	case pkg == "net/http" && name == "Server":
+		for key := range serverTimeouts {
+			if field(lit, key) != nil {
+				return
+			}
+		}
+		if !c.timeout {
+			c.report(lit, core.SevMedium, "go-http-no-timeouts", "http.Server has no ReadHeaderTimeout or ReadTimeout",
+				"Set ReadHeaderTimeout (and ReadTimeout/WriteTimeout/IdleTimeout) to protect against slow clients (Slowloris)")
+		}
+	}
+}
+
+func (c *file) reportSkipVerify(n ast.Node) {
+	c.report(n, core.SevHigh, "go-tls-insecure-skip-verify", "TLS certificate verification is disabled (InsecureSkipVerify: true)",
+		"Remove InsecureSkipVerify; for private CAs add the CA certificate to tls.Config.RootCAs")
+}
+
+// isTrue — литерал true или константа со значением true
+func (c *file) isTrue(e ast.Expr) bool {
+	if tv, ok := c.info.Types[e]; ok && tv.Value != nil && tv.Value.Kind() == constant.Bool {
+		return constant.BoolVal(tv.Value)
+	}
+	id, ok := ast.Unparen(e).(*ast.Ident)
+	return ok && id.Name == "true"
+}
+
+// field — значение поля name в литерале структуры
+func field(lit *ast.CompositeLit, name string) ast.Expr {
+	for _, el := range lit.Elts {
+		if kv, ok := el.(*ast.KeyValueExpr); ok {
+			if id, ok := kv.Key.(*ast.Ident); ok && id.Name == name {
+				return kv.Value
+			}
+		}
+	}
+	return nil
+}
+
+func blank(e ast.Expr) bool {
+	id, ok := e.(*ast.Ident)
+	return ok && id.Name == "_"
+}
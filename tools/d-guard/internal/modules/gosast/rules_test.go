@@ -0,0 +1,249 @@
+package gosast
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ruleAt — правило и строка находки
+type ruleAt struct {
+	Rule string
+	Line int
+}
+
+func scan(src string) []ruleAt {
+	out := []ruleAt{}
+	for _, is := range ScanFile("app/main.go", []byte(src)) {
+		out = append(out, ruleAt{is.RuleID, is.Line})
+	}
+	return out
+}
+
+// fixture — файл из импортов и тела; строки в ожиданиях считаются от начала файла
+func fixture(imports []string, body string) string {
+	var b strings.Builder
+	b.WriteString("package app\n\nimport (\n")
+	for _, imp := range imports {
+		b.WriteString("\t" + imp + "\n")
+	}
+	b.WriteString(")\n\n")
+	b.WriteString(body)
+	return b.String()
+}
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		imports []string
+		body    string
+		want    []ruleAt
+	}{
+		// --- go-sql-concat ---
+		{"sql concat", []string{`"database/sql"`}, `
+func find(db *sql.DB, id string) {
+	db.Query("SELECT * FROM users WHERE id = " + id)
+}`, []ruleAt{{"go-sql-concat", 9}}},
+		{"sql sprintf with context", []string{`"context"`, `"database/sql"`, `"fmt"`}, `
+func drop(ctx context.Context, db *sql.DB, table string) {
+	db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s", table))
+}`, []ruleAt{{"go-sql-concat", 11}}},
+		{"sql constant query", []string{`"database/sql"`}, `
+const base = "SELECT name FROM users"
+
+func list(db *sql.DB, id int) {
+	db.Query("SELECT 1")
+	db.Query(base + " WHERE id = $1", id)
+	db.QueryRow(base+" ORDER BY name")
+}`, nil},
+		{"sql query variable with placeholders", []string{`"database/sql"`}, `
+func find(db *sql.DB, query string, id int) {
+	db.Exec(query, id)
+}`, nil},
+		{"not sql", []string{`"strings"`}, `
+func key(cache interface{ Get(ctx, key string) string }, user string) {
+	cache.Get("", "profile:" + strings.ToLower(user))
+}`, nil},
+
+		// --- go-exec-shell ---
+		{"shell with variable", []string{`"os/exec"`}, `
+func run(name string) {
+	exec.Command("sh", "-c", "echo "+name).Run()
+}`, []ruleAt{{"go-exec-shell", 9}}},
+		{"shell with context and path", []string{`"context"`, `"os/exec"`}, `
+func run(ctx context.Context, script string) {
+	exec.CommandContext(ctx, "/bin/bash", "-c", script).Run()
+}`, []ruleAt{{"go-exec-shell", 10}}},
+		{"windows cmd", []string{`"os/exec"`}, `
+func run(arg string) {
+	exec.Command(` + "`C:\\Windows\\System32\\cmd.exe`" + `, "/c", "dir "+arg).Run()
+}`, []ruleAt{{"go-exec-shell", 9}}},
+		{"shell with constant script", []string{`"os/exec"`}, `
+const cleanup = "rm -rf /tmp/build"
+
+func run() {
+	exec.Command("sh", "-c", "const").Run()
+	exec.Command("sh", "-c", cleanup+" && sync").Run()
+}`, nil},
+		{"program called directly", []string{`"os/exec"`}, `
+func run(file string) {
+	exec.Command("ls", "-c", file).Run()
+	exec.Command("git", "log", file).Run()
+}`, nil},
+
+		// --- go-tls-insecure-skip-verify ---
+		{"skip verify literal", []string{`"crypto/tls"`}, `
+var cfg = &tls.Config{InsecureSkipVerify: true}`, []ruleAt{{"go-tls-insecure-skip-verify", 8}}},
+		{"skip verify assignment and constant", []string{`"crypto/tls"`}, `
+const insecure = true
+
+func client(cfg *tls.Config) {
+	cfg.InsecureSkipVerify = true
+	_ = tls.Config{InsecureSkipVerify: insecure}
+}`, []ruleAt{{"go-tls-insecure-skip-verify", 11}, {"go-tls-insecure-skip-verify", 12}}},
+		{"skip verify false or configurable", []string{`"crypto/tls"`}, `
+func client(debug bool) *tls.Config {
+	_ = &tls.Config{InsecureSkipVerify: false}
+	return &tls.Config{InsecureSkipVerify: debug}
+}`, nil},
+
+		// --- go-weak-crypto ---
+		{"md5 des rc4", []string{`"crypto/des"`, `"crypto/md5"`, `"crypto/rc4"`}, `
+func weak(key, data []byte) {
+	md5.Sum(data)
+	_ = md5.New()
+	des.NewTripleDESCipher(key)
+	rc4.NewCipher(key)
+}`, []ruleAt{{"go-weak-crypto", 11}, {"go-weak-crypto", 12}, {"go-weak-crypto", 13}, {"go-weak-crypto", 14}}},
+		{"renamed weak import", []string{`legacy "crypto/md5"`}, `
+func sum(data []byte) { legacy.Sum(data) }`, []ruleAt{{"go-weak-crypto", 8}}},
+		{"strong hash", []string{`"crypto/sha256"`}, `
+func sum(data []byte) { sha256.Sum256(data) }`, nil},
+
+		// --- go-math-rand-token ---
+		{"math/rand in token function", []string{`"math/rand"`}, `
+func newSessionID() int64 { return rand.Int63() }`, []ruleAt{{"go-math-rand-token", 8}}},
+		{"math/rand into token variable", []string{`"math/rand"`, `"strconv"`}, `
+func signup() string {
+	resetToken := strconv.Itoa(rand.Intn(1000000))
+	return resetToken
+}`, []ruleAt{{"go-math-rand-token", 10}}},
+		{"math/rand/v2 in token function", []string{`"math/rand/v2"`}, `
+func generateAPIKey() uint64 { return rand.Uint64() }`, []ruleAt{{"go-math-rand-token", 8}}},
+		{"renamed math/rand/v2", []string{`mrand "math/rand/v2"`}, `
+var csrfSecret = mrand.N(1 << 62)`, []ruleAt{{"go-math-rand-token", 8}}},
+		{"math/rand/v2 for jitter", []string{`"math/rand/v2"`, `"time"`}, `
+func backoff(attempt int) time.Duration {
+	return time.Duration(attempt)*time.Second + time.Duration(rand.IntN(1000))*time.Millisecond
+}`, nil},
+		{"crypto/rand named rand", []string{`"crypto/rand"`}, `
+func newToken() []byte {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return b
+}`, nil},
+		{"renamed crypto/rand next to math/rand", []string{`crand "crypto/rand"`, `"math/rand"`}, `
+func newToken(b []byte) error {
+	rand.Shuffle(len(b), func(i, j int) {})
+	_, err := crand.Read(b)
+	return err
+}`, []ruleAt{{"go-math-rand-token", 10}}},
+
+		// --- go-unchecked-security-error ---
+		{"discarded errors", []string{`"crypto/rand"`, `"golang.org/x/crypto/bcrypt"`, `"syscall"`}, `
+func setup(hash, password, key []byte) {
+	rand.Read(key)
+	_ = bcrypt.CompareHashAndPassword(hash, password)
+	syscall.Setuid(1000)
+}`, []ruleAt{{"go-unchecked-security-error", 11}, {"go-unchecked-security-error", 12}, {"go-unchecked-security-error", 13}}},
+		{"discarded x509 error", []string{`"crypto/x509"`}, `
+func parse(der []byte) *x509.Certificate {
+	cert, _ := x509.ParseCertificate(der)
+	return cert
+}`, []ruleAt{{"go-unchecked-security-error", 9}}},
+		{"checked errors", []string{`"crypto/rand"`, `"os"`}, `
+func setup(key []byte) error {
+	if _, err := rand.Read(key); err != nil {
+		return err
+	}
+	return os.Chmod("key.pem", 0o600)
+}`, nil},
+
+		// --- go-http-no-timeouts ---
+		{"ListenAndServe", []string{`"net/http"`}, `
+func main() { http.ListenAndServe(":8080", nil) }`, []ruleAt{{"go-http-no-timeouts", 8}}},
+		{"server without timeouts", []string{`"net/http"`}, `
+func main() {
+	srv := &http.Server{Addr: ":8080"}
+	srv.ListenAndServe()
+}`, []ruleAt{{"go-http-no-timeouts", 9}}},
+		{"server with ReadHeaderTimeout", []string{`"net/http"`, `"time"`}, `
+func main() {
+	srv := http.Server{Addr: ":8080", ReadHeaderTimeout: 5 * time.Second}
+	srv.ListenAndServe()
+}`, nil},
+		{"timeout set after literal", []string{`"net/http"`, `"time"`}, `
+func main() {
+	srv := &http.Server{Addr: ":8080"}
+	srv.ReadTimeout = 10 * time.Second
+	srv.ListenAndServe()
+}`, nil},
+		{"renamed net/http", []string{`stdhttp "net/http"`}, `
+func main() { stdhttp.ListenAndServeTLS(":443", "cert.pem", "key.pem", nil) }`, []ruleAt{{"go-http-no-timeouts", 8}}},
+		{"other package named http", []string{`"example.com/router/http"`}, `
+func main() { _ = http.Server{}; http.ListenAndServe(":8080") }`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := fixture(tt.imports, tt.body)
+			want := tt.want
+			if want == nil {
+				want = []ruleAt{}
+			}
+			if got := scan(src); !reflect.DeepEqual(got, want) {
+				t.Errorf("findings = %v, want %v\n%s", got, want, numbered(src))
+			}
+		})
+	}
+}
+
+func TestScanFileSkips(t *testing.T) {
+	body := "\nfunc main() { http.ListenAndServe(\":8080\", nil) }\n"
+	generated := "// Code generated by tool. DO NOT EDIT.\n\n" + fixture([]string{`"net/http"`}, body)
+	if got := scan(generated); len(got) != 0 {
+		t.Errorf("generated file: %v", got)
+	}
+	if got := scan("package app\n\nfunc main() {\n"); len(got) != 0 {
+		t.Errorf("syntax error: %v", got)
+	}
+}
+
+func TestGoFiles(t *testing.T) {
+	got := goFiles([]string{"/r/main.go", "/r/main_test.go", "/r/vendor/x/y.go", "/r/README.md", "/r/cmd/app/app.go"})
+	if want := []string{"/r/main.go", "/r/cmd/app/app.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("goFiles = %v, want %v", got, want)
+	}
+}
+
+func TestImportName(t *testing.T) {
+	for p, want := range map[string]string{
+		"net/http": "http", "math/rand/v2": "rand", "gopkg.in/yaml.v3": "yaml",
+		"github.com/go-sql-driver/mysql": "mysql", "github.com/foo/go-bar": "go_bar", "v8": "v8",
+	} {
+		if got := importName(p); got != want {
+			t.Errorf("importName(%q) = %q, want %q", p, got, want)
+		}
+	}
+}
+
+// numbered — исходник с номерами строк для сообщения об ошибке
+func numbered(src string) string {
+	var b strings.Builder
+	for i, l := range strings.Split(src, "\n") {
+		fmt.Fprintf(&b, "%3d  %s\n", i+1, l)
+	}
+	return b.String()
+}
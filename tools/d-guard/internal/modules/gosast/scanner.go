@@ -0,0 +1,55 @@
+package gosast
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/devos-os/d-guard/internal/core"
+	"github.com/devos-os/d-guard/internal/scanner"
+)
+
+// Нативный SAST для Go на go/parser и go/types: запускается вместо Semgrep,
+// если тот не установлен или отключен. Каждый файл анализируется отдельно
+// (импорты не загружаются), поэтому правила опираются на пути импортов и
+// константность выражений, а не на полные типы.
+
+func init() { scanner.Register(goScanner{}) }
+
+type goScanner struct{}
+
+func (goScanner) Name() string        { return "gosast" }
+func (goScanner) FallbackFor() string { return "semgrep" }
+
+func (goScanner) Applicable(t scanner.Target) bool   { return len(goFiles(t.Files)) > 0 }
+func (goScanner) SkipReason(t scanner.Target) string { return "no Go files to scan" }
+
+// Правила встроены в бинарник: их версия учтена в отпечатке кэша
+func (goScanner) RulesHash(t scanner.Target) (string, error) { return "builtin", nil }
+
+func (goScanner) Scan(ctx context.Context, t scanner.Target) ([]core.Issue, error) {
+	return scanner.EachFile(ctx, t, goFiles(t.Files), ScanFile)
+}
+
+// goFiles — исходники Go без тестов и vendor/ (тестовые md5 и InsecureSkipVerify — норма)
+func goFiles(files []string) []string {
+	var out []string
+	for _, f := range files {
+		slashed := filepath.ToSlash(f)
+		if !strings.HasSuffix(f, ".go") || strings.HasSuffix(f, "_test.go") || strings.Contains(slashed, "/vendor/") {
+			continue
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+// generated — "// Code generated ... DO NOT EDIT." до объявления пакета
+func generated(data []byte) bool {
+	head := data
+	if i := bytes.Index(head, []byte("\npackage ")); i >= 0 {
+		head = head[:i]
+	}
+	return bytes.Contains(head, []byte("Code generated")) && bytes.Contains(head, []byte("DO NOT EDIT"))
+}
@@ -0,0 +1,99 @@
+package iac
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/devos-os/d-guard/internal/core"
+	"github.com/devos-os/d-guard/internal/modules/container"
+	"gopkg.in/yaml.v3"
+)
+
+// scanCompose проверяет services.* docker-compose файла
+func scanCompose(path string, doc *yaml.Node) []core.Issue {
+	var issues []core.Issue
+	for _, kv := range pairs(get(doc, "services")) {
+		name, svc := kv[0].Value, kv[1]
+		add := func(n *yaml.Node, sev core.Severity, rule, msg, suggestion string) {
+			issues = append(issues, core.Issue{
+				Scanner: "IaC Compose", Severity: sev, File: path, Line: line(n, kv[0].Line), RuleID: rule,
+				Message: fmt.Sprintf("Service '%s': %s", name, msg), Suggestion: suggestion,
+			})
+		}
+
+		if n := get(svc, "privileged"); isTrue(n) {
+			add(n, core.SevCritical, "compose-privileged", "runs in privileged mode",
+				"Remove 'privileged: true' and grant only the capabilities the service needs")
+		}
+
+		for _, mode := range []string{"network_mode", "pid", "ipc"} {
+			if n := get(svc, mode); str(n) == "host" {
+				add(n, core.SevHigh, "compose-host-namespace", fmt.Sprintf("shares the host namespace (%s: host)", mode),
+					"Use an isolated namespace; publish only the required ports")
+			}
+		}
+
+		for _, v := range items(get(svc, "volumes")) {
+			src := str(get(v, "source"))
+			if v.Kind == yaml.ScalarNode {
+				src, _, _ = strings.Cut(v.Value, ":")
+			}
+			switch {
+			case runtimeSocket(src):
+				add(v, core.SevCritical, "compose-docker-socket", fmt.Sprintf("mounts the container runtime socket %s", src),
+					"This gives full root access to the host. Use API proxy instead.")
+			case strings.HasPrefix(src, "/") && sensitiveHostPath(src):
+				add(v, core.SevHigh, "compose-host-path", fmt.Sprintf("mounts sensitive host path %s", src),
+					"Mount only application data, never host system directories")
+			}
+		}
+
+		if n := get(svc, "user"); n != nil {
+			u, _, _ := strings.Cut(str(n), ":")
+			if u == "root" || u == "0" {
+				add(n, core.SevMedium, "compose-run-as-root", "runs as root",
+					"Run the service as a non-root user ('user: 1000:1000')")
+			}
+		}
+
+		for _, c := range items(get(svc, "cap_add")) {
+			sev := core.SevMedium
+			if container.DangerousCapability(c.Value) {
+				sev = core.SevHigh
+			}
+			add(c, sev, "compose-added-capabilities", fmt.Sprintf("adds capability %s", c.Value),
+				"Drop all capabilities ('cap_drop: [ALL]') and add back only what is strictly required")
+		}
+
+		if n := get(svc, "image"); n != nil && get(svc, "build") == nil && floating(str(n)) {
+			add(n, core.SevMedium, "compose-latest-image", fmt.Sprintf("image '%s' is not pinned to a version", str(n)),
+				"Pin specific version (e.g., postgres:16.2) for reproducibility")
+		}
+
+		if get(svc, "deploy", "resources", "limits") == nil && get(svc, "mem_limit") == nil && get(svc, "cpus") == nil {
+			add(nil, core.SevLow, "compose-no-resource-limits", "has no CPU/memory limits",
+				"Set 'deploy.resources.limits' (or mem_limit/cpus) so one service cannot starve the host")
+		}
+
+		composeEnv(get(svc, "environment"), add)
+	}
+	return issues
+}
+
+// composeEnv: environment в виде mapping (KEY: value) или списка ("KEY=value")
+func composeEnv(env *yaml.Node, add emitFunc) {
+	check := func(n *yaml.Node, key, value string) {
+		if plaintextSecret(key, value) {
+			add(n, core.SevHigh, "compose-plaintext-secret", fmt.Sprintf("secret %s is written in plain text", key),
+				"Use an env_file excluded from git, ${VAR} interpolation or compose 'secrets:'")
+		}
+	}
+	for _, kv := range pairs(env) {
+		check(kv[1], kv[0].Value, str(kv[1]))
+	}
+	for _, item := range items(env) {
+		if key, value, ok := strings.Cut(item.Value, "="); ok {
+			check(item, key, value)
+		}
+	}
+}
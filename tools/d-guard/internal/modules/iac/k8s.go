@@ -0,0 +1,131 @@
+package iac
+
+import (
+	"fmt"
+
+	"github.com/devos-os/d-guard/internal/core"
+	"github.com/devos-os/d-guard/internal/modules/container"
+	"gopkg.in/yaml.v3"
+)
+
+// isKubernetes: документ с apiVersion и kind
+func isKubernetes(doc *yaml.Node) bool {
+	return str(get(doc, "apiVersion")) != "" && str(get(doc, "kind")) != ""
+}
+
+// podSpec находит spec пода для рабочих нагрузок
+func podSpec(doc *yaml.Node) *yaml.Node {
+	switch str(get(doc, "kind")) {
+	case "Pod":
+		return get(doc, "spec")
+	case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "ReplicationController", "Job":
+		return get(doc, "spec", "template", "spec")
+	case "CronJob":
+		return get(doc, "spec", "jobTemplate", "spec", "template", "spec")
+	}
+	return nil
+}
+
+// scanKubernetes проверяет pod spec одного объекта
+func scanKubernetes(path string, doc *yaml.Node) []core.Issue {
+	spec := podSpec(doc)
+	if spec == nil {
+		return nil
+	}
+	object := str(get(doc, "kind")) + "/" + str(get(doc, "metadata", "name"))
+
+	var issues []core.Issue
+	emit := func(subject string) emitFunc {
+		return func(n *yaml.Node, sev core.Severity, rule, msg, suggestion string) {
+			issues = append(issues, core.Issue{
+				Scanner: "IaC Kubernetes", Severity: sev, File: path, Line: line(n, spec.Line), RuleID: rule,
+				Message: fmt.Sprintf("%s: %s", subject, msg), Suggestion: suggestion,
+			})
+		}
+	}
+	add := emit(object)
+
+	for _, ns := range []string{"hostNetwork", "hostPID", "hostIPC"} {
+		if n := get(spec, ns); isTrue(n) {
+			add(n, core.SevHigh, "k8s-host-namespace", fmt.Sprintf("shares the host namespace (%s: true)", ns),
+				fmt.Sprintf("Remove '%s' unless the workload is a node-level agent", ns))
+		}
+	}
+
+	for _, v := range items(get(spec, "volumes")) {
+		hp := get(v, "hostPath", "path")
+		if hp == nil {
+			continue
+		}
+		if runtimeSocket(hp.Value) {
+			add(hp, core.SevCritical, "k8s-docker-socket", fmt.Sprintf("mounts the container runtime socket %s", hp.Value),
+				"This gives full root access to the node. Use API proxy instead.")
+			continue
+		}
+		add(hp, core.SevHigh, "k8s-host-path", fmt.Sprintf("mounts hostPath %s", hp.Value),
+			"Use a PersistentVolumeClaim, configMap or emptyDir instead of hostPath")
+	}
+
+	podSC := get(spec, "securityContext")
+	for _, group := range []string{"initContainers", "containers", "ephemeralContainers"} {
+		for _, c := range items(get(spec, group)) {
+			scanContainer(c, podSC, emit(fmt.Sprintf("%s container '%s'", object, str(get(c, "name")))))
+		}
+	}
+	return issues
+}
+
+func scanContainer(c, podSC *yaml.Node, add emitFunc) {
+	sc := get(c, "securityContext")
+
+	if n := get(sc, "privileged"); isTrue(n) {
+		add(n, core.SevCritical, "k8s-privileged", "runs in privileged mode",
+			"Set 'securityContext.privileged: false' and grant only the capabilities the container needs")
+	}
+
+	// Настройки контейнера перекрывают настройки пода
+	runAsUser, runAsNonRoot := get(sc, "runAsUser"), get(sc, "runAsNonRoot")
+	if runAsUser == nil {
+		runAsUser = get(podSC, "runAsUser")
+	}
+	if runAsNonRoot == nil {
+		runAsNonRoot = get(podSC, "runAsNonRoot")
+	}
+	switch {
+	case str(runAsUser) == "0":
+		add(runAsUser, core.SevMedium, "k8s-run-as-root", "runs as root (runAsUser: 0)",
+			"Run as a non-root UID and set 'runAsNonRoot: true'")
+	case isFalse(runAsNonRoot):
+		add(runAsNonRoot, core.SevMedium, "k8s-run-as-root", "explicitly allows running as root (runAsNonRoot: false)",
+			"Set 'runAsNonRoot: true'")
+	case runAsUser == nil && !isTrue(runAsNonRoot):
+		add(c, core.SevLow, "k8s-run-as-root", "may run as root (no runAsNonRoot/runAsUser)",
+			"Set 'securityContext.runAsNonRoot: true' on the pod or container")
+	}
+
+	for _, cp := range items(get(sc, "capabilities", "add")) {
+		sev := core.SevMedium
+		if container.DangerousCapability(cp.Value) {
+			sev = core.SevHigh
+		}
+		add(cp, sev, "k8s-added-capabilities", fmt.Sprintf("adds capability %s", cp.Value),
+			"Drop ALL capabilities and add back only what is strictly required")
+	}
+
+	if n := get(c, "image"); n != nil && floating(n.Value) {
+		add(n, core.SevMedium, "k8s-latest-image", fmt.Sprintf("image '%s' is not pinned to a version", n.Value),
+			"Pin a specific version or digest so rollouts are reproducible")
+	}
+
+	if get(c, "resources", "limits") == nil {
+		add(c, core.SevLow, "k8s-no-resource-limits", "has no resources.limits",
+			"Set CPU and memory limits so one pod cannot starve the node")
+	}
+
+	for _, e := range items(get(c, "env")) {
+		if v := get(e, "value"); v != nil && plaintextSecret(str(get(e, "name")), v.Value) {
+			add(v, core.SevHigh, "k8s-plaintext-secret", fmt.Sprintf("secret %s is written in plain text", str(get(e, "name"))),
+				"Reference a Secret via 'valueFrom.secretKeyRef' instead of an inline value")
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package iac
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/devos-os/d-guard/internal/core"
+	"github.com/devos-os/d-guard/internal/scanner"
+	"gopkg.in/yaml.v3"
+)
+
+// Нативные проверки docker-compose и Kubernetes манифестов (без Trivy).
+// Позиции берутся из yaml.Node, поэтому находки указывают на конкретную строку.
+
+func init() { scanner.Register(iacScanner{}) }
+
+type iacScanner struct{}
+
+func (iacScanner) Name() string                       { return "iac" }
+func (iacScanner) Applicable(t scanner.Target) bool   { return len(yamlFiles(t.Files)) > 0 }
+func (iacScanner) SkipReason(t scanner.Target) string { return "no YAML manifests to scan" }
+
+// Правила встроены в бинарник: их версия учтена в отпечатке кэша
+func (iacScanner) RulesHash(t scanner.Target) (string, error) { return "builtin", nil }
+
+func (iacScanner) Scan(ctx context.Context, t scanner.Target) ([]core.Issue, error) {
+	return scanner.EachFile(ctx, t, yamlFiles(t.Files), ScanFile)
+}
+
+var composeNames = regexp.MustCompile(`(?i)^(docker-)?compose([.-][\w.-]+)?\.ya?ml$`)
+
+// IsCompose: docker-compose.yml, compose.yaml, docker-compose.prod.yml
+func IsCompose(path string) bool {
+	return composeNames.MatchString(filepath.Base(path))
+}
+
+func yamlFiles(files []string) []string {
+	var out []string
+	for _, f := range files {
+		if ext := strings.ToLower(filepath.Ext(f)); ext == ".yml" || ext == ".yaml" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// ScanFile проверяет compose-файл или YAML с Kubernetes объектами (в т.ч. multi-document)
+func ScanFile(path string, data []byte) []core.Issue {
+	var issues []core.Issue
+	docs := decode(data)
+	if IsCompose(path) {
+		for _, doc := range docs {
+			issues = append(issues, scanCompose(path, doc)...)
+		}
+		return issues
+	}
+	for _, doc := range docs {
+		if isKubernetes(doc) {
+			issues = append(issues, scanKubernetes(path, doc)...)
+		}
+	}
+	return issues
+}
+
+// decode читает все документы файла. Невалидный YAML (e.g. шаблоны Helm)
+// молча пропускается: это не ошибка сканирования.
+func decode(data []byte) []*yaml.Node {
+	var docs []*yaml.Node
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) || err != nil {
+			break
+		}
+		if len(doc.Content) > 0 && doc.Content[0].Kind == yaml.MappingNode {
+			docs = append(docs, doc.Content[0])
+		}
+	}
+	return docs
+}
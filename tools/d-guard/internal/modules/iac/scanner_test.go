@@ -0,0 +1,487 @@
+package iac
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/devos-os/d-guard/internal/core"
+)
+
+// ruleAt — правило и строка находки
+type ruleAt struct {
+	Rule string
+	Line int
+}
+
+func scan(path, src string) []ruleAt {
+	out := []ruleAt{}
+	for _, is := range ScanFile(path, []byte(src)) {
+		out = append(out, ruleAt{is.RuleID, is.Line})
+	}
+	return out
+}
+
+func TestComposeRules(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []ruleAt
+	}{
+		{"clean service", `services:
+  app:
+    image: nginx:1.25
+    mem_limit: 256m
+    user: "1000:1000"
+`, nil},
+
+		// --- compose-privileged ---
+		{"privileged", `services:
+  app:
+    image: nginx:1.25
+    mem_limit: 256m
+    privileged: true
+`, []ruleAt{{"compose-privileged", 5}}},
+		{"privileged false", `services:
+  app:
+    image: nginx:1.25
+    mem_limit: 256m
+    privileged: false
+`, nil},
+
+		// --- compose-host-namespace ---
+		{"host namespaces", `services:
+  app:
+    image: nginx:1.25
+    mem_limit: 256m
+    network_mode: host
+    pid: host
+    ipc: shareable
+`, []ruleAt{{"compose-host-namespace", 5}, {"compose-host-namespace", 6}}},
+
+		// --- compose-docker-socket, compose-host-path ---
+		{"volumes", `services:
+  app:
+    image: nginx:1.25
+    mem_limit: 256m
+    volumes:
+      - /var/run/docker.sock:/var/run/docker.sock
+      - type: bind
+        source: /etc
+        target: /host-etc
+      - ./data:/data
+      - /srv/app:/srv/app
+      - cache:/cache
+`, []ruleAt{{"compose-docker-socket", 6}, {"compose-host-path", 7}}},
+
+		// --- compose-run-as-root ---
+		{"user root", `services:
+  app:
+    image: nginx:1.25
+    mem_limit: 256m
+    user: "0:0"
+  worker:
+    image: nginx:1.25
+    mem_limit: 256m
+    user: root
+`, []ruleAt{{"compose-run-as-root", 5}, {"compose-run-as-root", 9}}},
+
+		// --- compose-added-capabilities ---
+		{"cap_add", `services:
+  app:
+    image: nginx:1.25
+    mem_limit: 256m
+    cap_add:
+      - NET_ADMIN
+      - CHOWN
+`, []ruleAt{{"compose-added-capabilities", 6}, {"compose-added-capabilities", 7}}},
+
+		// --- compose-latest-image ---
+		{"floating images", `services:
+  app:
+    image: nginx
+    mem_limit: 256m
+  db:
+    image: postgres:latest
+    mem_limit: 256m
+  cache:
+    image: redis@sha256:0123456789abcdef
+    mem_limit: 256m
+  api:
+    image: api
+    build: .
+    mem_limit: 256m
+`, []ruleAt{{"compose-latest-image", 3}, {"compose-latest-image", 6}}},
+
+		// --- compose-no-resource-limits ---
+		{"resource limits", `services:
+  app:
+    image: nginx:1.25
+  worker:
+    image: nginx:1.25
+    deploy:
+      resources:
+        limits:
+          memory: 256m
+  cron:
+    image: nginx:1.25
+    cpus: "0.5"
+`, []ruleAt{{"compose-no-resource-limits", 2}}},
+
+		// --- compose-plaintext-secret ---
+		{"environment mapping and list", `services:
+  app:
+    image: nginx:1.25
+    mem_limit: 256m
+    environment:
+      DB_PASSWORD: hunter2
+      API_TOKEN: ${API_TOKEN}
+      LOG_LEVEL: debug
+  worker:
+    image: nginx:1.25
+    mem_limit: 256m
+    environment:
+      - SECRET_KEY=abc123
+      - ACCESS_KEY=
+      - DEBUG=1
+`, []ruleAt{{"compose-plaintext-secret", 6}, {"compose-plaintext-secret", 13}}},
+
+		// --- якоря, алиасы и слияние ключей ---
+		{"merge key from anchor", `x-base: &base
+  privileged: true
+  mem_limit: 256m
+services:
+  app:
+    <<: *base
+    image: nginx:1.25
+  web:
+    <<: *base
+    privileged: false
+    image: nginx:1.25
+`, []ruleAt{{"compose-privileged", 2}}},
+		{"merge list and aliased values", `x-caps: &caps
+  - SYS_ADMIN
+x-limits: &limits
+  mem_limit: 256m
+x-env: &env
+  DB_PASSWORD: hunter2
+services:
+  app:
+    <<: [*limits]
+    image: nginx:1.25
+    cap_add: *caps
+    environment: *env
+`, []ruleAt{{"compose-added-capabilities", 2}, {"compose-plaintext-secret", 6}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.want
+			if want == nil {
+				want = []ruleAt{}
+			}
+			if got := scan("docker-compose.yml", tt.src); !reflect.DeepEqual(got, want) {
+				t.Errorf("findings = %v, want %v\n%s", got, want, numbered(tt.src))
+			}
+		})
+	}
+}
+
+// pod — начало манифеста пода: строки 1-8, контейнеры с 9-й
+const pod = `apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  securityContext:
+    runAsNonRoot: true
+  containers:
+`
+
+func TestKubernetesRules(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []ruleAt
+	}{
+		{"clean pod", pod + `    - name: app
+      image: nginx:1.25
+      resources:
+        limits: {memory: 128Mi}
+`, nil},
+
+		// --- k8s-host-namespace ---
+		{"host namespaces", `apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  hostNetwork: true
+  hostPID: false
+  hostIPC: true
+  securityContext:
+    runAsNonRoot: true
+  containers:
+    - name: app
+      image: nginx:1.25
+      resources:
+        limits: {memory: 128Mi}
+`, []ruleAt{{"k8s-host-namespace", 6}, {"k8s-host-namespace", 8}}},
+
+		// --- k8s-docker-socket, k8s-host-path ---
+		{"hostPath volumes", pod + `    - name: app
+      image: nginx:1.25
+      resources:
+        limits: {memory: 128Mi}
+  volumes:
+    - name: docker
+      hostPath:
+        path: /var/run/docker.sock
+    - name: logs
+      hostPath:
+        path: /var/log
+    - name: tmp
+      emptyDir: {}
+`, []ruleAt{{"k8s-docker-socket", 16}, {"k8s-host-path", 19}}},
+
+		// --- k8s-privileged ---
+		{"privileged", pod + `    - name: app
+      image: nginx:1.25
+      securityContext:
+        privileged: true
+      resources:
+        limits: {memory: 128Mi}
+`, []ruleAt{{"k8s-privileged", 12}}},
+
+		// --- k8s-run-as-root ---
+		{"runAsUser 0 overrides pod", pod + `    - name: app
+      image: nginx:1.25
+      securityContext:
+        runAsUser: 0
+      resources:
+        limits: {memory: 128Mi}
+`, []ruleAt{{"k8s-run-as-root", 12}}},
+		{"runAsNonRoot false", pod + `    - name: app
+      image: nginx:1.25
+      securityContext:
+        runAsNonRoot: false
+      resources:
+        limits: {memory: 128Mi}
+`, []ruleAt{{"k8s-run-as-root", 12}}},
+		{"no security context", `apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+    - name: app
+      image: nginx:1.25
+      resources:
+        limits: {memory: 128Mi}
+    - name: sidecar
+      image: envoy:1.29
+      securityContext:
+        runAsUser: 1000
+      resources:
+        limits: {memory: 128Mi}
+`, []ruleAt{{"k8s-run-as-root", 7}}},
+
+		// --- k8s-added-capabilities ---
+		{"capabilities", pod + `    - name: app
+      image: nginx:1.25
+      securityContext:
+        capabilities:
+          add: [SYS_ADMIN, NET_BIND_SERVICE]
+          drop: [ALL]
+      resources:
+        limits: {memory: 128Mi}
+`, []ruleAt{{"k8s-added-capabilities", 13}, {"k8s-added-capabilities", 13}}},
+
+		// --- k8s-latest-image ---
+		{"floating images", pod + `    - name: app
+      image: nginx:latest
+      resources:
+        limits: {memory: 128Mi}
+  initContainers:
+    - name: init
+      image: busybox
+      resources:
+        limits: {memory: 64Mi}
+    - name: migrate
+      image: migrate@sha256:0123456789abcdef
+      resources:
+        limits: {memory: 64Mi}
+`, []ruleAt{{"k8s-latest-image", 15}, {"k8s-latest-image", 10}}},
+
+		// --- k8s-no-resource-limits ---
+		{"no limits", pod + `    - name: app
+      image: nginx:1.25
+      resources:
+        requests: {memory: 128Mi}
+`, []ruleAt{{"k8s-no-resource-limits", 9}}},
+
+		// --- k8s-plaintext-secret ---
+		{"env values", pod + `    - name: app
+      image: nginx:1.25
+      resources:
+        limits: {memory: 128Mi}
+      env:
+        - name: DB_PASSWORD
+          value: hunter2
+        - name: API_TOKEN
+          valueFrom:
+            secretKeyRef: {name: api, key: token}
+        - name: LOG_LEVEL
+          value: debug
+`, []ruleAt{{"k8s-plaintext-secret", 15}}},
+
+		// --- вложенные pod spec ---
+		{"deployment and cronjob", `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      hostNetwork: true
+      securityContext:
+        runAsNonRoot: true
+      containers:
+        - name: app
+          image: nginx:1.25
+          resources:
+            limits: {memory: 128Mi}
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: nightly
+spec:
+  schedule: "0 3 * * *"
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          hostPID: true
+          securityContext:
+            runAsNonRoot: true
+          containers:
+            - name: job
+              image: job:2.0
+              resources:
+                limits: {memory: 128Mi}
+`, []ruleAt{{"k8s-host-namespace", 8}, {"k8s-host-namespace", 27}}},
+
+		// --- multi-document: строки считаются от начала файла ---
+		{"multi-document with other kinds", `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  password: hunter2
+---
+# не Kubernetes-объект
+foo: bar
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  securityContext:
+    runAsNonRoot: true
+  containers:
+    - name: app
+      image: nginx
+      resources:
+        limits: {memory: 128Mi}
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports: [{port: 80}]
+`, []ruleAt{{"k8s-latest-image", 20}}},
+
+		// --- якоря и алиасы ---
+		{"anchors and aliases", `apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  securityContext:
+    runAsNonRoot: true
+  containers:
+    - &app
+      name: app
+      image: nginx:1.25
+      securityContext: &sc
+        privileged: true
+      resources:
+        limits: {memory: 128Mi}
+    - <<: *app
+      name: copy
+    - name: other
+      image: nginx:1.25
+      securityContext: *sc
+      resources:
+        limits: {memory: 128Mi}
+`, []ruleAt{{"k8s-privileged", 13}, {"k8s-privileged", 13}, {"k8s-privileged", 13}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.want
+			if want == nil {
+				want = []ruleAt{}
+			}
+			if got := scan("deploy/app.yaml", tt.src); !reflect.DeepEqual(got, want) {
+				t.Errorf("findings = %v, want %v\n%s", got, want, numbered(tt.src))
+			}
+		})
+	}
+}
+
+func TestCapabilitySeverity(t *testing.T) {
+	src := "services:\n  app:\n    image: nginx:1.25\n    mem_limit: 256m\n    cap_add: [SYS_ADMIN, CHOWN]\n"
+	got := map[string]core.Severity{}
+	for _, is := range ScanFile("compose.yaml", []byte(src)) {
+		got[is.Message] = is.Severity
+	}
+	want := map[string]core.Severity{
+		"Service 'app': adds capability SYS_ADMIN": core.SevHigh,
+		"Service 'app': adds capability CHOWN":     core.SevMedium,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("severities = %v, want %v", got, want)
+	}
+}
+
+func TestScanFileInput(t *testing.T) {
+	// Шаблон Helm — невалидный YAML: не ошибка и не находки
+	if got := scan("templates/deploy.yaml", "spec:\n  replicas: {{ .Values.replicas }}\n  - broken"); len(got) != 0 {
+		t.Errorf("invalid YAML: %v", got)
+	}
+	// Compose-правила только для файлов compose, Kubernetes — только для объектов с apiVersion/kind
+	src := "services:\n  app:\n    image: nginx:1.25\n    privileged: true\n"
+	if got := scan("config.yaml", src); len(got) != 0 {
+		t.Errorf("compose rules on config.yaml: %v", got)
+	}
+	for path, want := range map[string]bool{
+		"docker-compose.yml": true, "compose.yaml": true, "deploy/docker-compose.prod.yml": true,
+		"Compose.YML": true, "compose-dev.yaml": true, "my-compose.yml": false, "docker-compose.json": false,
+	} {
+		if got := IsCompose(path); got != want {
+			t.Errorf("IsCompose(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+// numbered — исходник с номерами строк для сообщения об ошибке
+func numbered(src string) string {
+	var b strings.Builder
+	for i, l := range strings.Split(src, "\n") {
+		fmt.Fprintf(&b, "%3d  %s\n", i+1, l)
+	}
+	return b.String()
+}
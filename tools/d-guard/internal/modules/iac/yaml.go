@@ -0,0 +1,137 @@
+package iac
+
+import (
+	"strings"
+
+	"github.com/devos-os/d-guard/internal/core"
+	"github.com/devos-os/d-guard/internal/modules/container"
+	"gopkg.in/yaml.v3"
+)
+
+// emitFunc добавляет находку с позицией узла
+type emitFunc func(n *yaml.Node, sev core.Severity, rule, msg, suggestion string)
+
+// get спускается по ключам mapping-узлов; nil, если пути нет
+func get(n *yaml.Node, path ...string) *yaml.Node {
+	for _, key := range path {
+		var next *yaml.Node
+		for _, kv := range pairs(n) {
+			if kv[0].Value == key {
+				next = kv[1]
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		n = next
+	}
+	return n
+}
+
+// resolve разворачивает алиасы (*name) в узел с якорем (&name)
+func resolve(n *yaml.Node) *yaml.Node {
+	for n != nil && n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	return n
+}
+
+// pairs — пары ключ/значение mapping-узла, включая ключи из слияния (<<: *base).
+// Собственные ключи перекрывают слитые, из списка (<<: [*a, *b]) важнее первый.
+func pairs(n *yaml.Node) [][2]*yaml.Node {
+	n = resolve(n)
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	var out, merged [][2]*yaml.Node
+	seen := map[string]bool{}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], resolve(n.Content[i+1])
+		if key.Value == "<<" && key.Tag != "!!str" {
+			sources := []*yaml.Node{value}
+			if value != nil && value.Kind == yaml.SequenceNode {
+				sources = value.Content
+			}
+			for _, src := range sources {
+				merged = append(merged, pairs(src)...)
+			}
+			continue
+		}
+		seen[key.Value] = true
+		out = append(out, [2]*yaml.Node{key, value})
+	}
+	for _, kv := range merged {
+		if !seen[kv[0].Value] {
+			seen[kv[0].Value] = true
+			out = append(out, kv)
+		}
+	}
+	return out
+}
+
+// items — элементы sequence-узла
+func items(n *yaml.Node) []*yaml.Node {
+	n = resolve(n)
+	if n == nil || n.Kind != yaml.SequenceNode {
+		return nil
+	}
+	out := make([]*yaml.Node, 0, len(n.Content))
+	for _, item := range n.Content {
+		out = append(out, resolve(item))
+	}
+	return out
+}
+
+func str(n *yaml.Node) string {
+	n = resolve(n)
+	if n == nil || n.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return n.Value
+}
+
+func isTrue(n *yaml.Node) bool {
+	v := strings.ToLower(str(n))
+	return v == "true" || v == "yes" || v == "on"
+}
+
+func isFalse(n *yaml.Node) bool {
+	v := strings.ToLower(str(n))
+	return v == "false" || v == "no" || v == "off"
+}
+
+// line — строка узла или запасная (если узла нет)
+func line(n *yaml.Node, fallback int) int {
+	if n == nil {
+		return fallback
+	}
+	return n.Line
+}
+
+// floating: нет тега или тег latest, и нет digest
+func floating(image string) bool {
+	_, tag, digest := container.SplitImage(image)
+	return digest == "" && (tag == "" || tag == "latest")
+}
+
+// plaintextSecret: имя похоже на секрет, а значение записано прямо в файле
+// (не пустое и не подстановка ${VAR})
+func plaintextSecret(name, value string) bool {
+	return container.LooksLikeSecret(name) && value != "" && !strings.HasPrefix(value, "$")
+}
+
+// runtimeSocket — сокеты контейнерного рантайма (монтирование = root на хосте)
+func runtimeSocket(path string) bool {
+	return strings.HasSuffix(path, "docker.sock") || strings.HasSuffix(path, "containerd.sock") || strings.HasSuffix(path, "crio.sock")
+}
+
+// sensitiveHostPath — системные каталоги хоста
+func sensitiveHostPath(path string) bool {
+	p := strings.TrimRight(path, "/")
+	switch p {
+	case "", "/etc", "/proc", "/sys", "/root", "/var/run", "/run", "/var/lib/docker", "/var/lib/kubelet", "/boot", "/dev":
+		return true
+	}
+	return strings.HasPrefix(p, "/proc/") || strings.HasPrefix(p, "/sys/") || strings.HasPrefix(p, "/etc/")
+}
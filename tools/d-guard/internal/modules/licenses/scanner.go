@@ -0,0 +1,168 @@
+package licenses
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/devos-os/d-guard/internal/config"
+	"github.com/devos-os/d-guard/internal/core"
+	"github.com/devos-os/d-guard/internal/license"
+	"github.com/devos-os/d-guard/internal/modules/deps"
+	"github.com/devos-os/d-guard/internal/scanner"
+	"github.com/devos-os/d-guard/internal/source"
+)
+
+// Проверка лицензий зависимостей по политике licenses в .d-guard.yaml.
+// Лицензии — из lockfile, vendor-каталогов и локальных кэшей пакетов (без сети).
+
+func init() { scanner.Register(licensesScanner{}) }
+
+type licensesScanner struct{}
+
+func (licensesScanner) Name() string { return "licenses" }
+
+func (licensesScanner) Applicable(t scanner.Target) bool {
+	return len(deps.Lockfiles(t.Source(), t.Files)) > 0
+}
+func (licensesScanner) SkipReason(t scanner.Target) string { return "no dependency lockfiles to scan" }
+
+func (licensesScanner) Scan(ctx context.Context, t scanner.Target) ([]core.Issue, error) {
+	return Scan(ctx, t.Source(), t.Root, t.Files, t.Config.Licenses)
+}
+
+// Scan проверяет лицензии пакетов из lockfile среди files
+func Scan(ctx context.Context, fsys source.FS, root string, files []string, policy config.Licenses) ([]core.Issue, error) {
+	var issues []core.Issue
+	resolver := license.NewResolver(root)
+
+	for _, path := range deps.Lockfiles(fsys, files) {
+		pkgs, err := deps.Parse(fsys, path)
+		if err != nil {
+			continue
+		}
+		graph := deps.NewGraph(pkgs)
+		for i, p := range pkgs {
+			if ignored(policy.Ignore, p) {
+				continue
+			}
+			lic := p.License
+			if lic == "" {
+				lic = resolver.Resolve(p.Ecosystem, p.Name, p.Version, filepath.Dir(path))
+			}
+			if issue, ok := evaluate(policy, p, lic); ok {
+				issue.File, issue.Line = path, p.Line
+				issue.Description = dependencyPath(graph.Path(i)) + issue.Description
+				issues = append(issues, issue)
+			}
+		}
+		if ctx.Err() != nil {
+			return issues, ctx.Err()
+		}
+	}
+	return issues, nil
+}
+
+// evaluate применяет политику: deny, затем allow, затем copyleft и неизвестные лицензии
+func evaluate(policy config.Licenses, p deps.Package, lic string) (core.Issue, bool) {
+	pkg := p.Name + "@" + p.Version
+	if lic == "" {
+		if policy.Unknown == "" || policy.Unknown == "off" {
+			return core.Issue{}, false
+		}
+		return core.Issue{
+			Scanner:     "License",
+			RuleID:      "license-unknown",
+			Severity:    actionSeverity(policy.Unknown, core.SevLow),
+			Message:     fmt.Sprintf("License of %s is unknown", pkg),
+			Description: "No license in the lockfile, vendor directory or local package cache.",
+			Suggestion:  "Install dependencies locally (module cache, node_modules, .venv) so their metadata can be read, or review the package and add it to licenses.ignore",
+		}, true
+	}
+
+	expr := license.Parse(lic)
+	if len(policy.Deny) > 0 && !expr.Satisfied(func(id string) bool { return !listed(policy.Deny, id) }) {
+		return core.Issue{
+			Scanner:     "License",
+			RuleID:      "license-denied",
+			Severity:    core.SevHigh,
+			Message:     fmt.Sprintf("%s is licensed under %s, which is denied by licenses.deny", pkg, lic),
+			Description: "The license is explicitly denied by the project policy.",
+			Suggestion:  fmt.Sprintf("Replace %s with an alternative under an approved license, or add it to licenses.ignore after a legal review", p.Name),
+		}, true
+	}
+	allowed := len(policy.Allow) > 0 && expr.Satisfied(func(id string) bool { return listed(policy.Allow, id) })
+	if len(policy.Allow) > 0 && !allowed {
+		return core.Issue{
+			Scanner:     "License",
+			RuleID:      "license-not-allowed",
+			Severity:    core.SevHigh,
+			Message:     fmt.Sprintf("%s is licensed under %s, which is not in licenses.allow", pkg, lic),
+			Description: fmt.Sprintf("Allowed licenses: %s.", strings.Join(policy.Allow, ", ")),
+			Suggestion:  fmt.Sprintf("Replace %s, or add %s to licenses.allow after a legal review", p.Name, lic),
+		}, true
+	}
+
+	// Явно разрешенная лицензия не требует предупреждения о копилефте
+	if allowed || policy.Copyleft == "off" || expr.Satisfied(func(id string) bool { return license.Copyleft(id) == "" }) {
+		return core.Issue{}, false
+	}
+	kind, sev := license.CopyleftWeak, core.SevLow
+	if !expr.Satisfied(func(id string) bool { return license.Copyleft(id) != license.CopyleftStrong }) {
+		kind, sev = license.CopyleftStrong, core.SevMedium
+	}
+	desc := "Weak copyleft: modifications of the library itself must be published under the same license."
+	if kind == license.CopyleftStrong {
+		desc = "Strong copyleft: distributing software that includes it may require publishing the whole work under the same license."
+	}
+	return core.Issue{
+		Scanner:     "License",
+		RuleID:      "license-copyleft",
+		Severity:    actionSeverity(policy.Copyleft, sev),
+		Message:     fmt.Sprintf("%s is licensed under %s (%s copyleft)", pkg, lic, kind),
+		Description: desc,
+		Suggestion:  fmt.Sprintf("Check that the way %s is used and distributed complies with %s, then add it to licenses.allow or licenses.ignore", p.Name, lic),
+	}, true
+}
+
+// actionSeverity: deny — HIGH, warn — уровень по умолчанию для правила
+func actionSeverity(action string, warn core.Severity) core.Severity {
+	if action == "deny" {
+		return core.SevHigh
+	}
+	return warn
+}
+
+// listed сравнивает ID без учета регистра и устаревших форм ("GPL-2.0" = "GPL-2.0-only").
+// Лист "X WITH исключение" совпадает с записью целиком или с X.
+func listed(list []string, id string) bool {
+	base, _, _ := strings.Cut(id, " WITH ")
+	for _, l := range list {
+		if n := license.Normalize(l); n == license.Normalize(id) || n == license.Normalize(base) {
+			return true
+		}
+	}
+	return false
+}
+
+// ignored: licenses.ignore содержит имя пакета или имя@версию
+func ignored(list []string, p deps.Package) bool {
+	for _, l := range list {
+		if l == p.Name || l == p.Name+"@"+p.Version {
+			return true
+		}
+	}
+	return false
+}
+
+// dependencyPath — откуда пакет попал в проект (для Description)
+func dependencyPath(path []string) string {
+	switch len(path) {
+	case 0:
+		return "Dependency path: unknown (not reachable from direct dependencies in the lockfile graph). "
+	case 1:
+		return "Direct dependency of the project. "
+	}
+	return fmt.Sprintf("Dependency path: project → %s. ", strings.Join(path, " → "))
+}
@@ -0,0 +1,123 @@
+package licenses
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/devos-os/d-guard/internal/config"
+	"github.com/devos-os/d-guard/internal/core"
+	"github.com/devos-os/d-guard/internal/modules/deps"
+	"github.com/devos-os/d-guard/internal/osv"
+	"github.com/devos-os/d-guard/internal/source"
+)
+
+func TestEvaluate(t *testing.T) {
+	allowMIT := config.Licenses{Allow: []string{"MIT", "Apache-2.0", "BSD-3-Clause"}}
+	denyGPL := config.Licenses{Deny: []string{"GPL-3.0-only", "AGPL-3.0"}}
+	tests := []struct {
+		name    string
+		policy  config.Licenses
+		license string
+		rule    string // пусто — находки нет
+		sev     core.Severity
+	}{
+		// --- deny ---
+		{"denied", denyGPL, "GPL-3.0-only", "license-denied", core.SevHigh},
+		{"denied legacy id", denyGPL, "AGPL-3.0-only", "license-denied", core.SevHigh},
+		{"denied case-insensitive", denyGPL, "gpl-3.0", "license-denied", core.SevHigh},
+		{"OR with a non-denied choice", denyGPL, "MIT OR GPL-3.0-only", "", ""},
+		{"AND with a denied part", denyGPL, "MIT AND GPL-3.0-only", "license-denied", core.SevHigh},
+		{"or-later is another id", denyGPL, "GPL-3.0-or-later", "license-copyleft", core.SevMedium},
+
+		// --- allow ---
+		{"allowed", allowMIT, "MIT", "", ""},
+		{"not allowed", allowMIT, "ISC", "license-not-allowed", core.SevHigh},
+		{"OR with an allowed choice", allowMIT, "GPL-2.0-only OR Apache-2.0", "", ""},
+		{"AND needs all allowed", allowMIT, "MIT AND ISC", "license-not-allowed", core.SevHigh},
+		{"WITH matches the base id", config.Licenses{Allow: []string{"GPL-2.0-only"}}, "GPL-2.0-only WITH Classpath-exception-2.0", "", ""},
+		{"non-SPDX text", allowMIT, "SEE LICENSE IN LICENSE", "license-not-allowed", core.SevHigh},
+		{"deny wins over allow", config.Licenses{Allow: []string{"MIT", "GPL-3.0-only"}, Deny: []string{"GPL-3.0"}}, "GPL-3.0-only", "license-denied", core.SevHigh},
+
+		// --- copyleft ---
+		{"strong copyleft warns", config.Licenses{}, "AGPL-3.0-or-later", "license-copyleft", core.SevMedium},
+		{"weak copyleft warns low", config.Licenses{}, "LGPL-2.1-only", "license-copyleft", core.SevLow},
+		{"copyleft deny", config.Licenses{Copyleft: "deny"}, "MPL-2.0", "license-copyleft", core.SevHigh},
+		{"copyleft off", config.Licenses{Copyleft: "off"}, "GPL-3.0-only", "", ""},
+		{"explicitly allowed copyleft", config.Licenses{Allow: []string{"GPL-3.0-only"}}, "GPL-3.0-only", "", ""},
+		{"permissive choice avoids warning", config.Licenses{}, "MIT OR GPL-3.0-only", "", ""},
+		{"strongest part of AND", config.Licenses{}, "LGPL-2.1-only AND GPL-2.0-only", "license-copyleft", core.SevMedium},
+		{"GPL with exception is weak", config.Licenses{}, "GPL-2.0-only WITH Classpath-exception-2.0", "license-copyleft", core.SevLow},
+		{"permissive", config.Licenses{}, "Apache-2.0", "", ""},
+
+		// --- unknown ---
+		{"unknown off by default", config.Licenses{}, "", "", ""},
+		{"unknown warn", config.Licenses{Unknown: "warn"}, "", "license-unknown", core.SevLow},
+		{"unknown deny", config.Licenses{Unknown: "deny"}, "", "license-unknown", core.SevHigh},
+		{"unknown skips allow list", config.Licenses{Allow: []string{"MIT"}}, "", "", ""},
+	}
+	pkg := deps.Package{Ecosystem: osv.NPM, Name: "lib", Version: "1.0.0"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is, ok := evaluate(tt.policy, pkg, tt.license)
+			if tt.rule == "" {
+				if ok {
+					t.Errorf("unexpected finding %s (%s): %s", is.RuleID, is.Severity, is.Message)
+				}
+				return
+			}
+			if !ok || is.RuleID != tt.rule || is.Severity != tt.sev {
+				t.Errorf("finding = %q %s (%v), want %s %s", is.RuleID, is.Severity, ok, tt.rule, tt.sev)
+			}
+		})
+	}
+}
+
+func TestScan(t *testing.T) {
+	t.Setenv("GOMODCACHE", t.TempDir())
+	t.Setenv("CARGO_HOME", t.TempDir())
+	root := t.TempDir()
+	lock := filepath.Join(root, "package-lock.json")
+	fsys := source.Files{lock: []byte(`{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"dependencies": {"app-lib": "^1.0.0", "legal": "^2.0.0"}},
+    "node_modules/app-lib": {"version": "1.0.0", "license": "MIT", "dependencies": {"gpl-dep": "^3.0.0"}},
+    "node_modules/gpl-dep": {"version": "3.0.0", "license": "GPL-3.0-only"},
+    "node_modules/legal": {"version": "2.0.0", "license": "AGPL-3.0-only"}
+  }
+}`)}
+	policy := config.Licenses{Deny: []string{"GPL-3.0-only"}, Ignore: []string{"legal@2.0.0"}}
+
+	issues, err := Scan(context.Background(), fsys, root, []string{lock}, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 {
+		t.Fatalf("issues = %v", issues)
+	}
+	is := issues[0]
+	if is.RuleID != "license-denied" || is.File != lock || is.Line != 6 {
+		t.Errorf("issue = %s %s:%d", is.RuleID, is.File, is.Line)
+	}
+	// Транзитивная зависимость: путь от прямой зависимости проекта
+	if !strings.HasPrefix(is.Description, "Dependency path: project → app-lib@1.0.0 → gpl-dep@3.0.0. ") {
+		t.Errorf("description = %q", is.Description)
+	}
+}
+
+func TestDependencyPath(t *testing.T) {
+	for _, tt := range []struct {
+		path []string
+		want string
+	}{
+		{nil, "Dependency path: unknown"},
+		{[]string{"a@1"}, "Direct dependency of the project. "},
+		{[]string{"a@1", "b@2"}, "Dependency path: project → a@1 → b@2. "},
+	} {
+		if got := dependencyPath(tt.path); !strings.HasPrefix(got, tt.want) {
+			t.Errorf("dependencyPath(%v) = %q, want prefix %q", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package secrets
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+
+	"github.com/devos-os/d-guard/internal/core"
+	"github.com/devos-os/d-guard/internal/source"
+)
+
+// Engine — предкомпилированный набор правил
+type Engine struct {
+	rules []*compiledRule
+	allow *compiledAllowlist // Глобальный allowlist набора
+}
+
+// NewEngine компилирует набор правил один раз для всех файлов
+func NewEngine(p *Pack) (*Engine, error) {
+	e := &Engine{}
+	var err error
+	if e.allow, err = compileAllowlist(p.Allowlist); err != nil {
+		return nil, fmt.Errorf("allowlist: %w", err)
+	}
+	seen := map[string]bool{}
+	for _, r := range p.Rules {
+		if seen[r.ID] {
+			return nil, fmt.Errorf("duplicate rule id %q", r.ID)
+		}
+		seen[r.ID] = true
+		c, err := compileRule(r)
+		if err != nil {
+			return nil, err
+		}
+		e.rules = append(e.rules, c)
+	}
+	return e, nil
+}
+
+// Rules возвращает ID правил в порядке применения
+func (e *Engine) Rules() []string {
+	var ids []string
+	for _, r := range e.rules {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
+
+// ScanContent проверяет содержимое одного файла (path — для отчета и правил path).
+// Чтение, пропуск больших и бинарных файлов — в пуле scanner.EachFile.
+func (e *Engine) ScanContent(path string, data []byte) []core.Issue {
+	rules := e.rulesFor(path)
+	if len(rules) == 0 {
+		return nil
+	}
+
+	var issues []core.Issue
+	// Правила только по пути (e.g. *.pem) срабатывают на сам файл
+	for _, r := range rules {
+		if r.re == nil {
+			issues = append(issues, e.issue(r, path, 0, ""))
+		}
+	}
+
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), source.MaxFileSize)
+	lineNum := 0
+	for sc.Scan() {
+		lineNum++
+		issues = append(issues, e.scanLine(rules, path, lineNum, sc.Text())...)
+	}
+	return issues
+}
+
+// Line — строка файла с номером (e.g. добавленная строка патча)
+type Line struct {
+	N    int
+	Text string
+}
+
+// ScanLines проверяет отдельные строки файла (режим --history: только добавленные
+// строки коммита). Правила только по пути здесь не применяются.
+func (e *Engine) ScanLines(path string, lines []Line) []core.Issue {
+	rules := e.rulesFor(path)
+	var issues []core.Issue
+	for _, l := range lines {
+		issues = append(issues, e.scanLine(rules, path, l.N, l.Text)...)
+	}
+	return issues
+}
+
+// rulesFor отбирает правила, применимые к пути, и учитывает глобальный allowlist
+func (e *Engine) rulesFor(path string) []*compiledRule {
+	slashed := filepath.ToSlash(path)
+	if !e.allow.pathAllowed(slashed) {
+		return nil
+	}
+	var rules []*compiledRule
+	for _, r := range e.rules {
+		if r.path != nil && !r.path.MatchString(slashed) {
+			continue
+		}
+		if !r.allow.pathAllowed(slashed) {
+			continue
+		}
+		rules = append(rules, r)
+	}
+	return rules
+}
+
+// scanLine проверяет строку целиком, какой бы длинной она ни была (минифицированный JS,
+// JSON в одну строку, base64): regexp в Go работает за линейное время, а длина строки
+// ограничена source.MaxFileSize
+func (e *Engine) scanLine(rules []*compiledRule, path string, n int, line string) []core.Issue {
+	var issues []core.Issue
+	lower := strings.ToLower(line)
+	for _, r := range rules {
+		if r.re == nil || !hasKeyword(lower, r.keywords) {
+			continue
+		}
+		for _, m := range r.re.FindAllStringSubmatchIndex(line, -1) {
+			match := line[m[0]:m[1]]
+			secret := secretOf(line, m, r.SecretGroup)
+			if r.Entropy > 0 && Entropy(secret) < r.Entropy {
+				continue
+			}
+			if r.allow.allowed(secret, match, line) || e.allow.allowed(secret, match, line) {
+				continue
+			}
+			issues = append(issues, e.issue(r, path, n, secret))
+			break // Одна находка правила на строку
+		}
+	}
+	return issues
+}
+
+func (e *Engine) issue(r *compiledRule, path string, line int, secret string) core.Issue {
+	desc := r.Description
+	if desc == "" {
+		desc = r.ID
+	}
+	details := desc
+	if secret != "" {
+		details = fmt.Sprintf("%s (value: %s)", desc, redact(secret))
+	}
+	return core.Issue{
+		Scanner:     "Secrets",
+		RuleID:      r.ID,
+		Severity:    r.severity,
+		Message:     fmt.Sprintf("%s found", desc),
+		File:        path,
+		Line:        line,
+		Description: details,
+		Suggestion:  "Move secret to environment variables or Vault",
+	}
+}
+
+// secretOf выбирает сам секрет: secretGroup, иначе первую непустую группу, иначе все совпадение
+func secretOf(line string, m []int, group int) string {
+	if group > 0 && m[2*group] >= 0 {
+		return line[m[2*group]:m[2*group+1]]
+	}
+	for g := 1; 2*g < len(m); g++ {
+		if m[2*g] >= 0 && m[2*g+1] > m[2*g] {
+			return line[m[2*g]:m[2*g+1]]
+		}
+	}
+	return line[m[0]:m[1]]
+}
+
+func hasKeyword(lower string, keywords []string) bool {
+	if len(keywords) == 0 {
+		return true
+	}
+	for _, k := range keywords {
+		if strings.Contains(lower, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// Entropy — энтропия Шеннона строки в битах на символ
+func Entropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	freq := map[rune]float64{}
+	n := 0.0
+	for _, c := range s {
+		freq[c]++
+		n++
+	}
+	var h float64
+	for _, f := range freq {
+		p := f / n
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+// redact оставляет только начало секрета, чтобы отчет сам не стал утечкой
+func redact(s string) string {
+	if len(s) <= 8 {
+		return "****"
+	}
+	return s[:4] + strings.Repeat("*", 4)
+}
@@ -0,0 +1,265 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/devos-os/d-guard/internal/config"
+	"github.com/devos-os/d-guard/internal/core"
+	"github.com/devos-os/d-guard/internal/scanner"
+)
+
+// Фикстуры собираются при выполнении: целые токены в исходниках сами стали бы находками
+// d-guard и gitleaks на этом репозитории.
+
+const alphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// random — детерминированная строка из n символов набора chars с высокой энтропией
+func random(chars string, n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		b.WriteByte(chars[(i*7+i/len(chars))%len(chars)])
+	}
+	return b.String()
+}
+
+func rules(issues []core.Issue) []string {
+	var ids []string
+	for _, is := range issues {
+		ids = append(ids, is.RuleID)
+	}
+	return ids
+}
+
+func TestDefaultRules(t *testing.T) {
+	upper := "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	hexChars := "0123456789abcdef"
+	jwt := "ey" + "JhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9." + "ey" + "JzdWIiOiIxMjM0NTY3ODkwIiwibmFtZSI6IkpvaG4ifQ." + random(alphabet, 43)
+
+	tests := []struct {
+		name string
+		line string
+		want string // "" — находок нет
+	}{
+		{"aws access key", `key = "` + "AKIA" + random(upper, 16) + `"`, "aws-access-token"},
+		{"aws access key with example stopword", `key = "` + "AKIA" + "IOSFODNN7EXAMPLE" + `"`, ""},
+		{"aws access key too short", `key = "` + "AKIA" + random(upper, 15) + `"`, ""},
+		{"aws secret key", `aws_secret_access_key = "` + random(alphabet+"/+", 40) + `"`, "aws-secret-access-key"},
+		{"aws secret key low entropy", `aws_secret_access_key = "` + strings.Repeat("ab", 20) + `"`, ""},
+
+		{"github pat", "token: " + "ghp_" + random(alphabet, 36), "github-pat"},
+		{"github pat wrong length", "token: " + "ghp_" + random(alphabet, 35), ""},
+		{"github oauth", "gho_" + random(alphabet, 36), "github-oauth"},
+		{"github app token", "ghs_" + random(alphabet, 36), "github-app-token"},
+		{"github refresh token", "ghr_" + random(alphabet, 36), "github-refresh-token"},
+		{"github fine-grained pat", "github_pat_" + random(alphabet+"_", 82), "github-fine-grained-pat"},
+
+		{"gitlab pat", "GITLAB=" + "glpat-" + random(alphabet, 20), "gitlab-pat"},
+		{"gitlab pat too short", "GITLAB=" + "glpat-" + random(alphabet, 19), ""},
+		{"gitlab runner token", "glrt-" + random(alphabet, 20), "gitlab-runner-token"},
+
+		{"slack bot token", "xoxb-" + "1234567890-1234567890-" + random(alphabet, 24), "slack-bot-token"},
+		{"slack user token", "xoxp-" + "1234567890-1234567890-1234567890-" + random(alphabet, 32), "slack-user-token"},
+		{"slack webhook", "https://hooks.slack.com/services/" + "T00000000/B00000000/" + random(alphabet, 24), "slack-webhook-url"},
+		{"slack bot token malformed", "xoxb-" + "123-456", ""},
+
+		{"jwt", "Authorization: Bearer " + jwt, "jwt"},
+		{"jwt low entropy", "ey" + strings.Repeat("a", 20) + ".ey" + strings.Repeat("a", 20) + "." + strings.Repeat("a", 10), ""},
+
+		{"stripe live key", `stripe.Key = "` + "sk_live_" + random(alphabet, 24) + `"`, "stripe-access-token"},
+		{"stripe restricted key", "rk_test_" + random(alphabet, 24), "stripe-access-token"},
+		{"stripe key low entropy", "sk_live_" + strings.Repeat("a", 30), ""},
+		{"stripe publishable key", "pk_live_" + random(alphabet, 24), ""},
+
+		{"gcp service account", `  "private_key_id": "` + random(hexChars, 40) + `",`, "gcp-service-account"},
+		{"gcp service account short id", `  "private_key_id": "` + random(hexChars, 39) + `",`, ""},
+		{"gcp api key", "key=" + "AIza" + random(alphabet+"_-", 35), "gcp-api-key"},
+
+		{"private key", "-----BEGIN " + "RSA PRIVATE KEY-----", "private-key"},
+		{"public key", "-----BEGIN " + "PUBLIC KEY-----", ""},
+		{"generic api key", `api_key = "` + random(alphabet, 32) + `"`, "generic-api-key"},
+		{"generic api key placeholder", `api_key = "your_api_key_goes_here_1234"`, ""},
+		{"plain text", "nothing to see here", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Default().ScanContent("config/app.env", []byte(tt.line+"\n"))
+			if tt.want == "" {
+				if len(got) != 0 {
+					t.Errorf("%q: unexpected findings %v", tt.line, rules(got))
+				}
+				return
+			}
+			if len(got) != 1 || got[0].RuleID != tt.want {
+				t.Fatalf("%q: findings %v, want [%s]", tt.line, rules(got), tt.want)
+			}
+			if got[0].Line != 1 || got[0].File != "config/app.env" {
+				t.Errorf("position = %s:%d", got[0].File, got[0].Line)
+			}
+			if strings.Contains(got[0].Description, tt.line[len(tt.line)-12:]) {
+				t.Errorf("description leaks the secret: %s", got[0].Description)
+			}
+		})
+	}
+}
+
+func TestSeverityAndAllowlistedPaths(t *testing.T) {
+	token := "ghp_" + random(alphabet, 36)
+	jwt := "ey" + random(alphabet, 20) + ".ey" + random(alphabet, 20) + "." + random(alphabet, 20)
+
+	got := Default().ScanContent("app.js", []byte(token+"\n\n"+jwt+"\n"))
+	if len(got) != 2 || got[0].Severity != core.SevCritical || got[1].Severity != core.SevHigh || got[1].Line != 3 {
+		t.Errorf("findings = %+v", got)
+	}
+	for _, path := range []string{"web/package-lock.json", "go.sum", ".git/config"} {
+		if got := Default().ScanContent(path, []byte(token)); len(got) != 0 {
+			t.Errorf("%s: globally allowlisted path has findings %v", path, rules(got))
+		}
+	}
+}
+
+// Секрет в середине строки минифицированного JS длиной больше 10 КБ
+func TestLongLine(t *testing.T) {
+	token := "ghp_" + random(alphabet, 36)
+	filler := strings.Repeat("var a=function(b){return b+1};", 200)
+	line := filler + `const t="` + token + `";` + filler
+	if len(line) < 10<<10 {
+		t.Fatalf("line is %d bytes", len(line))
+	}
+	got := Default().ScanContent("dist/app.min.js", []byte("// header\n"+line+"\n"))
+	if len(got) != 1 || got[0].RuleID != "github-pat" || got[0].Line != 2 {
+		t.Errorf("findings = %+v", got)
+	}
+}
+
+func TestEntropy(t *testing.T) {
+	tests := []struct {
+		s    string
+		want float64
+	}{
+		{"", 0},
+		{"aaaa", 0},
+		{"ab", 1},
+		{"abcd", 2},
+		{"aabb", 1},
+		{"01234567", 3},
+		{"0123456789abcdef", 4},
+	}
+	for _, tt := range tests {
+		if got := Entropy(tt.s); got != tt.want {
+			t.Errorf("Entropy(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestEntropyThreshold(t *testing.T) {
+	pack := &Pack{Rules: []Rule{{ID: "token", Regex: `token=(\w+)`, Entropy: 3}}}
+	e, err := NewEngine(pack)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		secret string
+		found  bool
+	}{
+		{"01234567", true}, // Ровно 3 бита: порог включительный
+		{"0123456", false}, // log2(7) < 3
+		{"aaaaaaaaaaaaaaaaaaaa", false},
+		{random(alphabet, 24), true},
+	}
+	for _, tt := range tests {
+		if got := e.ScanContent("f", []byte("token="+tt.secret)); (len(got) == 1) != tt.found {
+			t.Errorf("token=%s: findings %v, want found = %v", tt.secret, rules(got), tt.found)
+		}
+	}
+}
+
+// gitleaks.toml пользователя: неизвестные ключи (tags), allowlist правила и глобальный
+const userPack = `title = "company rules"
+
+[extend]
+useDefault = %s
+
+[allowlist]
+paths = ['''^fixtures/''']
+
+[[rules]]
+id = "company-token"
+description = "Company internal token"
+regex = '''\b(cmp_[0-9a-f]{32})\b'''
+secretGroup = 1
+keywords = ["cmp_"]
+tags = ["internal"]
+severity = "medium"
+
+  [rules.allowlist]
+  regexTarget = "line"
+  regexes = ['''#\s*test-only''']
+
+[[rules]]
+id = "github-pat"
+description = "GitHub token (company override)"
+regex = '''\bghp_[0-9a-zA-Z]{36}\b'''
+keywords = ["ghp_"]
+severity = "LOW"
+`
+
+func TestUserGitleaksPack(t *testing.T) {
+	company := "cmp_" + random("0123456789abcdef", 32)
+	stripe := "sk_live_" + random(alphabet, 24)
+	github := "ghp_" + random(alphabet, 36)
+	content := []byte(company + "\n" + company + " # test-only\n" + stripe + "\n" + github + "\n")
+
+	for _, useDefault := range []bool{true, false} {
+		root := t.TempDir()
+		data := strings.Replace(userPack, "%s", map[bool]string{true: "true", false: "false"}[useDefault], 1)
+		if err := os.WriteFile(filepath.Join(root, "gitleaks.toml"), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		target := scanner.Target{Root: root, Config: &config.Project{}, Options: map[string]string{"rules": "gitleaks.toml"}}
+		e, err := engineFor(target)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := e.ScanContent("src/app.go", content)
+		want := []string{"company-token", "github-pat"}
+		if useDefault {
+			want = []string{"company-token", "stripe-access-token", "github-pat"}
+		}
+		if strings.Join(rules(got), ",") != strings.Join(want, ",") {
+			t.Fatalf("useDefault=%v: findings %v, want %v", useDefault, rules(got), want)
+		}
+		if got[0].Severity != core.SevMedium || got[0].Line != 1 || !strings.Contains(got[0].Description, "cmp_") {
+			t.Errorf("company-token = %+v", got[0])
+		}
+		if last := got[len(got)-1]; last.Severity != core.SevLow || last.Message != "GitHub token (company override) found" {
+			t.Errorf("github-pat override = %+v", last)
+		}
+		if got := e.ScanContent("fixtures/tokens.txt", content); len(got) != 0 {
+			t.Errorf("useDefault=%v: allowlisted path has findings %v", useDefault, rules(got))
+		}
+	}
+}
+
+func TestNewEngineErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		pack Pack
+	}{
+		{"duplicate id", Pack{Rules: []Rule{{ID: "a", Regex: "a"}, {ID: "a", Regex: "b"}}}},
+		{"no id", Pack{Rules: []Rule{{Regex: "a"}}}},
+		{"no regex or path", Pack{Rules: []Rule{{ID: "a"}}}},
+		{"bad regex", Pack{Rules: []Rule{{ID: "a", Regex: "("}}}},
+		{"secretGroup out of range", Pack{Rules: []Rule{{ID: "a", Regex: "(a)", SecretGroup: 2}}}},
+		{"bad severity", Pack{Rules: []Rule{{ID: "a", Regex: "a", Severity: "URGENT"}}}},
+		{"bad regexTarget", Pack{Allowlist: Allowlist{RegexTarget: "file"}}},
+	}
+	for _, tt := range tests {
+		if _, err := NewEngine(&tt.pack); err == nil {
+			t.Errorf("%s: NewEngine succeeded", tt.name)
+		}
+	}
+}
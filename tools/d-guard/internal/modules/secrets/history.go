@@ -0,0 +1,44 @@
+package secrets
+
+import (
+	"context"
+
+	"github.com/devos-os/d-guard/internal/core"
+	"github.com/devos-os/d-guard/internal/git"
+	"github.com/devos-os/d-guard/internal/scanner"
+	"github.com/devos-os/d-guard/internal/suppress"
+)
+
+// ScanHistory ищет секреты в строках, добавленных коммитами из rng
+// (пусто — все ветки). Находит и секреты, удаленные позже: из истории
+// они никуда не делись и должны быть отозваны.
+func ScanHistory(ctx context.Context, t scanner.Target, rng string) ([]core.Issue, error) {
+	engine, err := engineFor(t)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []core.Issue
+	err = git.WalkHistory(ctx, t.Root, rng, func(c git.Commit, p git.FilePatch) error {
+		lines := make([]Line, len(p.Lines))
+		text := make(map[int]string, len(p.Lines))
+		for i, l := range p.Lines {
+			lines[i] = Line{N: l.N, Text: l.Text}
+			text[l.N] = l.Text
+		}
+
+		for _, is := range engine.ScanLines(p.Path, lines) {
+			is.Commit, is.Author, is.Date = c.SHA, c.Author, c.Date
+			// d-guard:ignore на той же или предыдущей строке того же коммита
+			if reason, ok := suppress.Line(text[is.Line], is.RuleID); ok {
+				is.Suppressed, is.Justification = true, reason
+			} else if reason, ok := suppress.LineAbove(text[is.Line-1], is.RuleID); ok {
+				is.Suppressed, is.Justification = true, reason
+			}
+			is.Suggestion = "Revoke this secret: it stays in git history even after the file is changed"
+			issues = append(issues, is)
+		}
+		return ctx.Err()
+	})
+	return issues, err
+}
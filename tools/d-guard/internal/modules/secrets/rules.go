@@ -0,0 +1,235 @@
+package secrets
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/devos-os/d-guard/internal/core"
+	"gopkg.in/yaml.v3"
+)
+
+// Встроенный набор правил (формат gitleaks.toml)
+//
+//go:embed default.toml
+var defaultPack []byte
+
+// Pack — набор правил в формате gitleaks (TOML) или его YAML-эквивалент.
+// Severity — расширение d-guard, gitleaks это поле игнорирует.
+type Pack struct {
+	Extend    Extend    `toml:"extend" yaml:"extend"`
+	Allowlist Allowlist `toml:"allowlist" yaml:"allowlist"` // Глобальный allowlist
+	Rules     []Rule    `toml:"rules" yaml:"rules"`
+}
+
+// Extend: useDefault = true — дополнить встроенный набор, иначе заменить его (как в gitleaks)
+type Extend struct {
+	UseDefault bool `toml:"useDefault" yaml:"useDefault"`
+}
+
+// Rule — правило поиска секрета
+type Rule struct {
+	ID          string        `toml:"id" yaml:"id"`
+	Description string        `toml:"description" yaml:"description"`
+	Regex       string        `toml:"regex" yaml:"regex"`
+	SecretGroup int           `toml:"secretGroup" yaml:"secretGroup"` // Группа regex с самим секретом (0 — первая непустая)
+	Entropy     float64       `toml:"entropy" yaml:"entropy"`         // Минимальная энтропия Шеннона секрета
+	Keywords    []string      `toml:"keywords" yaml:"keywords"`       // Быстрый фильтр: строка должна содержать одно из слов
+	Path        string        `toml:"path" yaml:"path"`               // Regex пути файла
+	Severity    core.Severity `toml:"severity" yaml:"severity"`       // Пусто = CRITICAL
+	Allowlist   Allowlist     `toml:"allowlist" yaml:"allowlist"`
+}
+
+// Allowlist — исключения: совпадение по любому полю отменяет находку
+type Allowlist struct {
+	Description string   `toml:"description" yaml:"description"`
+	Regexes     []string `toml:"regexes" yaml:"regexes"`
+	RegexTarget string   `toml:"regexTarget" yaml:"regexTarget"` // secret (по умолчанию), match или line
+	Paths       []string `toml:"paths" yaml:"paths"`
+	StopWords   []string `toml:"stopwords" yaml:"stopwords"`
+}
+
+// LoadPack читает набор правил; формат определяется по расширению (.toml, .yaml/.yml)
+func LoadPack(path string) (*Pack, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var p *Pack
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		p, err = parseYAML(data)
+	default:
+		p, err = parseTOML(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// DefaultPack возвращает встроенный набор правил
+func DefaultPack() *Pack {
+	p, err := parseTOML(defaultPack)
+	if err != nil {
+		// Встроенный набор проверяется при сборке — ошибка здесь означает баг
+		panic(fmt.Sprintf("secrets: invalid embedded rule pack: %v", err))
+	}
+	return p
+}
+
+func parseTOML(data []byte) (*Pack, error) {
+	p := &Pack{}
+	md, err := toml.Decode(string(data), p)
+	if err != nil {
+		return nil, err
+	}
+	// Неизвестные ключи gitleaks (tags, secretGroup у allowlist и т.д.) не ошибка
+	_ = md.Undecoded()
+	return p, nil
+}
+
+func parseYAML(data []byte) (*Pack, error) {
+	p := &Pack{}
+	if err := yaml.NewDecoder(bytes.NewReader(data)).Decode(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Merge добавляет правила другого набора; правило с тем же ID заменяется
+func (p *Pack) Merge(other *Pack) {
+	idx := map[string]int{}
+	for i, r := range p.Rules {
+		idx[r.ID] = i
+	}
+	for _, r := range other.Rules {
+		if i, ok := idx[r.ID]; ok {
+			p.Rules[i] = r
+			continue
+		}
+		idx[r.ID] = len(p.Rules)
+		p.Rules = append(p.Rules, r)
+	}
+	p.Allowlist.Regexes = append(p.Allowlist.Regexes, other.Allowlist.Regexes...)
+	p.Allowlist.Paths = append(p.Allowlist.Paths, other.Allowlist.Paths...)
+	p.Allowlist.StopWords = append(p.Allowlist.StopWords, other.Allowlist.StopWords...)
+}
+
+// compiledRule — правило с предкомпилированными regex
+type compiledRule struct {
+	Rule
+	re        *regexp.Regexp
+	path      *regexp.Regexp
+	keywords  []string // В нижнем регистре
+	allow     *compiledAllowlist
+	severity  core.Severity
+}
+
+type compiledAllowlist struct {
+	regexes   []*regexp.Regexp
+	target    string
+	paths     []*regexp.Regexp
+	stopWords []string // В нижнем регистре
+}
+
+func compileRule(r Rule) (*compiledRule, error) {
+	if r.ID == "" {
+		return nil, fmt.Errorf("rule without id")
+	}
+	if r.Regex == "" && r.Path == "" {
+		return nil, fmt.Errorf("rule %s: regex or path is required", r.ID)
+	}
+	c := &compiledRule{Rule: r, severity: core.SevCritical}
+	var err error
+	if r.Regex != "" {
+		if c.re, err = regexp.Compile(r.Regex); err != nil {
+			return nil, fmt.Errorf("rule %s: regex: %w", r.ID, err)
+		}
+		if r.SecretGroup > c.re.NumSubexp() {
+			return nil, fmt.Errorf("rule %s: secretGroup %d, regex has %d groups", r.ID, r.SecretGroup, c.re.NumSubexp())
+		}
+	}
+	if r.Path != "" {
+		if c.path, err = regexp.Compile(r.Path); err != nil {
+			return nil, fmt.Errorf("rule %s: path: %w", r.ID, err)
+		}
+	}
+	if r.Severity != "" {
+		if c.severity, err = core.ParseSeverity(string(r.Severity)); err != nil {
+			return nil, fmt.Errorf("rule %s: %w", r.ID, err)
+		}
+	}
+	for _, k := range r.Keywords {
+		c.keywords = append(c.keywords, strings.ToLower(k))
+	}
+	if c.allow, err = compileAllowlist(r.Allowlist); err != nil {
+		return nil, fmt.Errorf("rule %s: allowlist: %w", r.ID, err)
+	}
+	return c, nil
+}
+
+func compileAllowlist(a Allowlist) (*compiledAllowlist, error) {
+	c := &compiledAllowlist{target: a.RegexTarget}
+	switch c.target {
+	case "", "secret", "match", "line":
+	default:
+		return nil, fmt.Errorf("regexTarget %q (expected secret, match or line)", a.RegexTarget)
+	}
+	for _, s := range a.Regexes {
+		re, err := regexp.Compile(s)
+		if err != nil {
+			return nil, err
+		}
+		c.regexes = append(c.regexes, re)
+	}
+	for _, s := range a.Paths {
+		re, err := regexp.Compile(s)
+		if err != nil {
+			return nil, err
+		}
+		c.paths = append(c.paths, re)
+	}
+	for _, w := range a.StopWords {
+		c.stopWords = append(c.stopWords, strings.ToLower(w))
+	}
+	return c, nil
+}
+
+// pathAllowed: false — файл исключен allowlist.paths
+func (a *compiledAllowlist) pathAllowed(path string) bool {
+	for _, re := range a.paths {
+		if re.MatchString(path) {
+			return false
+		}
+	}
+	return true
+}
+
+// allowed сообщает, что находка попадает под исключение
+func (a *compiledAllowlist) allowed(secret, match, line string) bool {
+	target := secret
+	switch a.target {
+	case "match":
+		target = match
+	case "line":
+		target = line
+	}
+	for _, re := range a.regexes {
+		if re.MatchString(target) {
+			return true
+		}
+	}
+	lower := strings.ToLower(secret)
+	for _, w := range a.stopWords {
+		if strings.Contains(lower, w) {
+			return true
+		}
+	}
+	return false
+}
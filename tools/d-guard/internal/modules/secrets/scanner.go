@@ -1,72 +1,126 @@
 package secrets
 
 import (
-	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
-	"os"
-	"regexp"
+	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/devos-os/d-guard/internal/core"
+	"github.com/devos-os/d-guard/internal/scanner"
 )
 
-var rules = []struct {
-	Name        string
-	Regex       string
-	Description string
-}{
-	{
-		Name:        "Generic API Key",
-		// Улучшенный regex: ищет assignment с длинной строкой
-		Regex:       `(?i)(api_key|apikey|secret|token)["']?\s*[:=]\s*["'][a-zA-Z0-9_=-]{20,}["']`,
-		Description: "Detected potential hardcoded API key",
-	},
-	{
-		Name:        "Private Key",
-		Regex:       `-----BEGIN ((EC|PGP|DSA|RSA|OPENSSH) )?PRIVATE KEY( BLOCK)?-----`,
-		Description: "Found private key block",
-	},
+func init() { scanner.Register(secretsScanner{}) }
+
+// secretsScanner — нативный fallback, если gitleaks не установлен или отключен
+type secretsScanner struct{}
+
+func (secretsScanner) Name() string        { return "secrets" }
+func (secretsScanner) FallbackFor() string { return "gitleaks" }
+
+func (secretsScanner) Applicable(t scanner.Target) bool { return len(t.Files) > 0 }
+func (secretsScanner) SkipReason(t scanner.Target) string { return "no files to scan" }
+
+// rules — путь к набору правил (gitleaks TOML или YAML). С [extend] useDefault = true
+// дополняет встроенный набор, иначе заменяет его.
+func (secretsScanner) Options() []string { return []string{"rules"} }
+
+func (secretsScanner) Scan(ctx context.Context, t scanner.Target) ([]core.Issue, error) {
+	engine, err := engineFor(t)
+	if err != nil {
+		return nil, err
+	}
+	return scanner.EachFile(ctx, t, t.Files, engine.ScanContent)
 }
 
-func Scan(files []string) []core.Issue {
-	var issues []core.Issue
+var (
+	defaultOnce   sync.Once
+	defaultEngine *Engine
+)
+
+// Default возвращает движок со встроенным набором правил (компилируется один раз)
+func Default() *Engine {
+	defaultOnce.Do(func() {
+		e, err := NewEngine(DefaultPack())
+		if err != nil {
+			panic(fmt.Sprintf("secrets: invalid embedded rule pack: %v", err))
+		}
+		defaultEngine = e
+	})
+	return defaultEngine
+}
+
+// RulesHash — отпечаток итогового набора правил (встроенные, файл, .d-guard.yaml)
+func (secretsScanner) RulesHash(t scanner.Target) (string, error) {
+	pack, err := packFor(t)
+	if err != nil || pack == nil {
+		return "builtin", err
+	}
+	data, err := json.Marshal(pack)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
 
-	for _, path := range files {
-		// Игнорируем сам бинарник d-guard и go.sum/mod
-		if strings.HasSuffix(path, "/d-guard") && !strings.HasSuffix(path, ".go") { continue }
-		if strings.HasSuffix(path, "go.sum") || strings.HasSuffix(path, "go.mod") { continue }
-		// Игнорируем папку .git
-		if strings.Contains(path, "/.git/") { continue }
+// engineFor собирает движок: встроенный набор, файл из scanners.secrets.rules
+// и secrets.rules из .d-guard.yaml (уже провалидированы)
+func engineFor(t scanner.Target) (*Engine, error) {
+	pack, err := packFor(t)
+	if err != nil {
+		return nil, err
+	}
+	if pack == nil {
+		return Default(), nil
+	}
+	engine, err := NewEngine(pack)
+	if err != nil {
+		return nil, fmt.Errorf("secrets rules: %w", err)
+	}
+	return engine, nil
+}
 
-		f, err := os.Open(path)
-		if err != nil { continue }
-		
-		scanner := bufio.NewScanner(f)
-		lineNum := 0
-		
-		for scanner.Scan() {
-			lineNum++
-			line := scanner.Text()
-			
-			// Проверка на длину строки (чтобы не сканировать минифицированный JS/JSON)
-			if len(line) > 1000 { continue }
+// packFor — набор правил цели (nil — только встроенный, движок берется из Default)
+func packFor(t scanner.Target) (*Pack, error) {
+	path := t.Option("rules", "")
+	custom := t.Config.Secrets.Rules
+	if path == "" && len(custom) == 0 {
+		return nil, nil
+	}
 
-			for _, rule := range rules {
-				re := regexp.MustCompile(rule.Regex)
-				if re.MatchString(line) {
-					issues = append(issues, core.Issue{
-						Scanner:     "Secrets",
-						Severity:    core.SevCritical,
-						Message:     fmt.Sprintf("%s found", rule.Name),
-						File:        path,
-						Line:        lineNum,
-						Description: rule.Description,
-						Suggestion:  "Move secret to environment variables or Vault",
-					})
-				}
-			}
+	pack := DefaultPack()
+	if path != "" {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(t.Root, path)
+		}
+		user, err := LoadPack(path)
+		if err != nil {
+			return nil, fmt.Errorf("secrets rule pack: %w", err)
+		}
+		if !user.Extend.UseDefault {
+			pack = &Pack{}
 		}
-		f.Close()
+		pack.Merge(user)
 	}
-	return issues
-}
\ No newline at end of file
+
+	extra := &Pack{}
+	for _, r := range custom {
+		extra.Rules = append(extra.Rules, Rule{
+			ID:          r.ID,
+			Description: r.Description,
+			Regex:       r.Regex,
+			SecretGroup: r.SecretGroup,
+			Entropy:     r.Entropy,
+			Keywords:    r.Keywords,
+			Severity:    core.Severity(strings.ToUpper(r.Severity)),
+			Allowlist:   Allowlist{Regexes: r.Allowlist},
+		})
+	}
+	pack.Merge(extra)
+	return pack, nil
+}
@@ -1,89 +1,498 @@
 package internal
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/devos-os/d-guard/internal/cache"
+	"github.com/devos-os/d-guard/internal/config"
 	"github.com/devos-os/d-guard/internal/core"
 	"github.com/devos-os/d-guard/internal/git"
-	"github.com/devos-os/d-guard/internal/modules/code"      // Наш нативный
-	"github.com/devos-os/d-guard/internal/modules/container" // Наш нативный
-	"github.com/devos-os/d-guard/internal/modules/external"  // Trivy (старый)
-	"github.com/devos-os/d-guard/internal/modules/secrets"   // Наш нативный (Fallback)
-	"github.com/devos-os/d-guard/internal/tools"             // Новые (Gitleaks, Semgrep)
+	"github.com/devos-os/d-guard/internal/proc"
+	"github.com/devos-os/d-guard/internal/scanner"
+	"github.com/devos-os/d-guard/internal/source"
+	"github.com/devos-os/d-guard/internal/suppress"
+
+	// Модули регистрируют себя в scanner-реестре через init()
+	_ "github.com/devos-os/d-guard/internal/modules/code"      // Наш нативный
+	_ "github.com/devos-os/d-guard/internal/modules/container" // Наш нативный
+	_ "github.com/devos-os/d-guard/internal/modules/deps"      // Наш нативный (SCA по OSV, Fallback для trivy)
+	_ "github.com/devos-os/d-guard/internal/modules/external"  // Trivy (старый)
+	_ "github.com/devos-os/d-guard/internal/modules/gosast"    // Наш нативный (SAST для Go, Fallback для semgrep)
+	_ "github.com/devos-os/d-guard/internal/modules/iac"       // Наш нативный (compose, Kubernetes)
+	_ "github.com/devos-os/d-guard/internal/modules/licenses"  // Наш нативный (политика лицензий зависимостей)
+	"github.com/devos-os/d-guard/internal/modules/secrets"     // Наш нативный (Fallback, --history)
+	_ "github.com/devos-os/d-guard/internal/tools"             // Новые (Gitleaks, Semgrep)
 )
 
-func RunAll(cfg core.Config) []core.Issue {
+// Result итог запуска оркестратора
+type Result struct {
+	Root    string
+	Files   []string // Просканированные файлы (при ScanAll — все файлы репозитория)
+	ScanAll bool
+	Issues  []core.Issue
+	Project *config.Project
+	Runs    []core.ScannerRun     // Статус каждого сканера (ok/skipped/failed)
+	Hunks   map[string]*git.Hunks // --changed-lines: измененные строки (nil — режим выключен)
+	Info    core.ScanInfo         // Метаданные для отчетов (commit, режим, время)
+	FS      source.FS             // Просканированное содержимое (staged blobs для --staged, nil — рабочая копия)
+}
+
+// Lines — построчный доступ к просканированному содержимому (для baseline и подавлений)
+func (r *Result) Lines() *source.Lines {
+	if r.FS == nil {
+		return source.NewLines(r.Root)
+	}
+	return source.NewLinesFS(r.Root, r.FS)
+}
+
+// Failed возвращает сканеры, завершившиеся ошибкой или по таймауту
+func (r *Result) Failed() []core.ScannerRun {
+	var failed []core.ScannerRun
+	for _, run := range r.Runs {
+		if run.Incomplete() {
+			failed = append(failed, run)
+		}
+	}
+	return failed
+}
+
+// LoadProject читает .d-guard.yaml (или --config) и сверяет его с реестром сканеров
+func LoadProject(root, path string) (*config.Project, error) {
+	project, err := config.Load(root, path)
+	if err != nil {
+		return nil, err
+	}
+	if err := project.CheckScanners(scanner.Known()); err != nil {
+		if project.Path != "" {
+			return nil, fmt.Errorf("%s: %w", project.Path, err)
+		}
+		return nil, err
+	}
+	return project, nil
+}
+
+// RunAll запускает выбранные сканеры параллельно. Отмена ctx (Ctrl+C) прерывает
+// все сканеры, --timeout ограничивает каждый из них отдельно.
+func RunAll(ctx context.Context, cfg core.Config) (*Result, error) {
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 	var allIssues []core.Issue
+	var runs []core.ScannerRun
+	started := time.Now()
 
-	// 1. Определяем файлы
 	root, _ := git.GetRepoRoot()
+	project, err := LoadProject(root, cfg.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// CLI важнее конфига: --only может включить сканер, выключенный в .d-guard.yaml
+	skip := append([]string{}, cfg.Skip...)
+	for _, name := range project.Disabled() {
+		if !contains(cfg.Only, name) {
+			skip = append(skip, name)
+		}
+	}
+	selected, err := scanner.Select(cfg.Only, skip)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTimeouts(cfg.Timeouts); err != nil {
+		return nil, err
+	}
+
+	// 1. Определяем файлы
 	var files []string
-	if cfg.ScanAll {
-		// Для scan-all передаем пустой список, инструменты сами просканируют папку
-		files = []string{} 
+	info := core.ScanInfo{Root: root, Commit: git.HeadCommit(root), Branch: git.CurrentBranch(root), Started: started}
+	if len(cfg.Files) > 0 {
+		files = cfg.Files
+		info.Mode = core.ModeFiles
+	} else if cfg.ScanAll {
+		// Внешние инструменты сами сканируют папку, нативным модулям нужен список файлов
+		if files, err = source.Walk(ctx, root); err != nil {
+			return nil, fmt.Errorf("walk %s: %w", root, err)
+		}
+		info.Mode = core.ModeAll
+	} else if cfg.Staged {
+		if files, err = git.GetStagedFiles(); err != nil {
+			return nil, err
+		}
+		info.Mode = core.ModeStaged
 	} else {
 		base := cfg.BaseBranch
 		if cfg.IsCI && base == "" { base = "origin/main" }
 		files, _ = git.GetChangedFiles(cfg.IsCI, base)
-		if len(files) == 0 { return nil }
+		info.Mode = core.ModeLocal
+		if cfg.IsCI {
+			info.Mode, info.Base = core.ModeCI, base
+		}
+	}
+	files = filterFiles(files, root, project)
+	info.Files = len(files)
+
+	// Ханки имеют смысл только для diff-режимов (не --all и не явный список файлов)
+	var hunks map[string]*git.Hunks
+	if cfg.ChangedLines && info.Mode != core.ModeAll && info.Mode != core.ModeFiles {
+		if info.Mode == core.ModeStaged {
+			hunks, err = git.GetStagedLines()
+		} else {
+			hunks, err = git.GetChangedLines(cfg.IsCI, info.Base)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("changed lines: %w", err)
+		}
+	}
+	if !cfg.ScanAll && len(files) == 0 {
+		info.Duration = time.Since(started)
+		return &Result{Root: root, Project: project, Info: info}, nil
+	}
+
+	// --staged: нативные модули читают blobs из индекса, а не рабочую копию
+	var fsys source.FS
+	if info.Mode == core.ModeStaged {
+		blobs, err := git.StagedBlobs(root, files)
+		if err != nil {
+			return nil, err
+		}
+		fsys = source.Files(blobs)
+	}
+
+	target := scanner.Target{Root: root, Files: files, ScanAll: cfg.ScanAll, Config: project, FS: fsys, Offline: cfg.Offline, Workers: cfg.Workers}
+	plan, skipped := scanner.Plan(selected, target)
+
+	// Внешним инструментам staged-содержимое отдаем через временный checkout индекса
+	var checkout string
+	if fsys != nil && hasExternal(plan) {
+		if checkout, err = os.MkdirTemp("", "d-guard-staged-*"); err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(checkout)
+		if err := git.CheckoutIndex(root, checkout, files); err != nil {
+			return nil, err
+		}
 	}
 
 	fmt.Printf("🚀 Orchestrating security scan on %s (Parallel execution)...\n", root)
+	if project.Path != "" {
+		fmt.Printf("  ⚙️  Using config %s\n", project.Path)
+	}
+	for _, sk := range skipped {
+		fmt.Printf("  ⏭️  %s skipped (%s)\n", sk.Scanner.Name(), sk.Reason)
+		runs = append(runs, core.ScannerRun{Name: sk.Scanner.Name(), Status: core.StatusSkipped, Reason: sk.Reason})
+	}
 
-	// Хелпер для запуска
-	run := func(name string, fn func() []core.Issue) {
-		defer wg.Done()
-		fmt.Printf("  ⏳ Starting %s...\n", name)
-		res := fn()
-		mu.Lock()
-		allIssues = append(allIssues, res...)
-		mu.Unlock()
-		if len(res) > 0 {
-			fmt.Printf("  🔴 %s found %d issues\n", name, len(res))
-		} else {
-			fmt.Printf("  ✅ %s clean\n", name)
-		}
-	}
-
-	// --- Запуск потоков ---
-	
-	// 1. Gitleaks (Secrets)
-	wg.Add(1)
-	go run("Gitleaks", func() []core.Issue {
-		res := tools.RunGitleaks(root, files)
-		if res == nil { // Fallback to native if not installed
-			return secrets.Scan(files)
-		}
-		return res
-	})
-
-	// 2. Semgrep (SAST)
-	wg.Add(1)
-	go run("Semgrep", func() []core.Issue {
-		return tools.RunSemgrep(root, files)
-	})
-
-	// 3. Trivy (SCA & IaC)
-	wg.Add(1)
-	go run("Trivy", func() []core.Issue {
-		return external.RunTrivyFs(root) // Trivy лучше работает по всей папке
-	})
-
-	// 4. Native Docker (Runtime + Static)
-	wg.Add(1)
-	go run("Native Docker", func() []core.Issue {
-		return container.Scan(files)
-	})
-
-	// 5. Native Code Quality
-	wg.Add(1)
-	go run("Code Quality", func() []core.Issue {
-		return code.Scan(files)
-	})
+	// Кэш нативных сканеров: неизмененные файлы не сканируются повторно
+	var store *cache.Store
+	if !cfg.NoCache {
+		store = cache.Open(cache.ResultsDir())
+	}
+
+	for _, s := range plan {
+		wg.Add(1)
+		go func(s scanner.Scanner, t scanner.Target) {
+			defer wg.Done()
+			fmt.Printf("  ⏳ Starting %s...\n", s.Name())
+			if _, ok := s.(scanner.External); ok && checkout != "" {
+				t = inCheckout(t, checkout)
+			}
+			sctx, cancel := withTimeout(ctx, timeoutFor(cfg, s.Name()))
+			defer cancel()
+			var run core.ScannerRun
+			var res []core.Issue
+			if fs, ok := s.(scanner.FileScanner); ok && store != nil && len(t.Files) > 0 {
+				run, res = executeCached(sctx, fs, s, t, store)
+			} else {
+				run, res = execute(sctx, s, t)
+			}
+			if t.Root != root {
+				res = fromCheckout(res, t.Root, root)
+			}
+			mu.Lock()
+			allIssues = append(allIssues, res...)
+			runs = append(runs, run)
+			mu.Unlock()
+			switch run.Status {
+			case core.StatusFailed:
+				fmt.Printf("  ❌ %s failed: %s\n", s.Name(), run.Error)
+			case core.StatusTimeout:
+				fmt.Printf("  ⏱️  %s %s\n", s.Name(), run.Error)
+			case core.StatusSkipped:
+				fmt.Printf("  ⏭️  %s skipped (%s)\n", s.Name(), run.Reason)
+			default:
+				if len(res) > 0 {
+					fmt.Printf("  🔴 %s found %d issues (%s)\n", s.Name(), len(res), run.Duration.Round(time.Millisecond))
+				} else {
+					fmt.Printf("  ✅ %s clean (%s)\n", s.Name(), run.Duration.Round(time.Millisecond))
+				}
+			}
+		}(s, withOptions(target, project, s.Name()))
+	}
 
 	wg.Wait()
-	return allIssues
-}
\ No newline at end of file
+	sort.Slice(runs, func(i, j int) bool { return runs[i].Name < runs[j].Name })
+
+	// Внешние инструменты сканируют папку целиком — include/exclude применяем и к результатам
+	allIssues = applyProject(allIssues, root, project)
+	if hunks != nil {
+		before := len(allIssues)
+		allIssues = onChangedLines(allIssues, root, hunks)
+		fmt.Printf("  ✂️  %d issues outside changed lines hidden (--changed-lines)\n", before-len(allIssues))
+	}
+
+	// Inline-подавления (d-guard:ignore) применяются ко всем сканерам одинаково
+	result := &Result{Root: root, Files: files, ScanAll: cfg.ScanAll, Project: project, Runs: runs, Info: info, Hunks: hunks, FS: fsys}
+	result.Issues = suppress.Apply(allIssues, result.Lines())
+
+	result.Info.Duration = time.Since(started)
+	return result, nil
+}
+
+// RunHistory сканирует добавленные строки коммитов нативным движком секретов (--history).
+// Внешние сканеры смотрят только на рабочую копию и здесь не запускаются.
+func RunHistory(ctx context.Context, cfg core.Config) (*Result, error) {
+	started := time.Now()
+	root, err := git.GetRepoRoot()
+	if err != nil {
+		return nil, err
+	}
+	project, err := LoadProject(root, cfg.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+
+	info := core.ScanInfo{
+		Root: root, Commit: git.HeadCommit(root), Branch: git.CurrentBranch(root),
+		Mode: core.ModeHistory, Base: cfg.HistoryRange, Started: started,
+	}
+	desc := cfg.HistoryRange
+	if desc == "" {
+		desc = "all branches"
+	}
+	fmt.Printf("🕰️  Scanning git history of %s (%s) for secrets...\n", root, desc)
+	if project.Path != "" {
+		fmt.Printf("  ⚙️  Using config %s\n", project.Path)
+	}
+
+	target := withOptions(scanner.Target{Root: root, ScanAll: true, Config: project}, project, "secrets")
+	run := core.ScannerRun{Name: "secrets", Version: core.Version, Status: core.StatusOK}
+	hctx, cancel := withTimeout(ctx, timeoutFor(cfg, "secrets"))
+	defer cancel()
+	issues, err := secrets.ScanHistory(hctx, target, cfg.HistoryRange)
+	run.Duration = time.Since(started)
+	if errors.Is(hctx.Err(), context.DeadlineExceeded) {
+		run.Status, run.Error = core.StatusTimeout, fmt.Sprintf("timed out after %s", timeoutFor(cfg, "secrets"))
+		fmt.Printf("  ⏱️  history scan %s\n", run.Error)
+	} else if err != nil {
+		run.Status, run.Error = core.StatusFailed, err.Error()
+		fmt.Printf("  ❌ history scan failed: %s\n", run.Error)
+	}
+	for i := range issues {
+		issues[i].ScannerID = "secrets"
+	}
+	issues = applyProject(issues, root, project)
+	run.Issues = len(issues)
+	if err == nil {
+		fmt.Printf("  🔍 %d findings in history (%s)\n", len(issues), run.Duration.Round(time.Millisecond))
+	}
+
+	info.Duration = time.Since(started)
+	return &Result{Root: root, Issues: issues, Project: project, Runs: []core.ScannerRun{run}, Info: info}, nil
+}
+
+// execute запускает сканер и превращает (issues, error) в структурированный результат.
+// Сканер, не заметивший отмену ctx, не держит оркестратор: его результат отбрасывается.
+func execute(ctx context.Context, s scanner.Scanner, t scanner.Target) (core.ScannerRun, []core.Issue) {
+	run := core.ScannerRun{Name: s.Name(), Version: core.Version}
+	if v, ok := s.(scanner.Versioned); ok {
+		run.Version = v.Version(ctx)
+	}
+
+	type result struct {
+		issues []core.Issue
+		err    error
+	}
+	done := make(chan result, 1)
+	start := time.Now()
+	go func() {
+		res, err := s.Scan(ctx, t)
+		done <- result{res, err}
+	}()
+
+	var res []core.Issue
+	var err error
+	select {
+	case r := <-done:
+		res, err = r.issues, r.err
+	case <-ctx.Done():
+		// Даем внешнему инструменту завершиться после SIGTERM группе процессов
+		select {
+		case r := <-done:
+			res, err = r.issues, r.err
+		case <-time.After(proc.KillGrace):
+			res, err = nil, ctx.Err()
+		}
+	}
+	run.Duration = time.Since(start)
+
+	// Результаты прерванного прогона неполные: считаем их отсутствующими
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		run.Status, run.Error = core.StatusTimeout, fmt.Sprintf("timed out after %s", run.Duration.Round(time.Second))
+		return run, nil
+	case context.Canceled:
+		run.Status, run.Error = core.StatusFailed, "interrupted"
+		return run, nil
+	}
+
+	var skip *scanner.SkipError
+	switch {
+	case errors.As(err, &skip):
+		run.Status, run.Reason = core.StatusSkipped, skip.Reason
+		return run, nil
+	case err != nil:
+		// Частичные результаты сохраняем, но прогон помечаем как неполный
+		run.Status, run.Error = core.StatusFailed, err.Error()
+	default:
+		run.Status = core.StatusOK
+	}
+
+	for i := range res {
+		res[i].ScannerID = s.Name()
+	}
+	run.Issues = len(res)
+	return run, res
+}
+
+// timeoutFor — лимит сканера: --timeout NAME=DURATION или общий --timeout
+func timeoutFor(cfg core.Config, name string) time.Duration {
+	if d, ok := cfg.Timeouts[name]; ok {
+		return d
+	}
+	return cfg.Timeout
+}
+
+// withTimeout: нулевой лимит — без ограничения (только отмена родителя)
+func withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+// checkTimeouts: лимит для неизвестного сканера — скорее всего опечатка
+func checkTimeouts(timeouts map[string]time.Duration) error {
+	known := scanner.Names()
+	for name := range timeouts {
+		if !contains(known, name) {
+			return fmt.Errorf("--timeout %s: unknown scanner %q (available: %s)", name, name, strings.Join(known, ", "))
+		}
+	}
+	return nil
+}
+
+func hasExternal(plan []scanner.Scanner) bool {
+	for _, s := range plan {
+		if _, ok := s.(scanner.External); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// inCheckout переносит target во временный checkout индекса
+func inCheckout(t scanner.Target, dir string) scanner.Target {
+	files := make([]string, 0, len(t.Files))
+	for _, f := range t.Files {
+		files = append(files, filepath.Join(dir, relPath(t.Root, f)))
+	}
+	t.Root, t.Files, t.FS = dir, files, nil
+	return t
+}
+
+// fromCheckout возвращает путям проблем корень настоящего репозитория
+func fromCheckout(issues []core.Issue, dir, root string) []core.Issue {
+	for i := range issues {
+		f := issues[i].File
+		if rel, err := filepath.Rel(dir, f); err == nil && filepath.IsAbs(f) && !strings.HasPrefix(rel, "..") {
+			issues[i].File = filepath.Join(root, rel)
+		}
+	}
+	return issues
+}
+
+func withOptions(t scanner.Target, project *config.Project, name string) scanner.Target {
+	t.Options = project.Options(name)
+	return t
+}
+
+func filterFiles(files []string, root string, project *config.Project) []string {
+	var kept []string
+	for _, f := range files {
+		if project.PathAllowed(relPath(root, f)) {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// applyProject: фильтр путей и переопределение уровней (severity: {rule: LEVEL})
+func applyProject(issues []core.Issue, root string, project *config.Project) []core.Issue {
+	var kept []core.Issue
+	for _, is := range issues {
+		if is.File != "" && !project.PathAllowed(relPath(root, is.File)) {
+			continue
+		}
+		if sev, ok := project.Override(is.RuleID); ok {
+			is.Severity = sev
+		}
+		kept = append(kept, is)
+	}
+	return kept
+}
+
+// onChangedLines оставляет проблемы на измененных строках. Проблемы уровня файла
+// (без строки) остаются, если файл изменен; без файла (runtime) — всегда.
+func onChangedLines(issues []core.Issue, root string, hunks map[string]*git.Hunks) []core.Issue {
+	var kept []core.Issue
+	for _, is := range issues {
+		if is.File == "" {
+			kept = append(kept, is)
+			continue
+		}
+		h, ok := hunks[relPath(root, is.File)]
+		if !ok {
+			continue
+		}
+		if is.Line <= 0 || h.Contains(is.Line) {
+			kept = append(kept, is)
+		}
+	}
+	return kept
+}
+
+func relPath(root, path string) string {
+	if !filepath.IsAbs(path) {
+		return filepath.ToSlash(path)
+	}
+	if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(path)
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
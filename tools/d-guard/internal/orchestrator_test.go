@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/devos-os/d-guard/internal/core"
+	"github.com/devos-os/d-guard/internal/git"
+)
+
+func TestOnChangedLines(t *testing.T) {
+	root := t.TempDir()
+	abs := func(p string) string { return filepath.Join(root, p) }
+	hunks := map[string]*git.Hunks{
+		"go.sum":         {Ranges: []git.LineRange{{Start: 40, End: 41}}},
+		"app/main.go":    {Ranges: []git.LineRange{{Start: 10, End: 12}}},
+		"new/handler.go": {Whole: true},
+	}
+
+	tests := []struct {
+		name string
+		is   core.Issue
+		kept bool
+	}{
+		{"line in hunk", core.Issue{File: abs("app/main.go"), Line: 11}, true},
+		{"hunk boundary", core.Issue{File: abs("app/main.go"), Line: 12}, true},
+		{"line outside hunk", core.Issue{File: abs("app/main.go"), Line: 13}, false},
+		{"relative path", core.Issue{File: "app/main.go", Line: 10}, true},
+		{"untracked file", core.Issue{File: abs("new/handler.go"), Line: 500}, true},
+		{"unchanged file", core.Issue{File: abs("other.go"), Line: 1}, false},
+		// Trivy: уязвимость зависимости относится ко всему lockfile
+		{"file-level issue, changed file", core.Issue{Scanner: "Trivy (Vuln)", File: "go.sum", Line: 0}, true},
+		{"file-level issue, unchanged file", core.Issue{Scanner: "Trivy (Vuln)", File: "go.mod", Line: 0}, false},
+		{"no file (runtime)", core.Issue{Scanner: "Runtime", Message: "privileged container"}, true},
+	}
+
+	var issues []core.Issue
+	for _, tt := range tests {
+		issues = append(issues, tt.is)
+	}
+	kept := map[string]bool{}
+	for _, is := range onChangedLines(issues, root, hunks) {
+		for _, tt := range tests {
+			if tt.is == is {
+				kept[tt.name] = true
+			}
+		}
+	}
+	for _, tt := range tests {
+		if kept[tt.name] != tt.kept {
+			t.Errorf("%s: kept = %v, want %v", tt.name, kept[tt.name], tt.kept)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package osv
+
+import (
+	"math"
+	"strings"
+)
+
+// CVSS3 считает базовую оценку по вектору CVSS:3.0/3.1 (формулы из спецификации FIRST)
+func CVSS3(vector string) (float64, bool) {
+	if !strings.HasPrefix(vector, "CVSS:3.") {
+		return 0, false
+	}
+	m := map[string]string{}
+	for _, part := range strings.Split(vector, "/")[1:] {
+		if k, v, ok := strings.Cut(part, ":"); ok {
+			m[k] = v
+		}
+	}
+
+	weights := map[string]map[string]float64{
+		"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+		"AC": {"L": 0.77, "H": 0.44},
+		"UI": {"N": 0.85, "R": 0.62},
+		"C":  {"H": 0.56, "L": 0.22, "N": 0},
+		"I":  {"H": 0.56, "L": 0.22, "N": 0},
+		"A":  {"H": 0.56, "L": 0.22, "N": 0},
+	}
+	val := map[string]float64{}
+	for k, table := range weights {
+		w, ok := table[m[k]]
+		if !ok {
+			return 0, false
+		}
+		val[k] = w
+	}
+
+	changed := m["S"] == "C"
+	if m["S"] != "U" && !changed {
+		return 0, false
+	}
+	pr := map[string]float64{"N": 0.85, "L": 0.62, "H": 0.27}
+	if changed {
+		pr = map[string]float64{"N": 0.85, "L": 0.68, "H": 0.5}
+	}
+	prW, ok := pr[m["PR"]]
+	if !ok {
+		return 0, false
+	}
+
+	iss := 1 - (1-val["C"])*(1-val["I"])*(1-val["A"])
+	impact := 6.42 * iss
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	if impact <= 0 {
+		return 0, true
+	}
+	exploitability := 8.22 * val["AV"] * val["AC"] * prW * val["UI"]
+	if changed {
+		return roundUp(math.Min(1.08*(impact+exploitability), 10)), true
+	}
+	return roundUp(math.Min(impact+exploitability, 10)), true
+}
+
+// roundUp — Roundup из CVSS 3.1: наименьшее число с одним знаком после запятой, не меньше x
+func roundUp(x float64) float64 {
+	i := int(math.Round(x * 100000))
+	if i%10000 == 0 {
+		return float64(i) / 100000
+	}
+	return (math.Floor(float64(i)/10000) + 1) / 10
+}
@@ -0,0 +1,155 @@
+package osv
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devos-os/d-guard/internal/cache"
+)
+
+// Локальное зеркало OSV: <dir>/meta.json и <dir>/<экосистема>.json (массив записей).
+// Сканирование читает только эти файлы и никогда не ходит в сеть.
+
+// ErrNoDB — зеркало еще не создано ('d-guard db update')
+var ErrNoDB = errors.New("OSV database not found")
+
+const metaFile = "meta.json"
+
+// Meta — сведения о содержимом зеркала
+type Meta struct {
+	Ecosystems map[string]EcosystemMeta `json:"ecosystems"`
+}
+
+type EcosystemMeta struct {
+	Updated time.Time `json:"updated"`
+	Source  string    `json:"source"`
+	Count   int       `json:"count"`
+}
+
+// DB — открытое зеркало; экосистемы загружаются лениво при первом запросе
+type DB struct {
+	Dir  string
+	Meta Meta
+
+	mu    sync.Mutex
+	index map[string]map[string][]*Vulnerability // экосистема -> имя пакета -> записи
+}
+
+// DefaultDir — ~/.cache/devos/d-guard/osv, рядом с остальными данными d-guard,
+// но вне кэша результатов (его устаревшие файлы удаляются)
+func DefaultDir() string {
+	return filepath.Join(cache.DefaultDir(), "osv")
+}
+
+// Open открывает зеркало в dir
+func Open(dir string) (*DB, error) {
+	data, err := os.ReadFile(filepath.Join(dir, metaFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w in %s (run 'd-guard db update')", ErrNoDB, dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	db := &DB{Dir: dir, index: map[string]map[string][]*Vulnerability{}}
+	if err := json.Unmarshal(data, &db.Meta); err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Join(dir, metaFile), err)
+	}
+	return db, nil
+}
+
+// Oldest — время самого старого обновления экосистемы (для предупреждения об устаревании)
+func (db *DB) Oldest() time.Time {
+	var oldest time.Time
+	for _, m := range db.Meta.Ecosystems {
+		if oldest.IsZero() || m.Updated.Before(oldest) {
+			oldest = m.Updated
+		}
+	}
+	return oldest
+}
+
+// Has сообщает, есть ли в зеркале экосистема
+func (db *DB) Has(ecosystem string) bool {
+	_, ok := db.Meta.Ecosystems[ecosystem]
+	return ok
+}
+
+// Query возвращает уязвимости, затрагивающие версию пакета
+func (db *DB) Query(ecosystem, name, version string) ([]Match, error) {
+	pkgs, err := db.load(ecosystem)
+	if err != nil {
+		return nil, err
+	}
+	name = NormalizeName(ecosystem, name)
+
+	var matches []Match
+	for _, v := range pkgs[name] {
+		for _, a := range v.Affected {
+			if a.Package.Ecosystem != ecosystem || NormalizeName(ecosystem, a.Package.Name) != name {
+				continue
+			}
+			if ok, desc, fixed := a.affects(version); ok {
+				matches = append(matches, Match{Vuln: v, Affected: desc, Fixed: fixed})
+				break
+			}
+		}
+	}
+	return matches, nil
+}
+
+func (db *DB) load(ecosystem string) (map[string][]*Vulnerability, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if pkgs, ok := db.index[ecosystem]; ok {
+		return pkgs, nil
+	}
+	pkgs := map[string][]*Vulnerability{}
+	db.index[ecosystem] = pkgs
+	if !db.Has(ecosystem) {
+		return pkgs, nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(db.Dir, fileName(ecosystem)))
+	if err != nil {
+		return nil, err
+	}
+	var vulns []*Vulnerability
+	if err := json.Unmarshal(data, &vulns); err != nil {
+		return nil, fmt.Errorf("%s: %w", fileName(ecosystem), err)
+	}
+	for _, v := range vulns {
+		seen := map[string]bool{}
+		for _, a := range v.Affected {
+			n := NormalizeName(ecosystem, a.Package.Name)
+			if a.Package.Ecosystem == ecosystem && !seen[n] {
+				seen[n] = true
+				pkgs[n] = append(pkgs[n], v)
+			}
+		}
+	}
+	return pkgs, nil
+}
+
+var pypiSeparators = regexp.MustCompile(`[-_.]+`)
+
+// NormalizeName приводит имя к каноническому виду экосистемы (PEP 503 для PyPI)
+func NormalizeName(ecosystem, name string) string {
+	if ecosystem == PyPI {
+		return pypiSeparators.ReplaceAllString(strings.ToLower(name), "-")
+	}
+	return name
+}
+
+// fileName — файл экосистемы ("Debian:12" и подобные экранируются)
+func fileName(ecosystem string) string {
+	return url.PathEscape(strings.ReplaceAll(ecosystem, ":", "_")) + ".json"
+}
@@ -0,0 +1,219 @@
+package osv
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/devos-os/d-guard/internal/core"
+)
+
+// Экосистемы OSV, для которых есть парсеры lockfile
+const (
+	Go     = "Go"
+	NPM    = "npm"
+	Crates = "crates.io"
+	PyPI   = "PyPI"
+)
+
+// Ecosystems — экосистемы, которые 'd-guard db update' скачивает по умолчанию
+var Ecosystems = []string{Go, NPM, Crates, PyPI}
+
+// Vulnerability — подмножество схемы OSV, нужное для сопоставления версий
+type Vulnerability struct {
+	ID               string     `json:"id"`
+	Aliases          []string   `json:"aliases,omitempty"`
+	Summary          string     `json:"summary,omitempty"`
+	Details          string     `json:"details,omitempty"`
+	Modified         time.Time  `json:"modified"`
+	Affected         []Affected `json:"affected"`
+	Severity         []Score    `json:"severity,omitempty"`
+	DatabaseSpecific struct {
+		Severity string `json:"severity,omitempty"` // GHSA: LOW, MODERATE, HIGH, CRITICAL
+	} `json:"database_specific"`
+}
+
+type Affected struct {
+	Package  Package  `json:"package"`
+	Ranges   []Range  `json:"ranges,omitempty"`
+	Versions []string `json:"versions,omitempty"`
+}
+
+type Package struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+}
+
+// Range — события introduced/fixed/last_affected (типы SEMVER и ECOSYSTEM; GIT не поддерживается)
+type Range struct {
+	Type   string  `json:"type"`
+	Events []Event `json:"events"`
+}
+
+type Event struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+}
+
+func (e Event) version() string {
+	switch {
+	case e.Introduced != "":
+		return e.Introduced
+	case e.Fixed != "":
+		return e.Fixed
+	case e.LastAffected != "":
+		return e.LastAffected
+	}
+	return e.Limit
+}
+
+type Score struct {
+	Type  string `json:"type"` // CVSS_V3, CVSS_V4
+	Score string `json:"score"`
+}
+
+// Match — уязвимость, затрагивающая конкретную версию пакета
+type Match struct {
+	Vuln     *Vulnerability
+	Affected string // e.g. ">=1.2.0, <1.2.5"
+	Fixed    string // Ближайшая версия с исправлением (пусто — исправления нет)
+}
+
+// CVE — первый CVE среди ID и алиасов (или ID записи OSV)
+func (v *Vulnerability) CVE() string {
+	for _, id := range append([]string{v.ID}, v.Aliases...) {
+		if strings.HasPrefix(id, "CVE-") {
+			return id
+		}
+	}
+	return v.ID
+}
+
+// Level — уровень: database_specific.severity (GHSA), затем CVSS v3, иначе MEDIUM
+func (v *Vulnerability) Level() core.Severity {
+	switch strings.ToUpper(v.DatabaseSpecific.Severity) {
+	case "CRITICAL":
+		return core.SevCritical
+	case "HIGH":
+		return core.SevHigh
+	case "MODERATE", "MEDIUM":
+		return core.SevMedium
+	case "LOW":
+		return core.SevLow
+	}
+	for _, s := range v.Severity {
+		if s.Type != "CVSS_V3" {
+			continue
+		}
+		if score, ok := CVSS3(s.Score); ok {
+			switch {
+			case score >= 9:
+				return core.SevCritical
+			case score >= 7:
+				return core.SevHigh
+			case score >= 4:
+				return core.SevMedium
+			default:
+				return core.SevLow
+			}
+		}
+	}
+	return core.SevMedium
+}
+
+// affects проверяет версию по диапазонам и явному списку versions одной записи affected
+func (a Affected) affects(version string) (bool, string, string) {
+	for _, v := range a.Versions {
+		if CompareEcosystem(a.Package.Ecosystem, v, version) == 0 {
+			return true, "=" + v, nextFixed(a.Package.Ecosystem, a.Ranges, version)
+		}
+	}
+	for _, r := range a.Ranges {
+		if r.Type != "SEMVER" && r.Type != "ECOSYSTEM" {
+			continue
+		}
+		if ok, desc, fixed := r.affects(a.Package.Ecosystem, version); ok {
+			return true, desc, fixed
+		}
+	}
+	return false, "", ""
+}
+
+// affects — алгоритм из спецификации OSV: события по возрастанию версии,
+// introduced включает уязвимость, fixed и last_affected — выключают.
+// Версии SEMVER сравниваются по SemVer, ECOSYSTEM — по правилам экосистемы.
+func (r Range) affects(ecosystem, version string) (bool, string, string) {
+	cmpVersions := Compare
+	if r.Type == "ECOSYSTEM" {
+		cmpVersions = func(a, b string) int { return CompareEcosystem(ecosystem, a, b) }
+	}
+	events := append([]Event{}, r.Events...)
+	sort.SliceStable(events, func(i, j int) bool {
+		return cmpVersions(events[i].version(), events[j].version()) < 0
+	})
+
+	affected := false
+	var lower string
+	for _, e := range events {
+		switch {
+		case e.Introduced != "" && cmpVersions(version, e.Introduced) >= 0:
+			affected, lower = true, e.Introduced
+		case e.Fixed != "" && cmpVersions(version, e.Fixed) >= 0:
+			affected = false
+		case e.LastAffected != "" && cmpVersions(version, e.LastAffected) > 0:
+			affected = false
+		}
+	}
+	if !affected {
+		return false, "", ""
+	}
+
+	desc := ">=" + lower
+	if lower == "0" {
+		desc = ""
+	}
+	fixed := ""
+	// Верхняя граница — первое fixed/last_affected не ниже текущей версии
+	for _, e := range events {
+		if e.Fixed != "" && cmpVersions(e.Fixed, version) > 0 {
+			fixed = e.Fixed
+			desc = joinRange(desc, "<"+e.Fixed)
+			break
+		}
+		if e.LastAffected != "" && cmpVersions(e.LastAffected, version) >= 0 {
+			desc = joinRange(desc, "<="+e.LastAffected)
+			break
+		}
+	}
+	if desc == "" {
+		desc = "all versions"
+	}
+	return true, desc, fixed
+}
+
+func joinRange(a, b string) string {
+	if a == "" {
+		return b
+	}
+	return a + ", " + b
+}
+
+func nextFixed(ecosystem string, ranges []Range, version string) string {
+	for _, r := range ranges {
+		if _, _, fixed := r.affects(ecosystem, version); fixed != "" {
+			return fixed
+		}
+	}
+	return ""
+}
+
+// Describe — строка для отчета: "GHSA-xxxx (CVE-2024-1234)"
+func (v *Vulnerability) Describe() string {
+	if cve := v.CVE(); cve != v.ID {
+		return fmt.Sprintf("%s (%s)", v.ID, cve)
+	}
+	return v.ID
+}
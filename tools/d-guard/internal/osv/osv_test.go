@@ -0,0 +1,171 @@
+package osv
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/devos-os/d-guard/internal/core"
+)
+
+func TestRangeAffects(t *testing.T) {
+	semver := func(events ...Event) Affected {
+		return Affected{Ranges: []Range{{Type: "SEMVER", Events: events}}}
+	}
+	twoIntervals := semver(
+		Event{Fixed: "2.3.0"}, Event{Introduced: "2.0.0"}, // События в произвольном порядке
+		Event{Introduced: "1.0.0"}, Event{Fixed: "1.5.0"},
+	)
+	tests := []struct {
+		name     string
+		affected Affected
+		version  string
+		want     bool
+		desc     string
+		fixed    string
+	}{
+		{"inside", semver(Event{Introduced: "1.2.0"}, Event{Fixed: "1.2.5"}), "1.2.3", true, ">=1.2.0, <1.2.5", "1.2.5"},
+		{"introduced is inclusive", semver(Event{Introduced: "1.2.0"}, Event{Fixed: "1.2.5"}), "1.2.0", true, ">=1.2.0, <1.2.5", "1.2.5"},
+		{"fixed is exclusive", semver(Event{Introduced: "1.2.0"}, Event{Fixed: "1.2.5"}), "1.2.5", false, "", ""},
+		{"below introduced", semver(Event{Introduced: "1.2.0"}, Event{Fixed: "1.2.5"}), "1.1.9", false, "", ""},
+		{"pre-release of fixed", semver(Event{Introduced: "1.2.0"}, Event{Fixed: "1.2.5"}), "1.2.5-rc.1", true, ">=1.2.0, <1.2.5", "1.2.5"},
+		{"all versions", semver(Event{Introduced: "0"}), "9.9.9", true, "all versions", ""},
+		{"introduced 0 with fix", semver(Event{Introduced: "0"}, Event{Fixed: "0.4.0"}), "0.3.0", true, "<0.4.0", "0.4.0"},
+		{"last_affected inclusive", semver(Event{Introduced: "0"}, Event{LastAffected: "1.4.0"}), "1.4.0", true, "<=1.4.0", ""},
+		{"after last_affected", semver(Event{Introduced: "0"}, Event{LastAffected: "1.4.0"}), "1.4.1", false, "", ""},
+		{"between intervals", twoIntervals, "1.7.0", false, "", ""},
+		{"second interval", twoIntervals, "2.1.0", true, ">=2.0.0, <2.3.0", "2.3.0"},
+		{"first interval", twoIntervals, "1.4.0", true, ">=1.0.0, <1.5.0", "1.5.0"},
+		{"ecosystem range", Affected{Ranges: []Range{{Type: "ECOSYSTEM", Events: []Event{{Introduced: "2.0"}, {Fixed: "2.0.post1"}}}}},
+			"2.0", true, ">=2.0, <2.0.post1", "2.0.post1"},
+		{"PyPI implicit post-release", Affected{Package: Package{Ecosystem: PyPI}, Ranges: []Range{{Type: "ECOSYSTEM", Events: []Event{{Introduced: "0"}, {Fixed: "2.0-1"}}}}},
+			"2.0", true, "<2.0-1", "2.0-1"},
+		{"PyPI post-release is fixed", Affected{Package: Package{Ecosystem: PyPI}, Ranges: []Range{{Type: "ECOSYSTEM", Events: []Event{{Introduced: "0"}, {Fixed: "2.0-1"}}}}},
+			"2.0.post1", false, "", ""},
+		{"git range ignored", Affected{Ranges: []Range{{Type: "GIT", Events: []Event{{Introduced: "0"}}}}}, "1.0.0", false, "", ""},
+		{"explicit versions", Affected{Versions: []string{"1.0.0", "1.0.1"},
+			Ranges: []Range{{Type: "ECOSYSTEM", Events: []Event{{Introduced: "1.0.0"}, {Fixed: "1.0.2"}}}}},
+			"1.0.1", true, "=1.0.1", "1.0.2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, desc, fixed := tt.affected.affects(tt.version)
+			if ok != tt.want || desc != tt.desc || fixed != tt.fixed {
+				t.Errorf("affects(%q) = %v, %q, %q; want %v, %q, %q", tt.version, ok, desc, fixed, tt.want, tt.desc, tt.fixed)
+			}
+		})
+	}
+}
+
+func TestCVSS3(t *testing.T) {
+	tests := []struct {
+		vector string
+		score  float64
+		ok     bool
+	}{
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 9.8, true},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H", 10.0, true},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N", 6.1, true},
+		{"CVSS:3.1/AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:H/A:H", 7.8, true},
+		{"CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:H/I:N/A:N", 5.9, true},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H", 7.5, true},
+		{"CVSS:3.0/AV:N/AC:L/PR:L/UI:N/S:U/C:L/I:N/A:N", 4.3, true},
+		{"CVSS:3.1/AV:P/AC:H/PR:H/UI:R/S:U/C:L/I:N/A:N", 1.6, true},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N", 0, true},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H", 0, false}, // Нет A
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:X/C:H/I:H/A:H", 0, false},
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N", 0, false},
+		{"AV:N/AC:L/Au:N/C:P/I:P/A:P", 0, false}, // CVSS v2
+	}
+	for _, tt := range tests {
+		score, ok := CVSS3(tt.vector)
+		if score != tt.score || ok != tt.ok {
+			t.Errorf("CVSS3(%s) = %v, %v; want %v, %v", tt.vector, score, ok, tt.score, tt.ok)
+		}
+	}
+}
+
+func TestLevel(t *testing.T) {
+	cvss := func(vector string) []Score { return []Score{{Type: "CVSS_V3", Score: vector}} }
+	tests := []struct {
+		name     string
+		severity string
+		scores   []Score
+		want     core.Severity
+	}{
+		{"ghsa moderate", "MODERATE", nil, core.SevMedium},
+		{"ghsa wins over cvss", "low", cvss("CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"), core.SevLow},
+		{"cvss critical", "", cvss("CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"), core.SevCritical},
+		{"cvss high", "", cvss("CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H"), core.SevHigh},
+		{"cvss medium", "", cvss("CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N"), core.SevMedium},
+		{"cvss low", "", cvss("CVSS:3.1/AV:P/AC:H/PR:H/UI:R/S:U/C:L/I:N/A:N"), core.SevLow},
+		{"cvss v4 only", "", []Score{{Type: "CVSS_V4", Score: "CVSS:4.0/AV:N"}}, core.SevMedium},
+		{"nothing", "", nil, core.SevMedium},
+	}
+	for _, tt := range tests {
+		v := &Vulnerability{ID: "GHSA-test", Severity: tt.scores}
+		v.DatabaseSpecific.Severity = tt.severity
+		if got := v.Level(); got != tt.want {
+			t.Errorf("%s: Level() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	v := &Vulnerability{ID: "GHSA-aaaa-bbbb-cccc", Aliases: []string{"GO-2024-0001", "CVE-2024-1234"}}
+	if got := v.Describe(); got != "GHSA-aaaa-bbbb-cccc (CVE-2024-1234)" {
+		t.Errorf("Describe() = %q", got)
+	}
+	v = &Vulnerability{ID: "CVE-2024-1234"}
+	if got := v.Describe(); got != "CVE-2024-1234" {
+		t.Errorf("Describe() = %q", got)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, v any) {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(metaFile, Meta{Ecosystems: map[string]EcosystemMeta{PyPI: {Count: 1}}})
+	write(fileName(PyPI), []Vulnerability{{
+		ID: "PYSEC-2024-1",
+		Affected: []Affected{
+			{Package: Package{Ecosystem: PyPI, Name: "Zope.Interface"},
+				Ranges: []Range{{Type: "ECOSYSTEM", Events: []Event{{Introduced: "0"}, {Fixed: "5.0"}}}}},
+			{Package: Package{Ecosystem: NPM, Name: "zope-interface"}, Versions: []string{"6.0"}},
+		},
+	}})
+
+	if _, err := Open(filepath.Join(dir, "missing")); !errors.Is(err, ErrNoDB) {
+		t.Errorf("Open(missing) = %v, want ErrNoDB", err)
+	}
+	db, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// PEP 503: регистр и разделители в имени не важны
+	matches, err := db.Query(PyPI, "zope_interface", "4.7.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || matches[0].Fixed != "5.0" || matches[0].Affected != "<5.0" {
+		t.Errorf("Query(4.7.1) = %+v", matches)
+	}
+	// Запись другой экосистемы в том же файле не сопоставляется
+	if matches, _ := db.Query(PyPI, "zope.interface", "6.0"); len(matches) != 0 {
+		t.Errorf("Query(6.0) = %+v, want none", matches)
+	}
+	// Экосистемы нет в зеркале — пустой результат без ошибки
+	if matches, err := db.Query(Go, "golang.org/x/net", "v0.1.0"); err != nil || len(matches) != 0 {
+		t.Errorf("Query(Go) = %+v, %v", matches, err)
+	}
+}
@@ -0,0 +1,180 @@
+package osv
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// exportURL — официальные выгрузки OSV: все записи экосистемы одним zip-архивом
+const exportURL = "https://osv-vulnerabilities.storage.googleapis.com/%s/all.zip"
+
+// DefaultSources — выгрузки для поддерживаемых экосистем
+func DefaultSources() []string {
+	var out []string
+	for _, eco := range Ecosystems {
+		out = append(out, fmt.Sprintf(exportURL, eco))
+	}
+	return out
+}
+
+// IsURL — источник нужно скачивать
+func IsURL(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+// Update загружает записи из источников (zip-выгрузка OSV, JSON-файл с записью
+// или массивом записей, файл или URL) и заменяет затронутые экосистемы в зеркале.
+// Возвращает число записей по экосистемам.
+func Update(ctx context.Context, dir string, sources []string) (map[string]int, error) {
+	byEco := map[string]map[string]*Vulnerability{}
+	origin := map[string]string{}
+
+	for _, src := range sources {
+		data, err := fetch(ctx, src)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", src, err)
+		}
+		vulns, err := parse(src, data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", src, err)
+		}
+		for _, v := range vulns {
+			for _, a := range v.Affected {
+				eco := a.Package.Ecosystem
+				if eco == "" {
+					continue
+				}
+				if byEco[eco] == nil {
+					byEco[eco] = map[string]*Vulnerability{}
+					origin[eco] = src
+				}
+				byEco[eco][v.ID] = v
+			}
+		}
+	}
+	if len(byEco) == 0 {
+		return nil, fmt.Errorf("no OSV records found in %s", strings.Join(sources, ", "))
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	meta := Meta{Ecosystems: map[string]EcosystemMeta{}}
+	if db, err := Open(dir); err == nil {
+		meta = db.Meta
+	}
+
+	counts := map[string]int{}
+	for eco, set := range byEco {
+		list := make([]*Vulnerability, 0, len(set))
+		for _, v := range set {
+			list = append(list, compact(v))
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+		if err := writeJSON(filepath.Join(dir, fileName(eco)), list); err != nil {
+			return nil, err
+		}
+		meta.Ecosystems[eco] = EcosystemMeta{Updated: time.Now().UTC(), Source: origin[eco], Count: len(list)}
+		counts[eco] = len(list)
+	}
+	return counts, writeJSON(filepath.Join(dir, metaFile), meta)
+}
+
+func fetch(ctx context.Context, src string) ([]byte, error) {
+	if !IsURL(src) {
+		return os.ReadFile(src)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download failed: %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// parse: zip с JSON-файлами (формат выгрузки OSV), одна запись или массив записей
+func parse(src string, data []byte) ([]*Vulnerability, error) {
+	if bytes.HasPrefix(data, []byte("PK")) {
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, err
+		}
+		var out []*Vulnerability
+		for _, f := range zr.File {
+			if !strings.HasSuffix(f.Name, ".json") {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			content, err := io.ReadAll(rc)
+			rc.Close()
+			if err != nil {
+				return nil, err
+			}
+			vulns, err := parseJSON(content)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", f.Name, err)
+			}
+			out = append(out, vulns...)
+		}
+		return out, nil
+	}
+	return parseJSON(data)
+}
+
+func parseJSON(data []byte) ([]*Vulnerability, error) {
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("[")) {
+		var list []*Vulnerability
+		return list, json.Unmarshal(data, &list)
+	}
+	var v Vulnerability
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	if v.ID == "" {
+		return nil, fmt.Errorf("not an OSV record (no id)")
+	}
+	return []*Vulnerability{&v}, nil
+}
+
+// compact укорачивает details: в отчет идет summary, полный текст — по ссылке на запись
+func compact(v *Vulnerability) *Vulnerability {
+	c := *v
+	if len(c.Details) > 1000 {
+		c.Details = c.Details[:1000] + "..."
+	}
+	return &c
+}
+
+// writeJSON пишет атомарно: прерванное обновление не портит зеркало
+func writeJSON(path string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
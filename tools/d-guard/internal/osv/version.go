@@ -0,0 +1,187 @@
+package osv
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Compare сравнивает версии (-1, 0, 1) с семантикой, общей для SemVer (Go, npm,
+// crates.io) и PEP 440 (PyPI): числовые сегменты — как числа, пре-релизы
+// (dev < alpha < beta < rc, "-x" в SemVer) младше релиза, post-релизы старше.
+// Метаданные сборки (+build, +local) не учитываются.
+func Compare(a, b string) int {
+	return compare(parseVersion(a, false), parseVersion(b, false))
+}
+
+// CompareEcosystem — Compare по правилам экосистемы: в PyPI "1.0-1" — неявный
+// post-релиз (PEP 440, то же что 1.0.post1), а в SemVer такой суффикс — пре-релиз.
+func CompareEcosystem(ecosystem, a, b string) int {
+	pep440 := ecosystem == PyPI
+	return compare(parseVersion(a, pep440), parseVersion(b, pep440))
+}
+
+func compare(va, vb version) int {
+	if c := cmpInt(va.epoch, vb.epoch); c != 0 {
+		return c
+	}
+	n := max(len(va.release), len(vb.release))
+	for i := 0; i < n; i++ {
+		if c := cmpInt(at(va.release, i), at(vb.release, i)); c != 0 {
+			return c
+		}
+	}
+	// Релиз без суффикса старше любого пре-релиза
+	switch {
+	case va.pre == nil && vb.pre != nil:
+		return 1
+	case va.pre != nil && vb.pre == nil:
+		return -1
+	case va.pre != nil:
+		if c := cmpPre(va.pre, vb.pre); c != 0 {
+			return c
+		}
+	}
+	return cmpInt(va.post, vb.post)
+}
+
+type version struct {
+	epoch   int
+	release []int
+	pre     []string // nil — не пре-релиз
+	post    int      // -1 — не post-релиз
+}
+
+func parseVersion(s string, pep440 bool) version {
+	v := version{post: -1}
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.TrimPrefix(s, "v")
+	s, _, _ = strings.Cut(s, "+")
+	if e, rest, ok := strings.Cut(s, "!"); ok {
+		v.epoch, _ = strconv.Atoi(e)
+		s = rest
+	}
+
+	// Релизная часть: 1.2.3
+	i := 0
+	for i < len(s) {
+		j := i
+		for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+			j++
+		}
+		if j == i {
+			break
+		}
+		n, _ := strconv.Atoi(s[i:j])
+		v.release = append(v.release, n)
+		i = j
+		if i < len(s) && s[i] == '.' && i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '9' {
+			i++
+			continue
+		}
+		break
+	}
+
+	suffix := strings.TrimLeft(s[i:], "-._")
+	if suffix == "" {
+		return v
+	}
+	if n, err := strconv.Atoi(suffix); err == nil && pep440 && strings.HasPrefix(s[i:], "-") {
+		v.post = n
+		return v
+	}
+	tokens := splitTokens(suffix)
+	for k, t := range tokens {
+		if t == "post" || t == "rev" || t == "r" {
+			if k+1 < len(tokens) {
+				v.post, _ = strconv.Atoi(tokens[k+1])
+			} else {
+				v.post = 0
+			}
+			if k > 0 {
+				v.pre = tokens[:k]
+			}
+			return v
+		}
+	}
+	v.pre = tokens
+	return v
+}
+
+// splitTokens: "rc.1" -> [rc 1], "a1" -> [a 1], "beta-2" -> [beta 2]
+func splitTokens(s string) []string {
+	var out []string
+	var cur strings.Builder
+	flush := func() {
+		if cur.Len() > 0 {
+			out = append(out, cur.String())
+			cur.Reset()
+		}
+	}
+	prevDigit := false
+	for i, r := range s {
+		if r == '.' || r == '-' || r == '_' {
+			flush()
+			continue
+		}
+		digit := unicode.IsDigit(r)
+		if i > 0 && digit != prevDigit {
+			flush()
+		}
+		cur.WriteRune(r)
+		prevDigit = digit
+	}
+	flush()
+	return out
+}
+
+// preRank — порядок ключевых слов пре-релизов (PEP 440 и общепринятые SemVer)
+var preRank = map[string]int{"dev": 0, "alpha": 1, "a": 1, "beta": 2, "b": 2, "pre": 3, "preview": 3, "c": 3, "rc": 3}
+
+func cmpPre(a, b []string) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		x, y := a[i], b[i]
+		nx, errX := strconv.Atoi(x)
+		ny, errY := strconv.Atoi(y)
+		switch {
+		case errX == nil && errY == nil:
+			if c := cmpInt(nx, ny); c != 0 {
+				return c
+			}
+		case errX == nil:
+			return -1 // SemVer: числовой идентификатор младше буквенного
+		case errY == nil:
+			return 1
+		default:
+			rx, okX := preRank[x]
+			ry, okY := preRank[y]
+			if okX && okY {
+				if c := cmpInt(rx, ry); c != 0 {
+					return c
+				}
+				continue
+			}
+			if c := strings.Compare(x, y); c != 0 {
+				return c
+			}
+		}
+	}
+	return cmpInt(len(a), len(b))
+}
+
+func at(list []int, i int) int {
+	if i < len(list) {
+		return list[i]
+	}
+	return 0
+}
+
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
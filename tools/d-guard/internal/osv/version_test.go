@@ -0,0 +1,85 @@
+package osv
+
+import "testing"
+
+// ordered проверяет, что каждая версия списка строго меньше следующей
+func ordered(t *testing.T, versions []string) {
+	t.Helper()
+	for i := range versions {
+		for j := range versions {
+			want := cmpInt(i, j)
+			if got := Compare(versions[i], versions[j]); got != want {
+				t.Errorf("Compare(%q, %q) = %d, want %d", versions[i], versions[j], got, want)
+			}
+		}
+	}
+}
+
+func TestCompareSemVer(t *testing.T) {
+	// Порядок из раздела 11 спецификации SemVer 2.0.0
+	ordered(t, []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta",
+		"1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0",
+		"1.0.1", "1.2.0", "1.10.0", "2.0.0",
+	})
+}
+
+func TestComparePEP440(t *testing.T) {
+	ordered(t, []string{
+		"1.0.dev0", "1.0a1", "1.0a2", "1.0b1", "1.0rc1", "1.0rc2",
+		"1.0", "1.0.post1", "1.0.post2", "1.0.1", "1.1",
+		"1!0.1", // Эпоха старше любой версии без нее
+	})
+}
+
+func TestCompareGoPseudoVersions(t *testing.T) {
+	ordered(t, []string{
+		"v0.0.0-20200101000000-0123456789ab",
+		"v0.0.0-20210101000000-fedcba987654",
+		"v0.1.0",
+		"v1.2.3",
+		"v1.2.4-0.20210101000000-0123456789ab", // Псевдоверсия после v1.2.3
+		"v1.2.4-0.20220101000000-0123456789ab",
+		"v1.2.4",
+	})
+}
+
+func TestCompareEqual(t *testing.T) {
+	tests := []struct{ a, b string }{
+		{"1.0", "1.0.0"},
+		{"v1.2.3", "1.2.3"},
+		{"1.2.3+build.5", "1.2.3"},
+		{"v2.0.0+incompatible", "2.0.0"},
+		{"1.0alpha1", "1.0a1"},
+		{"1.0c1", "1.0rc1"},
+		{"0!1.0", "1.0"},
+	}
+	for _, tt := range tests {
+		if got := Compare(tt.a, tt.b); got != 0 {
+			t.Errorf("Compare(%q, %q) = %d, want 0", tt.a, tt.b, got)
+		}
+	}
+}
+
+func TestCompareEcosystem(t *testing.T) {
+	tests := []struct {
+		ecosystem, a, b string
+		want            int
+	}{
+		// PEP 440: "-N" — неявный post-релиз
+		{PyPI, "1.0-1", "1.0.post1", 0},
+		{PyPI, "1.0-1", "1.0", 1},
+		{PyPI, "1.0-2", "1.0.post1", 1},
+		{PyPI, "1.0-1", "1.0.1", -1},
+		{PyPI, "1.0-rc1", "1.0", -1}, // Буквенный суффикс — по-прежнему пре-релиз
+		// SemVer: "-N" — пре-релиз
+		{NPM, "1.0.0-1", "1.0.0", -1},
+		{Crates, "1.0.0-1", "1.0.0", -1},
+		{Go, "v1.2.4-0.20210101000000-0123456789ab", "v1.2.4", -1},
+	}
+	for _, tt := range tests {
+		if got := CompareEcosystem(tt.ecosystem, tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareEcosystem(%s, %q, %q) = %d, want %d", tt.ecosystem, tt.a, tt.b, got, tt.want)
+		}
+	}
+}
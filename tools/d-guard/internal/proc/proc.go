@@ -0,0 +1,23 @@
+package proc
+
+import (
+	"context"
+	"os/exec"
+	"time"
+)
+
+// Запуск внешних инструментов (gitleaks, semgrep, trivy) с отменой через context.
+// Инструменты порождают свои процессы (semgrep-core, плагины trivy), поэтому при
+// отмене сигнал получает вся группа процессов, а не только ее лидер.
+
+// KillGrace — сколько ждать после SIGTERM, прежде чем добить группу SIGKILL
+const KillGrace = 3 * time.Second
+
+// Command — exec.CommandContext в собственной группе процессов
+func Command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	group(cmd)
+	// Потомки могут держать stdout открытым: Wait не должен зависнуть на нем
+	cmd.WaitDelay = 2 * KillGrace
+	return cmd
+}
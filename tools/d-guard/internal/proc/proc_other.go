@@ -0,0 +1,8 @@
+//go:build !unix
+
+package proc
+
+import "os/exec"
+
+// group: без групп процессов остается поведение exec.CommandContext (Kill лидера)
+func group(cmd *exec.Cmd) {}
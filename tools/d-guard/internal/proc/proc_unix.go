@@ -0,0 +1,29 @@
+//go:build unix
+
+package proc
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// group запускает процесс лидером новой группы (Ctrl+C терминала до него не доходит,
+// завершением управляет d-guard) и при отмене шлет SIGTERM, затем SIGKILL всей группе.
+// SIGKILL не отправляется, если Wait уже собрал лидера: пока лидер не собран (даже зомби),
+// его pid, а значит и pgid, не может достаться другой группе.
+func group(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		pgid := -cmd.Process.Pid
+		time.AfterFunc(KillGrace, func() {
+			if errors.Is(cmd.Process.Signal(syscall.Signal(0)), os.ErrProcessDone) {
+				return
+			}
+			syscall.Kill(pgid, syscall.SIGKILL)
+		})
+		return syscall.Kill(pgid, syscall.SIGTERM)
+	}
+}
@@ -0,0 +1,49 @@
+//go:build unix
+
+package proc
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// Процесс, игнорирующий SIGTERM, и его потомок добиваются SIGKILL через KillGrace
+func TestCancelKillsGroup(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := Command(ctx, "sh", "-c", `trap "" TERM; sleep 30 & wait`)
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	pgid := cmd.Process.Pid
+	time.Sleep(200 * time.Millisecond)
+
+	start := time.Now()
+	cancel()
+	cmd.Wait()
+	if d := time.Since(start); d > KillGrace+2*time.Second {
+		t.Errorf("Wait took %v after cancel", d)
+	}
+	// Группа пуста: sleep тоже получил SIGKILL
+	deadline := time.Now().Add(2 * time.Second)
+	for syscall.Kill(-pgid, 0) == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("process group is still alive")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// После Wait лидер собран: отложенный SIGKILL по этому признаку не отправляется
+func TestSignalAfterWait(t *testing.T) {
+	cmd := Command(context.Background(), "true")
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Process.Signal(syscall.Signal(0)); !errors.Is(err, os.ErrProcessDone) {
+		t.Errorf("Signal after Wait = %v, want os.ErrProcessDone", err)
+	}
+}
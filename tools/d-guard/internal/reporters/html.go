@@ -26,6 +26,11 @@ const htmlTemplate = `
 		h3 { margin-top: 0; }
 		.meta { color: #666; font-size: 0.9em; font-family: monospace; background: #eee; padding: 2px 5px; border-radius: 3px; }
 		.suggestion { background: #e3f2fd; padding: 10px; border-radius: 4px; margin-top: 10px; color: #0d47a1; }
+		.suppressed { opacity: 0.6; border-left-style: dashed; }
+		table.scanners { width: 100%; border-collapse: collapse; background: white; margin-bottom: 20px; border-radius: 4px; }
+		table.scanners th, table.scanners td { text-align: left; padding: 8px 12px; border-bottom: 1px solid #eee; font-size: 0.9em; }
+		.status-ok { color: #2ed573; } .status-skipped { color: #a4b0be; } .status-failed, .status-timeout { color: #ff4757; font-weight: bold; }
+		.justification { background: #f1f2f6; padding: 10px; border-radius: 4px; margin-top: 10px; color: #57606f; }
 	</style>
 </head>
 <body>
@@ -34,14 +39,35 @@ const htmlTemplate = `
 		<h1>🛡️ d-guard Scan Report</h1>
 		<p><strong>Generated:</strong> {{ .Date }} | <strong>Issues Found:</strong> {{ .Count }}</p>
 	</div>
+	{{ if .Runs }}
+	<table class="scanners">
+		<tr><th>Scanner</th><th>Status</th><th>Issues</th><th>Duration</th><th>Version</th><th>Details</th></tr>
+		{{ range .Runs }}
+		<tr>
+			<td>{{ .Name }}</td>
+			<td class="status-{{ .Status }}">{{ .Status }}</td>
+			<td>{{ .Issues }}</td>
+			<td>{{ if ne .Status "skipped" }}{{ .Duration }}{{ end }}</td>
+			<td>{{ .Version }}</td>
+			<td>{{ .Reason }}{{ .Error }}</td>
+		</tr>
+		{{ end }}
+	</table>
+	{{ end }}
 	{{ range .Issues }}
-	<div class="issue {{ .Severity }}">
-		<h3>[{{ .Severity }}] {{ .Scanner }}: {{ .Message }}</h3>
+	<div class="issue {{ .Severity }}{{ if .Suppressed }} suppressed{{ end }}">
+		<h3>[{{ .Severity }}] {{ .Scanner }}: {{ .Message }}{{ if .Suppressed }} (suppressed){{ end }}</h3>
 		<p>📍 Location: <span class="meta">{{ .File }}:{{ .Line }}</span></p>
+		{{ if .Commit }}
+		<p>🕰️ Commit: <span class="meta">{{ .Commit }}</span> by {{ .Author }} on {{ .Date.Format "2006-01-02" }}</p>
+		{{ end }}
 		<p>{{ .Description }}</p>
 		{{ if .Suggestion }}
 		<div class="suggestion"><strong>💡 Fix:</strong> {{ .Suggestion }}</div>
 		{{ end }}
+		{{ if .Suppressed }}
+		<div class="justification"><strong>🔕 Suppressed:</strong> {{ .Justification }}</div>
+		{{ end }}
 	</div>
 	{{ end }}
 </div>
@@ -49,7 +75,7 @@ const htmlTemplate = `
 </html>
 `
 
-func GenerateHTML(issues []core.Issue, filename string) {
+func GenerateHTML(issues []core.Issue, runs []core.ScannerRun, filename string) {
 	t, _ := template.New("report").Parse(htmlTemplate)
 	f, _ := os.Create(filename)
 	defer f.Close()
@@ -58,10 +84,12 @@ func GenerateHTML(issues []core.Issue, filename string) {
 		Date   string
 		Count  int
 		Issues []core.Issue
+		Runs   []core.ScannerRun
 	}{
 		Date:   time.Now().Format(time.RFC822),
 		Count:  len(issues),
 		Issues: issues,
+		Runs:   runs,
 	}
 
 	t.Execute(f, data)
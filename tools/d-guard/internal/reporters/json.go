@@ -0,0 +1,165 @@
+package reporters
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/devos-os/d-guard/internal/core"
+)
+
+// JSONSchemaVersion версия схемы JSON-отчета. Поля только добавляются;
+// удаление или переименование поля — новая версия.
+const JSONSchemaVersion = 1
+
+type jsonReport struct {
+	Schema        string        `json:"schema"` // Идентификатор формата
+	SchemaVersion int           `json:"schemaVersion"`
+	Tool          jsonTool      `json:"tool"`
+	Scan          jsonScan      `json:"scan"`
+	Summary       jsonSummary   `json:"summary"`
+	Scanners      []jsonScanner `json:"scanners"`
+	Issues        []jsonIssue   `json:"issues"`
+}
+
+type jsonTool struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type jsonScan struct {
+	Root       string  `json:"root"`
+	Commit     string  `json:"commit,omitempty"`
+	Branch     string  `json:"branch,omitempty"`
+	Mode       string  `json:"mode"`
+	Base       string  `json:"base,omitempty"`
+	Files      int     `json:"files"`
+	StartedAt  string  `json:"startedAt"`
+	DurationMs float64 `json:"durationMs"`
+}
+
+type jsonSummary struct {
+	Total      int            `json:"total"`
+	Active     int            `json:"active"`
+	Suppressed int            `json:"suppressed"`
+	BySeverity map[string]int `json:"bySeverity"` // Только активные
+	Failed     []string       `json:"failedScanners"`
+}
+
+type jsonScanner struct {
+	Name        string  `json:"name"`
+	Status      string  `json:"status"`
+	Reason      string  `json:"reason,omitempty"`
+	Error       string  `json:"error,omitempty"`
+	Version     string  `json:"version,omitempty"`
+	Issues      int     `json:"issues"`
+	DurationMs  float64 `json:"durationMs"`
+	CacheHits   int     `json:"cacheHits,omitempty"`
+	CacheMisses int     `json:"cacheMisses,omitempty"`
+}
+
+type jsonIssue struct {
+	Scanner       string `json:"scanner"`
+	Tool          string `json:"tool"`
+	RuleID        string `json:"ruleId"`
+	Severity      string `json:"severity"`
+	Message       string `json:"message"`
+	File          string `json:"file,omitempty"`
+	Line          int    `json:"line,omitempty"`
+	Description   string `json:"description,omitempty"`
+	Suggestion    string `json:"suggestion,omitempty"`
+	Suppressed    bool   `json:"suppressed"`
+	Justification string `json:"justification,omitempty"`
+	Commit        string `json:"commit,omitempty"` // --history
+	Author        string `json:"author,omitempty"`
+	Date          string `json:"date,omitempty"`
+}
+
+// GenerateJSON пишет машиночитаемый отчет (схема JSONSchemaVersion).
+// Пути файлов — относительно корня репозитория.
+func GenerateJSON(issues []core.Issue, runs []core.ScannerRun, info core.ScanInfo, filename string) error {
+	data, err := json.MarshalIndent(buildJSON(issues, runs, info), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filename, data, 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("\n📄 JSON Report generated: %s\n", filename)
+	return nil
+}
+
+func buildJSON(issues []core.Issue, runs []core.ScannerRun, info core.ScanInfo) jsonReport {
+	rep := jsonReport{
+		Schema:        "d-guard/report",
+		SchemaVersion: JSONSchemaVersion,
+		Tool:          jsonTool{Name: "d-guard", Version: core.Version},
+		Scan: jsonScan{
+			Root:       info.Root,
+			Commit:     info.Commit,
+			Branch:     info.Branch,
+			Mode:       info.Mode,
+			Base:       info.Base,
+			Files:      info.Files,
+			StartedAt:  info.Started.UTC().Format(time.RFC3339),
+			DurationMs: millis(info.Duration),
+		},
+		Summary:  jsonSummary{BySeverity: map[string]int{}, Failed: []string{}},
+		Scanners: []jsonScanner{},
+		Issues:   []jsonIssue{},
+	}
+	for _, sev := range core.Severities {
+		rep.Summary.BySeverity[string(sev)] = 0
+	}
+
+	for _, r := range runs {
+		rep.Scanners = append(rep.Scanners, jsonScanner{
+			Name:        r.Name,
+			Status:      string(r.Status),
+			Reason:      r.Reason,
+			Error:       r.Error,
+			Version:     r.Version,
+			Issues:      r.Issues,
+			DurationMs:  millis(r.Duration),
+			CacheHits:   r.CacheHits,
+			CacheMisses: r.CacheMisses,
+		})
+		if r.Incomplete() {
+			rep.Summary.Failed = append(rep.Summary.Failed, r.Name)
+		}
+	}
+
+	for _, is := range issues {
+		rep.Issues = append(rep.Issues, jsonIssue{
+			Scanner:       is.ScannerID,
+			Tool:          is.Scanner,
+			RuleID:        is.RuleID,
+			Severity:      string(is.Severity),
+			Message:       is.Message,
+			File:          relFile(info.Root, is.File),
+			Line:          is.Line,
+			Description:   is.Description,
+			Suggestion:    is.Suggestion,
+			Suppressed:    is.Suppressed,
+			Justification: is.Justification,
+			Commit:        is.Commit,
+			Author:        is.Author,
+		})
+		if !is.Date.IsZero() {
+			rep.Issues[len(rep.Issues)-1].Date = is.Date.Format(time.RFC3339)
+		}
+		rep.Summary.Total++
+		if is.Suppressed {
+			rep.Summary.Suppressed++
+			continue
+		}
+		rep.Summary.Active++
+		rep.Summary.BySeverity[string(is.Severity)]++
+	}
+	return rep
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
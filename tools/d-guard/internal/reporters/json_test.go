@@ -0,0 +1,97 @@
+package reporters
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/devos-os/d-guard/internal/core"
+)
+
+func TestBuildJSON(t *testing.T) {
+	info := core.ScanInfo{
+		Root: "/repo", Commit: "abc", Branch: "main", Mode: core.ModeCI, Base: "main", Files: 3,
+		Started: time.Date(2024, 3, 1, 12, 0, 0, 0, time.FixedZone("MSK", 3*3600)), Duration: 1500 * time.Millisecond,
+	}
+	issues := []core.Issue{
+		{Scanner: "Secrets", ScannerID: "secrets", RuleID: "github-pat", Severity: core.SevCritical, Message: "token", File: "/repo/a/b.go", Line: 4},
+		{Scanner: "Secrets", ScannerID: "secrets", RuleID: "github-pat", Severity: core.SevHigh, Message: "token", File: "/repo/c.go", Line: 1, Suppressed: true, Justification: "test fixture"},
+		{Scanner: "Trivy", ScannerID: "trivy", RuleID: "CVE-1", Severity: core.SevHigh, Message: "cve", File: "/opt/x"},
+		{Scanner: "Secrets", ScannerID: "secrets", RuleID: "aws", Severity: core.SevLow, Message: "old", File: "/repo/.env", Line: 2,
+			Commit: "0123456789abcdef", Author: "dev", Date: time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}
+	runs := []core.ScannerRun{
+		{Name: "secrets", Status: core.StatusOK, Issues: 3, Duration: 2500 * time.Microsecond, CacheHits: 5, CacheMisses: 1},
+		{Name: "trivy", Status: core.StatusFailed, Error: "exit status 1", Version: "0.50.0"},
+		{Name: "semgrep", Status: core.StatusTimeout, Error: "timed out"},
+		{Name: "gitleaks", Status: core.StatusSkipped, Reason: "binary not found"},
+	}
+	rep := buildJSON(issues, runs, info)
+
+	if rep.Schema != "d-guard/report" || rep.SchemaVersion != JSONSchemaVersion || JSONSchemaVersion != 1 {
+		t.Errorf("schema = %s v%d", rep.Schema, rep.SchemaVersion)
+	}
+	if rep.Scan.StartedAt != "2024-03-01T09:00:00Z" || rep.Scan.DurationMs != 1500 || rep.Scan.Mode != "ci" {
+		t.Errorf("scan = %+v", rep.Scan)
+	}
+
+	want := jsonSummary{
+		Total: 4, Active: 3, Suppressed: 1,
+		BySeverity: map[string]int{"CRITICAL": 1, "HIGH": 1, "MEDIUM": 0, "LOW": 1},
+		Failed:     []string{"trivy", "semgrep"},
+	}
+	if !reflect.DeepEqual(rep.Summary, want) {
+		t.Errorf("summary = %+v, want %+v", rep.Summary, want)
+	}
+
+	if got := rep.Scanners[0]; got.DurationMs != 2.5 || got.CacheHits != 5 || got.Status != "ok" {
+		t.Errorf("scanner = %+v", got)
+	}
+	if got := rep.Scanners[3]; got.Status != "skipped" || got.Reason != "binary not found" {
+		t.Errorf("skipped scanner = %+v", got)
+	}
+
+	// Пути относительно корня, вне его — как есть
+	if rep.Issues[0].File != "a/b.go" || rep.Issues[2].File != "/opt/x" {
+		t.Errorf("files = %q, %q", rep.Issues[0].File, rep.Issues[2].File)
+	}
+	if is := rep.Issues[1]; !is.Suppressed || is.Justification != "test fixture" || is.Scanner != "secrets" || is.Tool != "Secrets" {
+		t.Errorf("suppressed issue = %+v", is)
+	}
+	if is := rep.Issues[3]; is.Commit != "0123456789abcdef" || is.Date != "2023-01-02T03:04:05Z" || rep.Issues[0].Date != "" {
+		t.Errorf("history issue = %+v", is)
+	}
+}
+
+// Ключи верхнего уровня — контракт схемы v1 для потребителей отчета
+func TestGenerateJSONKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := GenerateJSON(nil, nil, core.ScanInfo{Mode: core.ModeAll}, path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"schema", "schemaVersion", "tool", "scan", "summary", "scanners", "issues"} {
+		if _, ok := raw[key]; !ok {
+			t.Errorf("missing key %q", key)
+		}
+	}
+	// Пустые списки — [] а не null
+	for _, key := range []string{"scanners", "issues"} {
+		if string(raw[key]) != "[]" {
+			t.Errorf("%s = %s, want []", key, raw[key])
+		}
+	}
+	if string(raw["schemaVersion"]) != "1" {
+		t.Errorf("schemaVersion = %s", raw["schemaVersion"])
+	}
+}
@@ -0,0 +1,209 @@
+package reporters
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/devos-os/d-guard/internal/core"
+)
+
+// JUnit XML: testsuite на каждый сканер, testcase на пару правило/файл.
+// Сканер без находок — один зеленый testcase, упавший — <error>, пропущенный — <skipped>.
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr,omitempty"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+	Body    string `xml:",chardata"`
+}
+
+// GenerateJUnit пишет JUnit XML для CI без поддержки SARIF (GitLab, Jenkins, Azure DevOps)
+func GenerateJUnit(issues []core.Issue, runs []core.ScannerRun, info core.ScanInfo, filename string) error {
+	data, err := xml.MarshalIndent(buildJUnit(issues, runs, info), "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append([]byte(xml.Header), data...)
+	if err := os.WriteFile(filename, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("\n📄 JUnit Report generated: %s\n", filename)
+	return nil
+}
+
+func buildJUnit(issues []core.Issue, runs []core.ScannerRun, info core.ScanInfo) junitTestSuites {
+	out := junitTestSuites{Name: "d-guard", Time: seconds(info.Duration.Seconds())}
+
+	// Находки по сканерам -> по ключу "правило @ файл"
+	byScanner := map[string]map[string][]core.Issue{}
+	for _, is := range issues {
+		name := is.ScannerID
+		if name == "" {
+			name = is.Scanner
+		}
+		if byScanner[name] == nil {
+			byScanner[name] = map[string][]core.Issue{}
+		}
+		key := ruleID(is) + " @ " + relFile(info.Root, is.File)
+		if is.Commit != "" {
+			key += " @ " + core.ShortSHA(is.Commit)
+		}
+		byScanner[name][key] = append(byScanner[name][key], is)
+	}
+
+	// Сканеры без записи о запуске (не должно случаться) все равно попадают в отчет
+	seen := map[string]bool{}
+	for _, r := range runs {
+		seen[r.Name] = true
+	}
+	for name := range byScanner {
+		if !seen[name] {
+			runs = append(runs, core.ScannerRun{Name: name, Status: core.StatusOK})
+		}
+	}
+
+	for _, r := range runs {
+		suite := junitTestSuite{
+			Name: "d-guard." + r.Name,
+			Time: seconds(r.Duration.Seconds()),
+		}
+		if !info.Started.IsZero() {
+			suite.Timestamp = info.Started.UTC().Format("2006-01-02T15:04:05")
+		}
+		if r.Version != "" {
+			suite.Properties = append(suite.Properties, junitProperty{Name: "version", Value: r.Version})
+		}
+
+		switch r.Status {
+		case core.StatusSkipped:
+			suite.Cases = append(suite.Cases, junitTestCase{
+				Name: "scan", Classname: suite.Name, Time: "0",
+				Skipped: &junitMessage{Message: r.Reason},
+			})
+			suite.Skipped++
+		case core.StatusFailed, core.StatusTimeout:
+			suite.Cases = append(suite.Cases, junitTestCase{
+				Name: "scan", Classname: suite.Name, Time: suite.Time,
+				Error: &junitMessage{Message: "scanner failed, results are incomplete", Type: "ScannerError", Body: r.Error},
+			})
+			suite.Errors++
+		}
+
+		groups := byScanner[r.Name]
+		var keys []string
+		for k := range groups {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			tc := junitCase(suite.Name, k, groups[k], info.Root)
+			switch {
+			case tc.Failure != nil:
+				suite.Failures++
+			case tc.Skipped != nil:
+				suite.Skipped++
+			}
+			suite.Cases = append(suite.Cases, tc)
+		}
+
+		if len(suite.Cases) == 0 {
+			suite.Cases = append(suite.Cases, junitTestCase{Name: "scan", Classname: suite.Name, Time: suite.Time})
+		}
+		suite.Tests = len(suite.Cases)
+
+		out.Tests += suite.Tests
+		out.Failures += suite.Failures
+		out.Errors += suite.Errors
+		out.Skipped += suite.Skipped
+		out.Suites = append(out.Suites, suite)
+	}
+	return out
+}
+
+// junitCase: все находки одного правила в одном файле. Только подавленные — skipped.
+func junitCase(classname, name string, group []core.Issue, root string) junitTestCase {
+	tc := junitTestCase{Name: name, Classname: classname, File: relFile(root, group[0].File), Time: "0"}
+
+	var active []core.Issue
+	var reasons []string
+	for _, is := range group {
+		if is.Suppressed {
+			reasons = append(reasons, fmt.Sprintf("line %d: %s", is.Line, is.Justification))
+			continue
+		}
+		active = append(active, is)
+	}
+	if len(active) == 0 {
+		tc.Skipped = &junitMessage{Message: "suppressed: " + strings.Join(reasons, "; ")}
+		return tc
+	}
+
+	// Тип failure — максимальный уровень среди находок
+	top := active[0].Severity
+	var body strings.Builder
+	for _, is := range active {
+		if is.Severity.Rank() > top.Rank() {
+			top = is.Severity
+		}
+		fmt.Fprintf(&body, "[%s] %s:%d %s\n", is.Severity, relFile(root, is.File), is.Line, is.Message)
+		if is.Commit != "" {
+			fmt.Fprintf(&body, "    Commit: %s by %s on %s\n", is.Commit, is.Author, is.Date.Format("2006-01-02"))
+		}
+		if is.Description != "" {
+			fmt.Fprintf(&body, "    %s\n", is.Description)
+		}
+		if is.Suggestion != "" {
+			fmt.Fprintf(&body, "    Fix: %s\n", is.Suggestion)
+		}
+	}
+	msg := active[0].Message
+	if len(active) > 1 {
+		msg = fmt.Sprintf("%s (%d occurrences)", msg, len(active))
+	}
+	tc.Failure = &junitMessage{Message: msg, Type: string(top), Body: body.String()}
+	return tc
+}
+
+func seconds(s float64) string {
+	return fmt.Sprintf("%.3f", s)
+}
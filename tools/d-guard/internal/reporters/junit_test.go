@@ -0,0 +1,112 @@
+package reporters
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devos-os/d-guard/internal/core"
+)
+
+func TestBuildJUnit(t *testing.T) {
+	info := core.ScanInfo{Root: "/repo", Started: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), Duration: 2 * time.Second}
+	issues := []core.Issue{
+		// Два вхождения одного правила в одном файле — один testcase, тип по максимальному уровню
+		{Scanner: "Secrets", ScannerID: "secrets", RuleID: "github-pat", Severity: core.SevMedium, Message: "token", File: "/repo/a.go", Line: 3},
+		{Scanner: "Secrets", ScannerID: "secrets", RuleID: "github-pat", Severity: core.SevCritical, Message: "token", File: "/repo/a.go", Line: 9, Suggestion: "Revoke"},
+		{Scanner: "Secrets", ScannerID: "secrets", RuleID: "github-pat", Severity: core.SevHigh, Message: "token", File: "/repo/b.go", Line: 1},
+		// Только подавленные — skipped
+		{Scanner: "Secrets", ScannerID: "secrets", RuleID: "aws", Severity: core.SevHigh, Message: "aws", File: "/repo/c.go", Line: 5, Suppressed: true, Justification: "fake"},
+		// Сканер без записи о запуске все равно попадает в отчет
+		{Scanner: "Code", ScannerID: "code", RuleID: "todo", Severity: core.SevLow, Message: "todo", File: "/repo/d.go", Line: 2},
+	}
+	runs := []core.ScannerRun{
+		{Name: "secrets", Status: core.StatusOK, Version: "dev"},
+		{Name: "semgrep", Status: core.StatusTimeout, Error: "timed out after 1m"},
+		{Name: "gitleaks", Status: core.StatusSkipped, Reason: "binary not found"},
+		{Name: "docker", Status: core.StatusOK},
+	}
+	out := buildJUnit(issues, runs, info)
+
+	type counts struct{ Tests, Failures, Errors, Skipped int }
+	want := map[string]counts{
+		"d-guard.secrets":  {3, 2, 0, 1},
+		"d-guard.semgrep":  {1, 0, 1, 0},
+		"d-guard.gitleaks": {1, 0, 0, 1},
+		"d-guard.docker":   {1, 0, 0, 0},
+		"d-guard.code":     {1, 1, 0, 0},
+	}
+	if len(out.Suites) != len(want) {
+		t.Fatalf("suites = %d, want %d", len(out.Suites), len(want))
+	}
+	suites := map[string]junitTestSuite{}
+	for _, s := range out.Suites {
+		suites[s.Name] = s
+		got := counts{s.Tests, s.Failures, s.Errors, s.Skipped}
+		if got != want[s.Name] {
+			t.Errorf("%s counts = %+v, want %+v", s.Name, got, want[s.Name])
+		}
+	}
+	if got := (counts{out.Tests, out.Failures, out.Errors, out.Skipped}); got != (counts{7, 3, 1, 2}) {
+		t.Errorf("totals = %+v", got)
+	}
+	if out.Time != "2.000" {
+		t.Errorf("time = %s", out.Time)
+	}
+
+	sec := suites["d-guard.secrets"]
+	if sec.Timestamp != "2024-03-01T12:00:00" || len(sec.Properties) != 1 || sec.Properties[0].Value != "dev" {
+		t.Errorf("secrets suite = %+v", sec)
+	}
+	tc := sec.Cases[2] // ключи отсортированы: aws @ c.go, github-pat @ a.go, github-pat @ b.go
+	if tc.Name != "github-pat @ b.go" || sec.Cases[1].Name != "github-pat @ a.go" {
+		t.Errorf("case order = %s, %s, %s", sec.Cases[0].Name, sec.Cases[1].Name, sec.Cases[2].Name)
+	}
+	f := sec.Cases[1].Failure
+	if f == nil || f.Type != "CRITICAL" || f.Message != "token (2 occurrences)" || !strings.Contains(f.Body, "a.go:9 token") || !strings.Contains(f.Body, "Fix: Revoke") {
+		t.Errorf("failure = %+v", f)
+	}
+	if s := sec.Cases[0].Skipped; s == nil || s.Message != "suppressed: line 5: fake" {
+		t.Errorf("suppressed case = %+v", sec.Cases[0])
+	}
+	if e := suites["d-guard.semgrep"].Cases[0].Error; e == nil || e.Body != "timed out after 1m" {
+		t.Errorf("timeout case = %+v", suites["d-guard.semgrep"].Cases[0])
+	}
+}
+
+func TestJUnitHistoryKey(t *testing.T) {
+	base := core.Issue{ScannerID: "secrets", RuleID: "github-pat", Severity: core.SevHigh, Message: "token", File: "/repo/.env", Line: 1}
+	a, b := base, base
+	a.Commit, b.Commit = "aaaaaaaaaaaaaaaa", "bbbbbbbbbbbbbbbb"
+	suite := buildJUnit([]core.Issue{a, b}, []core.ScannerRun{{Name: "secrets", Status: core.StatusOK}}, core.ScanInfo{Root: "/repo"}).Suites[0]
+	// Находки из разных коммитов — разные testcase
+	if suite.Failures != 2 || suite.Cases[0].Name != "github-pat @ .env @ aaaaaaaaaaaa" {
+		t.Errorf("suite = %+v", suite)
+	}
+}
+
+func TestGenerateJUnit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "junit.xml")
+	runs := []core.ScannerRun{{Name: "secrets", Status: core.StatusOK}}
+	if err := GenerateJUnit(nil, runs, core.ScanInfo{}, path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), xml.Header) {
+		t.Error("missing XML header")
+	}
+	var parsed junitTestSuites
+	if err := xml.Unmarshal(data, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	// Сканер без находок — один зеленый testcase
+	if parsed.Tests != 1 || parsed.Failures != 0 || len(parsed.Suites) != 1 || parsed.Suites[0].Cases[0].Name != "scan" {
+		t.Errorf("report = %+v", parsed)
+	}
+}
@@ -0,0 +1,338 @@
+package reporters
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/devos-os/d-guard/internal/core"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	srcRootID    = "%SRCROOT%"
+)
+
+// --- Модель SARIF 2.1.0 (только используемое подмножество) ---
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                   `json:"tool"`
+	Invocations        []sarifInvocation           `json:"invocations,omitempty"`
+	OriginalURIBaseIDs map[string]sarifArtifactLoc `json:"originalUriBaseIds,omitempty"`
+	Results            []sarifResult               `json:"results"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type sarifNotification struct {
+	Level   string    `json:"level"`
+	Message sarifText `json:"message"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	FullName       string      `json:"fullName,omitempty"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string            `json:"id"`
+	Name                 string            `json:"name,omitempty"`
+	ShortDescription     sarifText         `json:"shortDescription"`
+	FullDescription      *sarifText        `json:"fullDescription,omitempty"`
+	Help                 *sarifText        `json:"help,omitempty"`
+	DefaultConfiguration sarifRuleConfig   `json:"defaultConfiguration"`
+	Properties           map[string]string `json:"properties,omitempty"`
+}
+
+type sarifRuleConfig struct {
+	Level string `json:"level"`
+}
+
+type sarifText struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID       string             `json:"ruleId"`
+	RuleIndex    int                `json:"ruleIndex"`
+	Level        string             `json:"level"`
+	Message      sarifText          `json:"message"`
+	Locations    []sarifLocation    `json:"locations,omitempty"`
+	Suppressions []sarifSuppression `json:"suppressions,omitempty"`
+	Properties   map[string]string  `json:"properties,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLoc `json:"physicalLocation"`
+}
+
+type sarifPhysicalLoc struct {
+	ArtifactLocation sarifArtifactLoc `json:"artifactLocation"`
+	Region           *sarifRegion     `json:"region,omitempty"`
+}
+
+type sarifArtifactLoc struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// toolInfo описывает "драйвер" SARIF, к которому относится сканер d-guard
+type toolInfo struct {
+	Name     string
+	FullName string
+	URI      string
+	Version  string
+}
+
+const dguardURI = "https://github.com/devos-os/d-guard"
+
+// sarifTools сопоставляет сканер из реестра (core.Issue.ScannerID) с инструментом.
+// Нативные модули — каждый в свой run.
+var sarifTools = map[string]toolInfo{
+	"gitleaks": {Name: "Gitleaks", URI: "https://github.com/gitleaks/gitleaks"},
+	"semgrep":  {Name: "Semgrep", URI: "https://semgrep.dev"},
+	"trivy":    {Name: "Trivy", URI: "https://trivy.dev"},
+	"secrets":  {Name: "d-guard-secrets", FullName: "d-guard Native Secrets Scanner", URI: dguardURI},
+	"code":     {Name: "d-guard-code", FullName: "d-guard Code Quality Checks", URI: dguardURI},
+	"docker":   {Name: "d-guard-docker", FullName: "d-guard Docker Analyzer", URI: dguardURI},
+}
+
+// GenerateSARIF сохраняет найденные проблемы в формате SARIF 2.1.0.
+// Пути внутри root записываются относительно %SRCROOT%, чтобы дашборды
+// code scanning могли сопоставить их с файлами репозитория.
+func GenerateSARIF(issues []core.Issue, runs []core.ScannerRun, root, filename string) error {
+	data, err := json.MarshalIndent(buildSARIF(issues, runs, root), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return err
+	}
+	fmt.Printf("\n📄 SARIF Report generated: %s\n", filename)
+	return nil
+}
+
+// buildSARIF строит SARIF-лог: один run на каждый инструмент.
+// Статусы сканеров попадают в invocations, поэтому упавший или пропущенный
+// инструмент виден в дашборде даже без единой находки.
+func buildSARIF(issues []core.Issue, scanners []core.ScannerRun, root string) sarifLog {
+	runs := map[string]*sarifRun{}
+	ruleIdx := map[string]map[string]int{}
+	var order []string
+
+	getRun := func(info toolInfo) *sarifRun {
+		run, ok := runs[info.Name]
+		if !ok {
+			run = &sarifRun{
+				Tool: sarifTool{Driver: sarifDriver{
+					Name: info.Name, FullName: info.FullName, Version: info.Version,
+					InformationURI: info.URI, Rules: []sarifRule{},
+				}},
+				Results: []sarifResult{},
+			}
+			if root != "" {
+				run.OriginalURIBaseIDs = map[string]sarifArtifactLoc{
+					srcRootID: {URI: "file://" + filepath.ToSlash(root) + "/"},
+				}
+			}
+			runs[info.Name] = run
+			ruleIdx[info.Name] = map[string]int{}
+			order = append(order, info.Name)
+		}
+		return run
+	}
+
+	for _, sc := range scanners {
+		info := toolFor(sc.Name, sc.Name)
+		if sc.Version != "" {
+			info.Version = sc.Version
+		}
+		run := getRun(info)
+		inv := sarifInvocation{ExecutionSuccessful: !sc.Incomplete()}
+		switch sc.Status {
+		case core.StatusFailed, core.StatusTimeout:
+			inv.ToolExecutionNotifications = []sarifNotification{{Level: "error", Message: sarifText{Text: sc.Error}}}
+		case core.StatusSkipped:
+			inv.ToolExecutionNotifications = []sarifNotification{{Level: "note", Message: sarifText{Text: "skipped: " + sc.Reason}}}
+		}
+		run.Invocations = append(run.Invocations, inv)
+	}
+
+	for _, is := range issues {
+		info := toolFor(is.ScannerID, is.Scanner)
+		run := getRun(info)
+
+		id := ruleID(is)
+		idx, ok := ruleIdx[info.Name][id]
+		if !ok {
+			idx = len(run.Tool.Driver.Rules)
+			ruleIdx[info.Name][id] = idx
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, newRule(id, is))
+		}
+
+		res := sarifResult{
+			RuleID:    id,
+			RuleIndex: idx,
+			Level:     sarifLevel(is.Severity),
+			Message:   sarifText{Text: is.Message},
+		}
+		if is.File != "" {
+			res.Locations = []sarifLocation{{PhysicalLocation: physicalLocation(is, root)}}
+		}
+		if is.Suppressed {
+			res.Suppressions = []sarifSuppression{{Kind: "inSource", Justification: is.Justification}}
+		}
+		if is.Commit != "" {
+			// --history: строка существует только в указанном коммите
+			res.Message.Text = fmt.Sprintf("%s (commit %s)", is.Message, core.ShortSHA(is.Commit))
+			res.Properties = map[string]string{"commit": is.Commit, "author": is.Author, "date": is.Date.Format(time.RFC3339)}
+		}
+		run.Results = append(run.Results, res)
+	}
+
+	sort.Strings(order)
+	log := sarifLog{Version: sarifVersion, Schema: sarifSchema, Runs: []sarifRun{}}
+	for _, name := range order {
+		log.Runs = append(log.Runs, *runs[name])
+	}
+	return log
+}
+
+func toolFor(id, display string) toolInfo {
+	if info, ok := sarifTools[id]; ok {
+		if info.URI == dguardURI {
+			info.Version = core.Version
+		}
+		return info
+	}
+	// Неизвестный сканер (например, подключенный позже) — отдельный драйвер d-guard
+	name := id
+	if name == "" {
+		name = display
+	}
+	return toolInfo{Name: "d-guard-" + slug(name), FullName: "d-guard " + display, URI: dguardURI, Version: core.Version}
+}
+
+func newRule(id string, is core.Issue) sarifRule {
+	r := sarifRule{
+		ID:                   id,
+		Name:                 is.Message,
+		ShortDescription:     sarifText{Text: is.Message},
+		DefaultConfiguration: sarifRuleConfig{Level: sarifLevel(is.Severity)},
+		Properties: map[string]string{
+			// Используется GitHub code scanning для ранжирования
+			"security-severity": securitySeverity(is.Severity),
+			"d-guard-severity":  string(is.Severity),
+		},
+	}
+	if is.Description != "" {
+		r.FullDescription = &sarifText{Text: is.Description}
+	}
+	if is.Suggestion != "" {
+		r.Help = &sarifText{Text: is.Suggestion}
+	}
+	return r
+}
+
+func physicalLocation(is core.Issue, root string) sarifPhysicalLoc {
+	loc := sarifPhysicalLoc{ArtifactLocation: sarifArtifactLoc{URI: filepath.ToSlash(is.File)}}
+	if rel, ok := relativeTo(root, is.File); ok {
+		loc.ArtifactLocation = sarifArtifactLoc{URI: rel, URIBaseID: srcRootID}
+	}
+	if is.Line > 0 {
+		loc.Region = &sarifRegion{StartLine: is.Line}
+	}
+	return loc
+}
+
+// relativeTo возвращает путь относительно корня репозитория (false — файл вне root)
+func relativeTo(root, file string) (string, bool) {
+	if root == "" || file == "" {
+		return "", false
+	}
+	path := file
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// relFile — путь для отчетов: относительный, если файл внутри репозитория
+func relFile(root, file string) string {
+	if rel, ok := relativeTo(root, file); ok {
+		return rel
+	}
+	return filepath.ToSlash(file)
+}
+
+func ruleID(is core.Issue) string {
+	if is.RuleID != "" {
+		return is.RuleID
+	}
+	return slug(is.Message)
+}
+
+func sarifLevel(s core.Severity) string {
+	switch s {
+	case core.SevCritical, core.SevHigh:
+		return "error"
+	case core.SevMedium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+func securitySeverity(s core.Severity) string {
+	switch s {
+	case core.SevCritical:
+		return "9.5"
+	case core.SevHigh:
+		return "8.0"
+	case core.SevMedium:
+		return "5.5"
+	default:
+		return "2.0"
+	}
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+func slug(s string) string {
+	return strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
@@ -0,0 +1,140 @@
+package reporters
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/devos-os/d-guard/internal/core"
+)
+
+func TestBuildSARIF(t *testing.T) {
+	root := "/repo"
+	issues := []core.Issue{
+		{Scanner: "Secrets", ScannerID: "secrets", RuleID: "github-pat", Severity: core.SevCritical, Message: "GitHub token", File: "/repo/app/config.go", Line: 12, Suggestion: "Revoke it"},
+		{Scanner: "Semgrep", ScannerID: "semgrep", RuleID: "sql-injection", Severity: core.SevHigh, Message: "SQL injection", File: "/repo/db.go", Line: 40},
+		{Scanner: "Secrets", ScannerID: "secrets", RuleID: "aws-access-key", Severity: core.SevMedium, Message: "AWS key", File: "/repo/deploy.sh", Line: 3},
+		{Scanner: "Secrets", ScannerID: "secrets", RuleID: "github-pat", Severity: core.SevCritical, Message: "GitHub token", File: "/repo/README.md", Line: 7, Suppressed: true, Justification: "example"},
+		{Scanner: "Runtime", ScannerID: "runtime", Severity: core.SevLow, Message: "Container runs as root"},
+		{Scanner: "Trivy", ScannerID: "trivy", RuleID: "CVE-2024-1", Severity: core.SevLow, Message: "CVE", File: "/elsewhere/go.sum"},
+	}
+	runs := []core.ScannerRun{
+		{Name: "secrets", Status: core.StatusOK},
+		{Name: "semgrep", Status: core.StatusTimeout, Error: "timed out after 2m", Version: "1.60.0"},
+		{Name: "gitleaks", Status: core.StatusSkipped, Reason: "binary not found"},
+	}
+	log := buildSARIF(issues, runs, root)
+
+	if log.Version != "2.1.0" || log.Schema != sarifSchema {
+		t.Errorf("version/schema = %s %s", log.Version, log.Schema)
+	}
+	byName := map[string]sarifRun{}
+	var names []string
+	for _, r := range log.Runs {
+		byName[r.Tool.Driver.Name] = r
+		names = append(names, r.Tool.Driver.Name)
+	}
+	if want := []string{"Gitleaks", "Semgrep", "Trivy", "d-guard-runtime", "d-guard-secrets"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("runs = %v, want %v", names, want)
+	}
+
+	// ruleIndex указывает на правило своего run, повторное правило не дублируется
+	sec := byName["d-guard-secrets"]
+	var rules []string
+	for _, r := range sec.Tool.Driver.Rules {
+		rules = append(rules, r.ID)
+	}
+	if want := []string{"github-pat", "aws-access-key"}; !reflect.DeepEqual(rules, want) {
+		t.Errorf("secrets rules = %v, want %v", rules, want)
+	}
+	for _, res := range sec.Results {
+		if got := sec.Tool.Driver.Rules[res.RuleIndex].ID; got != res.RuleID {
+			t.Errorf("result %s has ruleIndex %d pointing to %s", res.RuleID, res.RuleIndex, got)
+		}
+	}
+	if got := byName["Semgrep"].Results[0].RuleIndex; got != 0 {
+		t.Errorf("semgrep ruleIndex = %d, want 0 (indexes are per run)", got)
+	}
+
+	// Пути относительно %SRCROOT%, строка в region.startLine
+	first := sec.Results[0]
+	loc := first.Locations[0].PhysicalLocation
+	if loc.ArtifactLocation != (sarifArtifactLoc{URI: "app/config.go", URIBaseID: srcRootID}) || loc.Region == nil || loc.Region.StartLine != 12 {
+		t.Errorf("location = %+v region %+v", loc.ArtifactLocation, loc.Region)
+	}
+	if base := sec.OriginalURIBaseIDs[srcRootID].URI; base != "file:///repo/" {
+		t.Errorf("%%SRCROOT%% = %q", base)
+	}
+	if first.Level != "error" || sec.Results[1].Level != "warning" {
+		t.Errorf("levels = %s, %s", first.Level, sec.Results[1].Level)
+	}
+	rule := sec.Tool.Driver.Rules[0]
+	if rule.Properties["security-severity"] != "9.5" || rule.Help == nil || rule.Help.Text != "Revoke it" {
+		t.Errorf("rule = %+v", rule)
+	}
+
+	// Подавленная находка остается в отчете с suppressions
+	if s := sec.Results[2].Suppressions; len(s) != 1 || s[0].Kind != "inSource" || s[0].Justification != "example" {
+		t.Errorf("suppressions = %+v", s)
+	}
+
+	// Без файла — без locations и с правилом из сообщения; файл вне root — абсолютный URI без region
+	rt := byName["d-guard-runtime"].Results[0]
+	if rt.Locations != nil || rt.RuleID != "container-runs-as-root" {
+		t.Errorf("runtime result = %+v", rt)
+	}
+	tl := byName["Trivy"].Results[0].Locations[0].PhysicalLocation
+	if tl.ArtifactLocation != (sarifArtifactLoc{URI: "/elsewhere/go.sum"}) || tl.Region != nil {
+		t.Errorf("trivy location = %+v", tl)
+	}
+
+	// Статусы сканеров — в invocations, даже без находок
+	inv := byName["Semgrep"].Invocations
+	if len(inv) != 1 || inv[0].ExecutionSuccessful || inv[0].ToolExecutionNotifications[0].Message.Text != "timed out after 2m" {
+		t.Errorf("semgrep invocations = %+v", inv)
+	}
+	if byName["Semgrep"].Tool.Driver.Version != "1.60.0" {
+		t.Errorf("semgrep version = %q", byName["Semgrep"].Tool.Driver.Version)
+	}
+	gl := byName["Gitleaks"]
+	if len(gl.Results) != 0 || gl.Invocations[0].ToolExecutionNotifications[0].Level != "note" {
+		t.Errorf("gitleaks run = %+v", gl)
+	}
+}
+
+func TestSARIFHistory(t *testing.T) {
+	date := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	is := core.Issue{
+		Scanner: "Secrets", ScannerID: "secrets", RuleID: "github-pat", Severity: core.SevHigh, Message: "GitHub token",
+		File: "/repo/old.env", Line: 2, Commit: "0123456789abcdef0123456789abcdef01234567", Author: "dev", Date: date,
+	}
+	res := buildSARIF([]core.Issue{is}, nil, "/repo").Runs[0].Results[0]
+	if res.Message.Text != "GitHub token (commit 0123456789ab)" {
+		t.Errorf("message = %q", res.Message.Text)
+	}
+	if want := map[string]string{"commit": is.Commit, "author": "dev", "date": "2024-03-01T10:00:00Z"}; !reflect.DeepEqual(res.Properties, want) {
+		t.Errorf("properties = %v", res.Properties)
+	}
+}
+
+func TestGenerateSARIF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.sarif")
+	if err := GenerateSARIF(nil, nil, "", path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	// Пустой отчет — все равно валидный SARIF: runs это массив, а не null
+	if runs, ok := raw["runs"].([]any); !ok || len(runs) != 0 || raw["version"] != "2.1.0" || raw["$schema"] == nil {
+		t.Errorf("empty report = %s", data)
+	}
+}
@@ -0,0 +1,99 @@
+package reporters
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/devos-os/d-guard/internal/core"
+)
+
+// PrintSummary выводит таблицу: количество проблем по сканерам и уровням.
+// Подавленные (d-guard:ignore) считаются отдельной колонкой.
+func PrintSummary(w io.Writer, issues []core.Issue) {
+	type row struct {
+		bySev      map[core.Severity]int
+		suppressed int
+	}
+	rows := map[string]*row{}
+	total := &row{bySev: map[core.Severity]int{}}
+
+	for _, is := range issues {
+		name := is.ScannerID
+		if name == "" {
+			name = is.Scanner
+		}
+		r, ok := rows[name]
+		if !ok {
+			r = &row{bySev: map[core.Severity]int{}}
+			rows[name] = r
+		}
+		if is.Suppressed {
+			r.suppressed++
+			total.suppressed++
+			continue
+		}
+		r.bySev[is.Severity]++
+		total.bySev[is.Severity]++
+	}
+
+	var names []string
+	for name := range rows {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SCANNER\tCRITICAL\tHIGH\tMEDIUM\tLOW\tSUPPRESSED\t")
+	line := func(name string, r *row) {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t\n", name,
+			r.bySev[core.SevCritical], r.bySev[core.SevHigh], r.bySev[core.SevMedium], r.bySev[core.SevLow], r.suppressed)
+	}
+	for _, name := range names {
+		line(name, rows[name])
+	}
+	line("TOTAL", total)
+	tw.Flush()
+}
+
+// PrintScanners выводит статус каждого сканера: зеленый прогон должен
+// означать, что все действительно отработало, а не было тихо пропущено
+func PrintScanners(w io.Writer, runs []core.ScannerRun) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SCANNER\tSTATUS\tISSUES\tDURATION\tVERSION\tDETAILS\t")
+	for _, r := range runs {
+		icon := map[core.ScanStatus]string{core.StatusOK: "✅", core.StatusSkipped: "⏭️ ", core.StatusFailed: "❌", core.StatusTimeout: "⏱️ "}[r.Status]
+		details := r.Reason
+		if r.Incomplete() {
+			details = r.Error
+		}
+		duration := "-"
+		if r.Status != core.StatusSkipped {
+			duration = r.Duration.Round(time.Millisecond).String()
+		}
+		version := r.Version
+		if version == "" {
+			version = "-"
+		}
+		if cached := r.CacheHits + r.CacheMisses; cached > 0 && details == "" {
+			details = fmt.Sprintf("cache %d/%d files", r.CacheHits, cached)
+		}
+		fmt.Fprintf(tw, "%s\t%s %s\t%d\t%s\t%s\t%s\t\n", r.Name, icon, r.Status, r.Issues, duration, version, details)
+	}
+	tw.Flush()
+}
+
+// PrintCache выводит итог кэша нативных сканеров (ничего, если кэш не использовался)
+func PrintCache(w io.Writer, runs []core.ScannerRun) {
+	var hits, misses int
+	for _, r := range runs {
+		hits += r.CacheHits
+		misses += r.CacheMisses
+	}
+	if hits+misses == 0 {
+		return
+	}
+	fmt.Fprintf(w, "💾 Cache: %d file results reused, %d rescanned\n", hits, misses)
+}
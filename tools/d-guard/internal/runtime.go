@@ -0,0 +1,115 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/devos-os/d-guard/internal/core"
+	"github.com/devos-os/d-guard/internal/git"
+	"github.com/devos-os/d-guard/internal/modules/container"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+)
+
+// RunRuntime — аудит контейнеров (d-guard runtime). Не зависит от git:
+// .d-guard.yaml берется из корня репозитория, если команда запущена внутри него.
+func RunRuntime(ctx context.Context, cfg core.Config) (*Result, error) {
+	started := time.Now()
+	root, err := git.GetRepoRoot()
+	if err != nil {
+		root = ""
+	}
+	project, err := LoadProject(root, cfg.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+
+	cli, endpoint, closeClient, err := runtimeClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer closeClient()
+
+	fmt.Printf("🐳 Auditing containers via %s...\n", endpoint)
+	if project.Path != "" {
+		fmt.Printf("  ⚙️  Using config %s\n", project.Path)
+	}
+
+	ctx, cancel := withTimeout(ctx, timeoutFor(cfg, "runtime"))
+	defer cancel()
+	run := core.ScannerRun{Name: "runtime", Status: core.StatusOK, Version: container.Engine(ctx, cli)}
+	issues, err := container.Audit(ctx, cli, cfg.RuntimeAll)
+	run.Duration = time.Since(started)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		run.Status, run.Error = core.StatusTimeout, fmt.Sprintf("timed out after %s", timeoutFor(cfg, "runtime"))
+		fmt.Printf("  ⏱️  runtime audit %s\n", run.Error)
+	} else if err != nil {
+		run.Status, run.Error = core.StatusFailed, err.Error()
+		fmt.Printf("  ❌ runtime audit failed: %s\n", run.Error)
+	}
+	for i := range issues {
+		issues[i].ScannerID = "runtime"
+		if sev, ok := project.Override(issues[i].RuleID); ok {
+			issues[i].Severity = sev
+		}
+	}
+	run.Issues = len(issues)
+
+	info := core.ScanInfo{Root: root, Mode: core.ModeRuntime, Base: endpoint, Started: started, Duration: time.Since(started)}
+	return &Result{Root: root, Issues: issues, Project: project, Runs: []core.ScannerRun{run}, Info: info}, nil
+}
+
+// runtimeClient подключается к живому API или поднимает InspectAPI над дампом inspect
+// на unix-сокете во временном каталоге. closeClient закрывает клиент и сервер.
+func runtimeClient(cfg core.Config) (cli *client.Client, endpoint string, closeClient func(), err error) {
+	if cfg.InspectFile == "" {
+		cli, err := container.Connect(cfg.RuntimeHost)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("cannot connect to container engine: %w", err)
+		}
+		return cli, cli.DaemonHost(), func() { cli.Close() }, nil
+	}
+
+	data, err := os.ReadFile(cfg.InspectFile)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	var containers []types.ContainerJSON
+	if err := json.Unmarshal(data, &containers); err != nil {
+		return nil, "", nil, fmt.Errorf("%s: expected output of 'docker inspect' (JSON array): %w", cfg.InspectFile, err)
+	}
+
+	dir, err := os.MkdirTemp("", "d-guard-inspect-")
+	if err != nil {
+		return nil, "", nil, err
+	}
+	sock := filepath.Join(dir, "docker.sock")
+	ln, err := net.Listen("unix", sock)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, "", nil, err
+	}
+	srv := &http.Server{
+		Handler:           container.InspectAPI(containers, types.Version{Version: "inspect dump"}),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go srv.Serve(ln)
+	stop := func() {
+		srv.Close()
+		os.RemoveAll(dir)
+	}
+
+	cli, err = client.NewClientWithOpts(client.WithHost("unix://"+sock), client.WithAPIVersionNegotiation())
+	if err != nil {
+		stop()
+		return nil, "", nil, err
+	}
+	return cli, cfg.InspectFile, func() { cli.Close(); stop() }, nil
+}
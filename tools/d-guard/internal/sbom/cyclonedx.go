@@ -0,0 +1,118 @@
+package sbom
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/devos-os/d-guard/internal/core"
+	"github.com/devos-os/d-guard/internal/license"
+)
+
+// CycloneDX 1.5 (https://cyclonedx.org/docs/1.5/json/) — только используемые поля
+
+type cdxBOM struct {
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	SerialNumber string          `json:"serialNumber"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp"`
+	Tools     cdxTools     `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTools struct {
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	Type       string        `json:"type"`
+	BOMRef     string        `json:"bom-ref,omitempty"`
+	Name       string        `json:"name"`
+	Version    string        `json:"version,omitempty"`
+	PURL       string        `json:"purl,omitempty"`
+	Licenses   []cdxLicense  `json:"licenses,omitempty"`
+	Hashes     []cdxHash     `json:"hashes,omitempty"`
+	Properties []cdxProperty `json:"properties,omitempty"`
+}
+
+// cdxLicense — либо license (id или name), либо expression
+type cdxLicense struct {
+	License    *cdxLicenseID `json:"license,omitempty"`
+	Expression string        `json:"expression,omitempty"`
+}
+
+type cdxLicenseID struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+type cdxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+func writeCycloneDX(w io.Writer, b *BOM) error {
+	const rootRef = "project"
+	doc := cdxBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + b.Serial,
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: b.Created.Format(time.RFC3339),
+			Tools:     cdxTools{Components: []cdxComponent{{Type: "application", Name: "d-guard", Version: core.Version}}},
+			Component: cdxComponent{Type: "application", BOMRef: rootRef, Name: b.Name},
+		},
+		Components:   []cdxComponent{},
+		Dependencies: []cdxDependency{{Ref: rootRef, DependsOn: []string{}}},
+	}
+
+	for _, c := range b.Components {
+		comp := cdxComponent{Type: "library", BOMRef: c.PURL, Name: c.Name, Version: c.Version, PURL: c.PURL}
+		if c.License != "" {
+			switch ok, single := license.Valid(c.License); {
+			case single:
+				comp.Licenses = []cdxLicense{{License: &cdxLicenseID{ID: c.License}}}
+			case ok:
+				comp.Licenses = []cdxLicense{{Expression: c.License}}
+			default:
+				comp.Licenses = []cdxLicense{{License: &cdxLicenseID{Name: c.License}}}
+			}
+		}
+		for _, h := range c.Hashes {
+			if h.Alg == "h1" {
+				// Хэш дерева модуля из go.sum — не хэш архива, в hashes не подходит
+				comp.Properties = append(comp.Properties, cdxProperty{Name: "d-guard:go.sum", Value: h.Value})
+				continue
+			}
+			comp.Hashes = append(comp.Hashes, cdxHash{Alg: h.Alg, Content: h.Value})
+		}
+		for _, f := range c.Files {
+			comp.Properties = append(comp.Properties, cdxProperty{Name: "d-guard:lockfile", Value: f})
+		}
+		doc.Components = append(doc.Components, comp)
+		doc.Dependencies[0].DependsOn = append(doc.Dependencies[0].DependsOn, c.PURL)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(doc)
+}
@@ -0,0 +1,205 @@
+package sbom
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/devos-os/d-guard/internal/license"
+	"github.com/devos-os/d-guard/internal/modules/deps"
+	"github.com/devos-os/d-guard/internal/osv"
+	"github.com/devos-os/d-guard/internal/source"
+)
+
+// Форматы вывода 'd-guard sbom --format'
+const (
+	FormatCycloneDX = "cyclonedx-json"
+	FormatSPDX      = "spdx-json"
+)
+
+var Formats = []string{FormatCycloneDX, FormatSPDX}
+
+// BOM — состав проекта: зависимости из lockfile всех найденных стеков
+type BOM struct {
+	Name       string // Имя проекта (каталог корня)
+	Root       string
+	Created    time.Time
+	Serial     string // UUID документа (CycloneDX serialNumber, SPDX documentNamespace)
+	Components []Component
+	Warnings   []string // Стеки без lockfile и т.п. (в stderr, не в документ)
+}
+
+// Component — пакет с purl, лицензией и контрольными суммами
+type Component struct {
+	Ecosystem string // osv.Go, osv.NPM, osv.Crates, osv.PyPI
+	Name      string
+	Version   string
+	PURL      string
+	License   string      // SPDX-выражение или текст из метаданных пакета (пусто — неизвестна)
+	Hashes    []deps.Hash // Из lockfile: SHA-* артефакта, h1 из go.sum
+	Files     []string    // Lockfile, в которых встретился пакет (относительно Root)
+}
+
+// manifests — файлы стеков без версий (как в d-env general.Analyze): есть манифест,
+// но нет lockfile — зависимости не зафиксированы и в SBOM не попадут
+var manifests = map[string]string{
+	"package.json":   "package-lock.json, npm-shrinkwrap.json or yarn.lock",
+	"Cargo.toml":     "Cargo.lock",
+	"pyproject.toml": "poetry.lock or pinned requirements.txt",
+}
+
+// Build собирает SBOM из lockfile среди files (абсолютные пути внутри root)
+func Build(root string, files []string, fsys source.FS) (*BOM, error) {
+	serial, err := uuid()
+	if err != nil {
+		return nil, err
+	}
+	b := &BOM{Name: filepath.Base(root), Root: root, Created: time.Now().UTC(), Serial: serial}
+	licenses := license.NewResolver(root)
+
+	byPURL := map[string]int{}
+	dirs := map[string]bool{}
+	for _, lock := range deps.Lockfiles(fsys, files) {
+		pkgs, err := deps.Parse(fsys, lock)
+		if err != nil {
+			return nil, err
+		}
+		rel, _ := filepath.Rel(root, lock)
+		dirs[filepath.Dir(lock)] = true
+		for _, p := range pkgs {
+			purl := PURL(p.Ecosystem, p.Name, p.Version)
+			if i, ok := byPURL[purl]; ok {
+				c := &b.Components[i]
+				c.Files = appendNew(c.Files, rel)
+				for _, h := range p.Hashes {
+					c.Hashes = appendHash(c.Hashes, h)
+				}
+				if c.License == "" {
+					c.License = p.License
+				}
+				continue
+			}
+			c := Component{Ecosystem: p.Ecosystem, Name: p.Name, Version: p.Version, PURL: purl, License: p.License, Hashes: p.Hashes, Files: []string{rel}}
+			if c.License == "" {
+				c.License = licenses.Resolve(c.Ecosystem, c.Name, c.Version, filepath.Dir(lock))
+			}
+			byPURL[purl] = len(b.Components)
+			b.Components = append(b.Components, c)
+		}
+	}
+
+	for _, f := range files {
+		hint, ok := manifests[filepath.Base(f)]
+		if !ok || dirs[filepath.Dir(f)] || strings.Contains(filepath.ToSlash(f), "/node_modules/") {
+			continue
+		}
+		rel, _ := filepath.Rel(root, f)
+		b.Warnings = append(b.Warnings, fmt.Sprintf("%s has no lockfile next to it (%s): its dependencies are not in the SBOM", rel, hint))
+	}
+
+	sort.SliceStable(b.Components, func(i, j int) bool {
+		a, c := b.Components[i], b.Components[j]
+		if a.Ecosystem != c.Ecosystem {
+			return a.Ecosystem < c.Ecosystem
+		}
+		if a.Name != c.Name {
+			return a.Name < c.Name
+		}
+		return a.Version < c.Version
+	})
+	return b, nil
+}
+
+// Write пишет SBOM в выбранном формате
+func Write(w io.Writer, format string, b *BOM) error {
+	switch format {
+	case FormatCycloneDX:
+		return writeCycloneDX(w, b)
+	case FormatSPDX:
+		return writeSPDX(w, b)
+	}
+	return fmt.Errorf("unknown SBOM format %q (use %s)", format, strings.Join(Formats, " or "))
+}
+
+// Files — файлы проекта вне git: обход каталога без зависимостей и артефактов сборки
+func Files(root string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			switch d.Name() {
+			case ".git", "node_modules", "target", "dist", "vendor", ".venv", "venv":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		files = append(files, path)
+		return nil
+	})
+	return files, err
+}
+
+// PURL — Package URL (github.com/package-url/purl-spec) для экосистем OSV
+func PURL(ecosystem, name, version string) string {
+	var typ string
+	switch ecosystem {
+	case osv.Go:
+		typ = "golang"
+	case osv.NPM:
+		typ = "npm"
+	case osv.Crates:
+		typ = "cargo"
+	case osv.PyPI:
+		typ = "pypi"
+		name = osv.NormalizeName(osv.PyPI, name)
+	default:
+		typ = strings.ToLower(ecosystem)
+	}
+	// namespace/name: сегменты пути кодируются по отдельности (@scope npm → %40scope)
+	segs := strings.Split(name, "/")
+	for i, s := range segs {
+		segs[i] = escape(s)
+	}
+	return "pkg:" + typ + "/" + strings.Join(segs, "/") + "@" + escape(version)
+}
+
+func escape(s string) string {
+	return strings.NewReplacer("@", "%40", "+", "%2B").Replace(url.PathEscape(s))
+}
+
+func appendNew(list []string, v string) []string {
+	for _, s := range list {
+		if s == v {
+			return list
+		}
+	}
+	return append(list, v)
+}
+
+func appendHash(list []deps.Hash, h deps.Hash) []deps.Hash {
+	for _, x := range list {
+		if x == h {
+			return list
+		}
+	}
+	return append(list, h)
+}
+
+// uuid — случайный UUID v4
+func uuid() (string, error) {
+	var u [16]byte
+	if _, err := rand.Read(u[:]); err != nil {
+		return "", err
+	}
+	u[6] = u[6]&0x0f | 0x40
+	u[8] = u[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16]), nil
+}
@@ -0,0 +1,214 @@
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devos-os/d-guard/internal/modules/deps"
+	"github.com/devos-os/d-guard/internal/osv"
+	"github.com/devos-os/d-guard/internal/source"
+)
+
+func TestPURL(t *testing.T) {
+	tests := []struct {
+		ecosystem, name, version, want string
+	}{
+		{osv.Go, "golang.org/x/net", "v0.20.0", "pkg:golang/golang.org/x/net@v0.20.0"},
+		{osv.Go, "github.com/BurntSushi/toml", "v1.3.2", "pkg:golang/github.com/BurntSushi/toml@v1.3.2"},
+		{osv.Go, "github.com/docker/docker", "v24.0.7+incompatible", "pkg:golang/github.com/docker/docker@v24.0.7%2Bincompatible"},
+		{osv.NPM, "left-pad", "1.3.0", "pkg:npm/left-pad@1.3.0"},
+		{osv.NPM, "@babel/core", "7.24.0", "pkg:npm/%40babel/core@7.24.0"},
+		{osv.NPM, "pkg", "1.0.0-rc.1+build.5", "pkg:npm/pkg@1.0.0-rc.1%2Bbuild.5"},
+		{osv.Crates, "serde_json", "1.0.114", "pkg:cargo/serde_json@1.0.114"},
+		{osv.PyPI, "Django_REST.framework", "3.15.0", "pkg:pypi/django-rest-framework@3.15.0"},
+		{osv.PyPI, "torch", "2.2.0+cpu", "pkg:pypi/torch@2.2.0%2Bcpu"},
+		{"Packagist", "a b", "1.0", "pkg:packagist/a%20b@1.0"},
+	}
+	for _, tt := range tests {
+		if got := PURL(tt.ecosystem, tt.name, tt.version); got != tt.want {
+			t.Errorf("PURL(%s, %s, %s) = %s, want %s", tt.ecosystem, tt.name, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	// Лицензии не должны подтягиваться из кэшей машины, где идут тесты
+	t.Setenv("GOMODCACHE", t.TempDir())
+	t.Setenv("CARGO_HOME", t.TempDir())
+	root := t.TempDir()
+	path := func(rel string) string { return filepath.Join(root, filepath.FromSlash(rel)) }
+
+	lock := `{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"dependencies": {"left-pad": "^1.3.0"}},
+    "node_modules/left-pad": {"version": "1.3.0", "integrity": "sha512-AAAA", "license": "WTFPL OR MIT"},
+    "node_modules/@babel/core": {"version": "7.24.0", "license": {"type": "MIT"}}
+  }
+}`
+	fsys := source.Files{
+		path("go.mod"):                []byte("module example.com/app\n\nrequire golang.org/x/net v0.20.0\n"),
+		path("go.sum"):                []byte("golang.org/x/net v0.20.0 h1:abc=\ngolang.org/x/net v0.20.0/go.mod h1:def=\n"),
+		path("web/package-lock.json"): []byte(lock),
+		path("admin/package-lock.json"): []byte(`{"lockfileVersion": 3, "packages": {
+    "node_modules/left-pad": {"version": "1.3.0", "integrity": "sha1-AAAA"}}}`),
+		path("tools/package.json"): []byte(`{"name": "tools"}`),
+		path("web/package.json"):   []byte(`{"name": "web"}`),
+	}
+	var files []string
+	for f := range fsys {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+
+	b, err := Build(root, files, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, c := range b.Components {
+		got = append(got, c.PURL+" "+c.License+" "+strings.Join(c.Files, ","))
+	}
+	want := []string{
+		"pkg:golang/golang.org/x/net@v0.20.0  go.mod",
+		"pkg:npm/%40babel/core@7.24.0 MIT " + filepath.FromSlash("web/package-lock.json"),
+		// Один пакет из двух lockfile — один компонент, файлы и хэши объединены
+		"pkg:npm/left-pad@1.3.0 WTFPL OR MIT " + filepath.FromSlash("admin/package-lock.json,web/package-lock.json"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("components:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if h := b.Components[2].Hashes; len(h) != 2 {
+		t.Errorf("left-pad hashes = %v, want sha512 and sha1", h)
+	}
+	if h := b.Components[0].Hashes; !reflect.DeepEqual(h, []deps.Hash{{Alg: "h1", Value: "h1:abc="}}) {
+		t.Errorf("go hashes = %v", h)
+	}
+	if len(b.Warnings) != 1 || !strings.HasPrefix(b.Warnings[0], filepath.FromSlash("tools/package.json")+" has no lockfile") {
+		t.Errorf("warnings = %v", b.Warnings)
+	}
+	if len(b.Serial) != 36 || b.Serial[14] != '4' {
+		t.Errorf("serial %q is not a UUID v4", b.Serial)
+	}
+}
+
+func testBOM() *BOM {
+	return &BOM{
+		Name: "app", Created: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), Serial: "3b1f5c2e-8d4a-4c6b-9e7f-0a1b2c3d4e5f",
+		Components: []Component{
+			{Ecosystem: osv.Go, Name: "golang.org/x/net", Version: "v0.20.0", PURL: "pkg:golang/golang.org/x/net@v0.20.0",
+				License: "BSD-3-Clause", Hashes: []deps.Hash{{Alg: "h1", Value: "h1:abc="}}, Files: []string{"go.mod"}},
+			{Ecosystem: osv.NPM, Name: "left-pad", Version: "1.3.0", PURL: "pkg:npm/left-pad@1.3.0",
+				License: "WTFPL OR MIT", Hashes: []deps.Hash{{Alg: "SHA-512", Value: "00ff"}}, Files: []string{"web/package-lock.json"}},
+			{Ecosystem: osv.PyPI, Name: "internal", Version: "1.0", PURL: "pkg:pypi/internal@1.0",
+				License: "Proprietary", Files: []string{"requirements.txt"}},
+			{Ecosystem: osv.Crates, Name: "mystery", Version: "0.1.0", PURL: "pkg:cargo/mystery@0.1.0", Files: []string{"Cargo.lock"}},
+		},
+	}
+}
+
+func TestCycloneDX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatCycloneDX, testBOM()); err != nil {
+		t.Fatal(err)
+	}
+	var doc cdxBOM
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.BOMFormat != "CycloneDX" || doc.SpecVersion != "1.5" || doc.SerialNumber != "urn:uuid:3b1f5c2e-8d4a-4c6b-9e7f-0a1b2c3d4e5f" {
+		t.Errorf("header = %s %s %s", doc.BOMFormat, doc.SpecVersion, doc.SerialNumber)
+	}
+	if doc.Metadata.Timestamp != "2024-03-01T12:00:00Z" || doc.Metadata.Component.Name != "app" {
+		t.Errorf("metadata = %+v", doc.Metadata)
+	}
+
+	comps := doc.Components
+	if len(comps) != 4 {
+		t.Fatalf("components = %d", len(comps))
+	}
+	// Лицензия: один ID, выражение или произвольный текст
+	lic := []cdxLicense{}
+	for _, c := range comps {
+		if c.BOMRef != c.PURL || c.Type != "library" {
+			t.Errorf("component %s: bom-ref %q, type %q", c.Name, c.BOMRef, c.Type)
+		}
+		if len(c.Licenses) > 0 {
+			lic = append(lic, c.Licenses[0])
+		}
+	}
+	wantLic := []cdxLicense{
+		{License: &cdxLicenseID{ID: "BSD-3-Clause"}},
+		{Expression: "WTFPL OR MIT"},
+		{License: &cdxLicenseID{Name: "Proprietary"}},
+	}
+	if !reflect.DeepEqual(lic, wantLic) {
+		t.Errorf("licenses = %+v", lic)
+	}
+	// h1 из go.sum — свойство, а не hash
+	if comps[0].Hashes != nil || comps[0].Properties[0] != (cdxProperty{Name: "d-guard:go.sum", Value: "h1:abc="}) {
+		t.Errorf("go component = %+v", comps[0])
+	}
+	if !reflect.DeepEqual(comps[1].Hashes, []cdxHash{{Alg: "SHA-512", Content: "00ff"}}) {
+		t.Errorf("npm hashes = %+v", comps[1].Hashes)
+	}
+	graph := doc.Dependencies
+	if len(graph) != 1 || graph[0].Ref != "project" || len(graph[0].DependsOn) != 4 || graph[0].DependsOn[1] != "pkg:npm/left-pad@1.3.0" {
+		t.Errorf("dependencies = %+v", graph)
+	}
+}
+
+func TestSPDX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatSPDX, testBOM()); err != nil {
+		t.Fatal(err)
+	}
+	var doc spdxDocument
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.SPDXVersion != "SPDX-2.3" || doc.DataLicense != "CC0-1.0" || doc.SPDXID != "SPDXRef-DOCUMENT" {
+		t.Errorf("header = %s %s %s", doc.SPDXVersion, doc.DataLicense, doc.SPDXID)
+	}
+	if doc.DocumentNamespace != "https://spdx.org/spdxdocs/app-3b1f5c2e-8d4a-4c6b-9e7f-0a1b2c3d4e5f" {
+		t.Errorf("namespace = %s", doc.DocumentNamespace)
+	}
+	if len(doc.Packages) != 5 || doc.Packages[0].SPDXID != "SPDXRef-Project" {
+		t.Fatalf("packages = %+v", doc.Packages)
+	}
+
+	net, pad, internal, mystery := doc.Packages[1], doc.Packages[2], doc.Packages[3], doc.Packages[4]
+	if net.SPDXID != "SPDXRef-Package-1" || net.LicenseDeclared != "BSD-3-Clause" || net.LicenseConcluded != noAssertion || net.Checksums != nil {
+		t.Errorf("go package = %+v", net)
+	}
+	if ref := net.ExternalRefs[0]; ref.ReferenceType != "purl" || ref.ReferenceLocator != "pkg:golang/golang.org/x/net@v0.20.0" {
+		t.Errorf("externalRefs = %+v", net.ExternalRefs)
+	}
+	if pad.LicenseDeclared != "WTFPL OR MIT" || !reflect.DeepEqual(pad.Checksums, []spdxChecksum{{Algorithm: "SHA512", ChecksumValue: "00ff"}}) {
+		t.Errorf("npm package = %+v", pad)
+	}
+	if internal.LicenseDeclared != noAssertion || !strings.Contains(internal.LicenseComments, "Proprietary") {
+		t.Errorf("unknown SPDX id = %+v", internal)
+	}
+	if mystery.LicenseDeclared != noAssertion || mystery.LicenseComments != "" || mystery.SourceInfo != "from Cargo.lock" {
+		t.Errorf("no license = %+v", mystery)
+	}
+
+	rels := doc.Relationships
+	if len(rels) != 5 || rels[0] != (spdxRelationship{"SPDXRef-DOCUMENT", "DESCRIBES", "SPDXRef-Project"}) ||
+		rels[4] != (spdxRelationship{"SPDXRef-Project", "DEPENDS_ON", "SPDXRef-Package-4"}) {
+		t.Errorf("relationships = %+v", rels)
+	}
+}
+
+func TestWriteUnknownFormat(t *testing.T) {
+	if err := Write(&bytes.Buffer{}, "syft-json", testBOM()); err == nil || !strings.Contains(err.Error(), "cyclonedx-json or spdx-json") {
+		t.Errorf("Write = %v", err)
+	}
+}
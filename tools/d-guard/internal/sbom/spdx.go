@@ -0,0 +1,118 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/devos-os/d-guard/internal/core"
+	"github.com/devos-os/d-guard/internal/license"
+)
+
+// SPDX 2.3 (https://spdx.github.io/spdx-spec/v2.3/) — только используемые поля
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name             string            `json:"name"`
+	SPDXID           string            `json:"SPDXID"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	LicenseComments  string            `json:"licenseComments,omitempty"`
+	CopyrightText    string            `json:"copyrightText"`
+	Checksums        []spdxChecksum    `json:"checksums,omitempty"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs,omitempty"`
+	SourceInfo       string            `json:"sourceInfo,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+const noAssertion = "NOASSERTION"
+
+func writeSPDX(w io.Writer, b *BOM) error {
+	const rootID = "SPDXRef-Project"
+	doc := spdxDocument{
+		SPDXVersion: "SPDX-2.3",
+		DataLicense: "CC0-1.0",
+		SPDXID:      "SPDXRef-DOCUMENT",
+		Name:        b.Name,
+		// Уникальный URI документа; по нему ничего не скачивается
+		DocumentNamespace: fmt.Sprintf("https://spdx.org/spdxdocs/%s-%s", escape(b.Name), b.Serial),
+		CreationInfo: spdxCreationInfo{
+			Created:  b.Created.Format(time.RFC3339),
+			Creators: []string{"Tool: d-guard-" + core.Version},
+		},
+		Packages: []spdxPackage{{
+			Name: b.Name, SPDXID: rootID, DownloadLocation: noAssertion,
+			LicenseConcluded: noAssertion, LicenseDeclared: noAssertion, CopyrightText: noAssertion,
+		}},
+		Relationships: []spdxRelationship{{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: rootID}},
+	}
+
+	for i, c := range b.Components {
+		id := fmt.Sprintf("SPDXRef-Package-%d", i+1)
+		pkg := spdxPackage{
+			Name:             c.Name,
+			SPDXID:           id,
+			VersionInfo:      c.Version,
+			DownloadLocation: noAssertion,
+			LicenseConcluded: noAssertion,
+			LicenseDeclared:  noAssertion,
+			CopyrightText:    noAssertion,
+			ExternalRefs:     []spdxExternalRef{{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: c.PURL}},
+			SourceInfo:       "from " + strings.Join(c.Files, ", "),
+		}
+		if ok, _ := license.Valid(c.License); ok {
+			pkg.LicenseDeclared = c.License
+		} else if c.License != "" {
+			pkg.LicenseComments = "Declared license is not a known SPDX identifier: " + c.License
+		}
+		for _, h := range c.Hashes {
+			if h.Alg == "h1" {
+				continue // Хэш дерева модуля из go.sum — в SPDX нет такого алгоритма
+			}
+			pkg.Checksums = append(pkg.Checksums, spdxChecksum{Algorithm: strings.ReplaceAll(h.Alg, "-", ""), ChecksumValue: h.Value})
+		}
+		doc.Packages = append(doc.Packages, pkg)
+		doc.Relationships = append(doc.Relationships, spdxRelationship{SPDXElementID: rootID, RelationshipType: "DEPENDS_ON", RelatedSPDXElement: id})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(doc)
+}
@@ -0,0 +1,65 @@
+package scanner
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	"github.com/devos-os/d-guard/internal/core"
+	"github.com/devos-os/d-guard/internal/source"
+)
+
+// FileFunc проверяет содержимое одного файла
+type FileFunc func(path string, data []byte) []core.Issue
+
+// EachFile читает files из t.Source() и проверяет их fn в пуле из t.Workers горутин.
+// Бинарные файлы и файлы крупнее source.MaxFileSize пропускаются. Находки идут
+// в порядке files, чтобы вывод не зависел от числа воркеров.
+func EachFile(ctx context.Context, t Target, files []string, fn FileFunc) ([]core.Issue, error) {
+	results := make([][]core.Issue, len(files))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < t.workers(len(files)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				data, err := t.Source().ReadFile(files[i])
+				if err != nil || len(data) > source.MaxFileSize || source.IsBinary(data) {
+					continue
+				}
+				results[i] = fn(files[i], data)
+			}
+		}()
+	}
+
+feed:
+	for i := range files {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	var issues []core.Issue
+	for _, r := range results {
+		issues = append(issues, r...)
+	}
+	return issues, ctx.Err()
+}
+
+// workers — размер пула: --workers или число ядер, но не больше числа файлов
+func (t Target) workers(files int) int {
+	n := t.Workers
+	if n <= 0 {
+		n = runtime.NumCPU()
+	}
+	if n > files {
+		n = files
+	}
+	return n
+}
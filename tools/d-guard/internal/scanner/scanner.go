@@ -0,0 +1,229 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/devos-os/d-guard/internal/config"
+	"github.com/devos-os/d-guard/internal/core"
+	"github.com/devos-os/d-guard/internal/source"
+)
+
+// Target описывает, что именно сканировать
+type Target struct {
+	Root    string   // Абсолютный путь к корню репозитория
+	Files   []string // Абсолютные пути файлов: измененные или все файлы репозитория при ScanAll
+	ScanAll bool     // Полный скан репозитория (внешние инструменты сканируют Root сами)
+
+	Config  *config.Project   // Конфигурация проекта (.d-guard.yaml), всегда не nil
+	Options map[string]string // scanners.<name>.* для текущего сканера
+
+	// FS — содержимое файлов для нативных модулей: рабочая копия или staged blobs.
+	// nil — рабочая копия.
+	FS source.FS
+
+	// Offline — внешним инструментам запрещено обновлять базы из сети (--offline)
+	Offline bool
+
+	// Workers — размер пула нативных модулей (EachFile), 0 — число ядер
+	Workers int
+}
+
+// Source возвращает FS цели (рабочая копия, если не задан)
+func (t Target) Source() source.FS {
+	if t.FS == nil {
+		return source.OS{}
+	}
+	return t.FS
+}
+
+// Option возвращает опцию сканера из конфига или значение по умолчанию
+func (t Target) Option(key, def string) string {
+	if v, ok := t.Options[key]; ok && v != "" {
+		return v
+	}
+	return def
+}
+
+// Scanner — единый интерфейс для нативных модулей и внешних инструментов.
+// Модули регистрируют себя через Register в init().
+type Scanner interface {
+	// Name короткий идентификатор для --only/--skip (e.g. "gitleaks", "code")
+	Name() string
+	// Applicable сообщает, имеет ли смысл запуск (есть файлы, установлен бинарник и т.д.)
+	Applicable(t Target) bool
+	// Scan выполняет проверку
+	Scan(ctx context.Context, t Target) ([]core.Issue, error)
+}
+
+// Fallback реализуют сканеры-заменители: они запускаются, только если
+// основной сканер отключен или неприменим (e.g. нативный secrets вместо gitleaks)
+type Fallback interface {
+	FallbackFor() string
+}
+
+// External реализуют внешние инструменты, которые читают файлы с диска сами,
+// а не через Target.FS. В режиме --staged они получают Target с Root во
+// временном checkout индекса.
+type External interface {
+	External()
+}
+
+// FileScanner реализуют нативные модули, чьи находки зависят только от пути
+// и содержимого каждого файла в отдельности. Оркестратор кэширует их результаты
+// по хэшу содержимого и передает в Scan только измененные файлы.
+type FileScanner interface {
+	// RulesHash — отпечаток набора правил с учетом конфига: его смена сбрасывает кэш
+	RulesHash(t Target) (string, error)
+}
+
+// Skipper объясняет, почему сканер неприменим (для отчета)
+type Skipper interface {
+	SkipReason(t Target) string
+}
+
+// Versioned сообщает версию внешнего инструмента
+type Versioned interface {
+	Version(ctx context.Context) string
+}
+
+// SkipError — сканер решил не выполняться уже во время Scan (это не ошибка)
+type SkipError struct{ Reason string }
+
+func (e *SkipError) Error() string { return "skipped: " + e.Reason }
+
+// Skip возвращает SkipError для выхода из Scan
+func Skip(format string, args ...any) error {
+	return &SkipError{Reason: fmt.Sprintf(format, args...)}
+}
+
+// Configurable реализуют сканеры, принимающие опции из .d-guard.yaml
+type Configurable interface {
+	Options() []string
+}
+
+var (
+	mu       sync.RWMutex
+	registry = map[string]Scanner{}
+)
+
+// Register добавляет сканер в реестр. Повторная регистрация имени — ошибка программиста.
+func Register(s Scanner) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	name := s.Name()
+	if _, dup := registry[name]; dup {
+		panic(fmt.Sprintf("scanner %q registered twice", name))
+	}
+	registry[name] = s
+}
+
+// All возвращает все зарегистрированные сканеры, отсортированные по имени
+func All() []Scanner {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	list := make([]Scanner, 0, len(registry))
+	for _, s := range registry {
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name() < list[j].Name() })
+	return list
+}
+
+// Names возвращает имена зарегистрированных сканеров
+func Names() []string {
+	var names []string
+	for _, s := range All() {
+		names = append(names, s.Name())
+	}
+	return names
+}
+
+// Known возвращает имя сканера -> поддерживаемые опции (для валидации конфига)
+func Known() map[string][]string {
+	known := map[string][]string{}
+	for _, s := range All() {
+		var opts []string
+		if c, ok := s.(Configurable); ok {
+			opts = c.Options()
+		}
+		known[s.Name()] = opts
+	}
+	return known
+}
+
+// Select применяет фильтры --only/--skip. Неизвестные имена — ошибка,
+// чтобы опечатка в CI не отключала проверку молча.
+func Select(only, skip []string) ([]Scanner, error) {
+	known := map[string]bool{}
+	for _, n := range Names() {
+		known[n] = true
+	}
+	for _, n := range append(append([]string{}, only...), skip...) {
+		if !known[n] {
+			return nil, fmt.Errorf("unknown scanner %q (available: %s)", n, strings.Join(Names(), ", "))
+		}
+	}
+
+	onlySet := toSet(only)
+	skipSet := toSet(skip)
+
+	var selected []Scanner
+	for _, s := range All() {
+		if len(onlySet) > 0 && !onlySet[s.Name()] {
+			continue
+		}
+		if skipSet[s.Name()] {
+			continue
+		}
+		selected = append(selected, s)
+	}
+	return selected, nil
+}
+
+// Skipped — сканер, который не будет запущен, с причиной
+type Skipped struct {
+	Scanner Scanner
+	Reason  string
+}
+
+// Plan отбирает сканеры, которые действительно будут запущены для target:
+// применимые, и без fallback-сканеров, чей основной сканер уже в работе.
+func Plan(selected []Scanner, t Target) (run []Scanner, skipped []Skipped) {
+	var applicable []Scanner
+	active := map[string]bool{}
+	for _, s := range selected {
+		if s.Applicable(t) {
+			applicable = append(applicable, s)
+			active[s.Name()] = true
+			continue
+		}
+		reason := "not applicable"
+		if sk, ok := s.(Skipper); ok {
+			reason = sk.SkipReason(t)
+		}
+		skipped = append(skipped, Skipped{Scanner: s, Reason: reason})
+	}
+
+	for _, s := range applicable {
+		if fb, ok := s.(Fallback); ok && active[fb.FallbackFor()] {
+			skipped = append(skipped, Skipped{Scanner: s, Reason: "fallback for " + fb.FallbackFor() + ", which is running"})
+			continue
+		}
+		run = append(run, s)
+	}
+	return run, skipped
+}
+
+func toSet(list []string) map[string]bool {
+	set := map[string]bool{}
+	for _, v := range list {
+		set[v] = true
+	}
+	return set
+}
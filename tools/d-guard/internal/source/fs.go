@@ -0,0 +1,29 @@
+package source
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FS — откуда читать содержимое файлов: рабочая копия или индекс git (--staged).
+// Пути абсолютные, как в scanner.Target.Files.
+type FS interface {
+	ReadFile(path string) ([]byte, error)
+}
+
+// OS читает файлы рабочей копии
+type OS struct{}
+
+func (OS) ReadFile(path string) ([]byte, error) { return os.ReadFile(path) }
+
+// Files — содержимое в памяти (e.g. staged blobs). Файлов вне набора "нет":
+// в staged-режиме рабочая копия не должна подмешиваться.
+type Files map[string][]byte
+
+func (m Files) ReadFile(path string) ([]byte, error) {
+	if data, ok := m[filepath.Clean(path)]; ok {
+		return data, nil
+	}
+	return nil, &fs.PathError{Op: "read", Path: path, Err: fs.ErrNotExist}
+}
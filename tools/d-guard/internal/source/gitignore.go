@@ -0,0 +1,117 @@
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ignorePattern — строка .gitignore, скомпилированная в regexp
+type ignorePattern struct {
+	base     string // Каталог .gitignore относительно корня ("" — корень)
+	re       *regexp.Regexp
+	negate   bool // !pattern — вернуть ранее исключенное
+	dirOnly  bool // pattern/ — только каталоги
+	anchored bool // Содержит "/": путь относительно base, иначе — имя на любой глубине
+}
+
+// parseIgnore разбирает .gitignore из каталога base (слэши, относительно корня)
+func parseIgnore(base string, data []byte) []ignorePattern {
+	var patterns []ignorePattern
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p := ignorePattern{base: base}
+		if strings.HasPrefix(line, "!") {
+			p.negate, line = true, line[1:]
+		} else if strings.HasPrefix(line, `\`) {
+			line = line[1:] // \# и \! — буквальные символы
+		}
+		if strings.HasSuffix(line, "/") {
+			p.dirOnly, line = true, strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		p.anchored = strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+		re, err := regexp.Compile("^" + globRegexp(line) + "$")
+		if err != nil {
+			continue
+		}
+		p.re = re
+		patterns = append(patterns, p)
+	}
+	return patterns
+}
+
+// globRegexp переводит glob .gitignore (*, ?, [..], **) в regexp
+func globRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "/**") && i+3 == len(glob):
+			b.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// ignored: rel — путь относительно корня со слэшами. Последний совпавший шаблон решает,
+// шаблоны вложенных .gitignore идут после родительских и потому важнее.
+func ignored(patterns []ignorePattern, rel string, dir bool) bool {
+	result := false
+	for _, p := range patterns {
+		if p.dirOnly && !dir {
+			continue
+		}
+		sub := rel
+		if p.base != "" {
+			if !strings.HasPrefix(rel, p.base+"/") {
+				continue
+			}
+			sub = rel[len(p.base)+1:]
+		}
+		if !p.anchored {
+			sub = path.Base(sub)
+		}
+		if p.re.MatchString(sub) {
+			result = !p.negate
+		}
+	}
+	return result
+}
@@ -0,0 +1,75 @@
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"sync"
+)
+
+// Lines кэширует содержимое файлов построчно. Используется пост-обработкой
+// результатов (baseline, inline-подавления), где одну и ту же строку
+// запрашивают для многих проблем.
+type Lines struct {
+	root  string
+	fs    FS
+	mu    sync.Mutex
+	files map[string][]string
+}
+
+// NewLines создает кэш над рабочей копией; относительные пути разрешаются от root
+func NewLines(root string) *Lines {
+	return NewLinesFS(root, OS{})
+}
+
+// NewLinesFS создает кэш над произвольным источником (e.g. staged blobs)
+func NewLinesFS(root string, fsys FS) *Lines {
+	return &Lines{root: root, fs: fsys, files: map[string][]string{}}
+}
+
+// Root корень репозитория
+func (l *Lines) Root() string { return l.root }
+
+// Line возвращает строку n (с 1) файла path
+func (l *Lines) Line(path string, n int) (string, bool) {
+	lines := l.File(path)
+	if n <= 0 || n > len(lines) {
+		return "", false
+	}
+	return lines[n-1], true
+}
+
+// File возвращает все строки файла (nil, если файл не читается)
+func (l *Lines) File(path string) []string {
+	if path == "" {
+		return nil
+	}
+	abs := path
+	if !filepath.IsAbs(abs) {
+		abs = filepath.Join(l.root, abs)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lines, ok := l.files[abs]
+	if !ok {
+		lines = readLines(l.fs, abs)
+		l.files[abs] = lines
+	}
+	return lines
+}
+
+func readLines(fsys FS, path string) []string {
+	data, err := fsys.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var lines []string
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	return lines
+}
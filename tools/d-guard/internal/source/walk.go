@@ -0,0 +1,77 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// MaxFileSize — файлы крупнее (дампы, артефакты сборки) нативные модули не сканируют
+const MaxFileSize = 10 << 20
+
+// Walk перечисляет файлы репозитория для полного скана (--all): как
+// 'git ls-files --cached --others --exclude-standard', но без git — учитываются
+// .gitignore на всех уровнях и .git/info/exclude. Каталог .git, символические
+// ссылки и файлы крупнее MaxFileSize пропускаются. Пути абсолютные.
+func Walk(ctx context.Context, root string) ([]string, error) {
+	var patterns []ignorePattern
+	if data, err := os.ReadFile(filepath.Join(root, ".git", "info", "exclude")); err == nil {
+		patterns = parseIgnore("", data)
+	}
+
+	var files []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Нечитаемый каталог не должен обрывать обход всего репозитория
+			if d != nil && d.IsDir() && path != root {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		rel, _ := filepath.Rel(root, path)
+		rel = filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			if path == root {
+				patterns = append(patterns, readIgnore(path, "")...)
+				return nil
+			}
+			if d.Name() == ".git" || ignored(patterns, rel, true) {
+				return filepath.SkipDir
+			}
+			// Вложенный .gitignore действует только внутри своего каталога
+			patterns = append(patterns, readIgnore(path, rel)...)
+			return nil
+		}
+		if !d.Type().IsRegular() || ignored(patterns, rel, false) {
+			return nil
+		}
+		if info, err := d.Info(); err != nil || info.Size() > MaxFileSize {
+			return nil
+		}
+		files = append(files, path)
+		return nil
+	})
+	return files, err
+}
+
+func readIgnore(dir, rel string) []ignorePattern {
+	data, err := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		return nil
+	}
+	return parseIgnore(rel, data)
+}
+
+// IsBinary — эвристика git: NUL-байт в первых 8000 байтах
+func IsBinary(data []byte) bool {
+	if len(data) > 8000 {
+		data = data[:8000]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
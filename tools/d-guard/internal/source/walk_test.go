@@ -0,0 +1,172 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// ignoreTree — файлы фикстуры: .gitignore на нескольких уровнях, отрицания,
+// якоря, **, классы символов и .git/info/exclude
+var ignoreTree = map[string]string{
+	".gitignore": `# комментарий
+*.log
+!keep.log
+/root-only.txt
+build/
+doc/*.txt
+**/cache
+logs/**
+!logs/keep.txt
+a/**/b
+[Dd]ebug/
+file[0-9].tmp
+?.o
+\#hash
+tmp
+dist/
+!dist/
+`,
+	".git/info/exclude": "local-notes.md\n",
+
+	"main.go":                "",
+	"app.log":                "",
+	"keep.log":               "",
+	"sub/trace.log":          "",
+	"sub/keep.log":           "",
+	"root-only.txt":          "",
+	"sub/root-only.txt":      "",
+	"build/out.bin":          "",
+	"sub/build/out.bin":      "",
+	"build.go":               "",
+	"doc/readme.txt":         "",
+	"doc/guide/intro.txt":    "",
+	"doc/index.md":           "",
+	"cache/x":                "",
+	"deep/er/cache/y":        "",
+	"logs/a.txt":             "",
+	"logs/keep.txt":          "",
+	"logs/sub/keep.txt":      "",
+	"a/b":                    "",
+	"a/x/y/b":                "",
+	"a/x/c":                  "",
+	"Debug/app.exe":          "",
+	"debug/app.exe":          "",
+	"DEBUG/app.exe":          "",
+	"file1.tmp":              "",
+	"fileA.tmp":              "",
+	"x.o":                    "",
+	"xy.o":                   "",
+	"#hash":                  "",
+	"tmp/t":                  "",
+	"sub/tmp":                "",
+	"dist/bundle.js":         "",
+	"local-notes.md":         "",
+	"sub/local-notes.md":     "",
+	"pkg/.gitignore":         "/gen\n*.pb.go\n!api.pb.go\nnested/x.txt\n",
+	"pkg/gen/code.go":        "",
+	"pkg/sub/gen/code.go":    "",
+	"pkg/a.pb.go":            "",
+	"pkg/api.pb.go":          "",
+	"pkg/sub/b.pb.go":        "",
+	"pkg/nested/x.txt":       "",
+	"pkg/other/nested/x.txt": "",
+	"nested/x.txt":           "",
+	"pkg/sub/.gitignore":     "!b.pb.go\n",
+}
+
+// Walk должен давать тот же список, что 'git ls-files --cached --others --exclude-standard'.
+// Отслеживаемые, но игнорируемые файлы (git add -f) не проверяются: без git их не отличить.
+func TestWalkMatchesGitLsFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	root := t.TempDir()
+	// Глобальные настройки (core.excludesFile) не должны влиять на git в тесте
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	git := func(args ...string) []byte {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+		return out
+	}
+
+	git("init", "-q")
+	for name, content := range ignoreTree {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("add", "main.go", "doc/index.md") // Часть файлов в индексе (--cached)
+
+	var want []string
+	for _, p := range bytes.Split(bytes.TrimRight(git("ls-files", "-z", "--cached", "--others", "--exclude-standard"), "\x00"), []byte{0}) {
+		want = append(want, string(p))
+	}
+
+	files, err := Walk(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range files {
+		rel, _ := filepath.Rel(root, f)
+		got = append(got, filepath.ToSlash(rel))
+	}
+
+	sort.Strings(want)
+	sort.Strings(got)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Walk differs from git ls-files\n got: %s\nwant: %s", strings.Join(got, " "), strings.Join(want, " "))
+	}
+}
+
+func TestWalkSkips(t *testing.T) {
+	root := t.TempDir()
+	write := func(name string, size int) {
+		t.Helper()
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("small.txt", 10)
+	write("dump.sql", MaxFileSize+1)
+	write(".git/config", 10)
+	if err := os.Symlink(filepath.Join(root, "small.txt"), filepath.Join(root, "link.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := Walk(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{filepath.Join(root, "small.txt")}; !reflect.DeepEqual(files, want) {
+		t.Errorf("Walk = %v, want %v", files, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Walk(ctx, root); err == nil {
+		t.Error("Walk with canceled context must fail")
+	}
+}
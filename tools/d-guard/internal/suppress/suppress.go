@@ -0,0 +1,120 @@
+package suppress
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/devos-os/d-guard/internal/core"
+	"github.com/devos-os/d-guard/internal/source"
+)
+
+// Синтаксис (в комментарии любого языка, на той же строке или отдельной строкой-комментарием перед ней):
+//
+//	d-guard:ignore <rule>[,<rule>...] reason="почему это допустимо"
+//
+// <rule> — RuleID проблемы, "*" подавляет любые правила на строке. Без <rule>
+// (в том числе "d-guard:ignore reason=..." и "/* d-guard:ignore */") подавляется любое правило.
+var directive = regexp.MustCompile(`d-guard:ignore(?:\s+([\w.*-]+(?:,[\w.*-]+)*)(?:\s|$))?(?:\s*reason="([^"]*)")?`)
+
+// NoReason — обоснование по умолчанию, если reason не указан
+const NoReason = "no reason given"
+
+// Apply помечает проблемы, подавленные inline-комментарием, как Suppressed.
+// Работает одинаково для нативных модулей и внешних инструментов (Gitleaks,
+// Semgrep, Trivy): нужен только путь к файлу и номер строки. lines — рабочая
+// копия или staged-содержимое, в зависимости от режима.
+func Apply(issues []core.Issue, lines *source.Lines) []core.Issue {
+	for i := range issues {
+		is := &issues[i]
+		// Находки из истории привязаны к старой версии файла — их проверяет сам сканер (Line)
+		if is.File == "" || is.Line <= 0 || is.Suppressed || is.Commit != "" {
+			continue
+		}
+		// Сначала та же строка, затем предыдущая
+		reason, ok := "", false
+		if text, found := lines.Line(is.File, is.Line); found {
+			reason, ok = Line(text, is.RuleID)
+		}
+		if text, found := lines.Line(is.File, is.Line-1); found && !ok {
+			reason, ok = LineAbove(text, is.RuleID)
+		}
+		if ok {
+			is.Suppressed = true
+			is.Justification = reason
+		}
+	}
+	return issues
+}
+
+// Line проверяет одну строку: есть ли в ней d-guard:ignore для правила.
+// Нужна, когда строки нет в рабочей копии (e.g. добавленные строки коммита).
+func Line(text, ruleID string) (reason string, ok bool) {
+	return match(text, ruleID)
+}
+
+// LineAbove — директива на строке перед находкой. Действует, только если строка —
+// один комментарий: "x := 1 // d-guard:ignore" подавляет свою строку, но не следующую.
+func LineAbove(text, ruleID string) (reason string, ok bool) {
+	if !commentOnly(text) {
+		return "", false
+	}
+	return match(text, ruleID)
+}
+
+// commentMarkers — начало комментария и его конец для блочных ("" — до конца строки)
+var commentMarkers = []struct{ open, close string }{
+	{"//", ""}, {"#", ""}, {"--", ""}, {"/*", "*/"}, {"<!--", "-->"},
+}
+
+// commentOnly: в строке нет кода, только комментарий
+func commentOnly(text string) bool {
+	t := strings.TrimSpace(text)
+	for _, m := range commentMarkers {
+		if !strings.HasPrefix(t, m.open) {
+			continue
+		}
+		if m.close == "" {
+			return true
+		}
+		_, after, closed := strings.Cut(t[len(m.open):], m.close)
+		return !closed || strings.TrimSpace(after) == ""
+	}
+	return false
+}
+
+// Active возвращает только неподавленные проблемы
+func Active(issues []core.Issue) []core.Issue {
+	var active []core.Issue
+	for _, is := range issues {
+		if !is.Suppressed {
+			active = append(active, is)
+		}
+	}
+	return active
+}
+
+func match(line, ruleID string) (string, bool) {
+	for _, m := range directive.FindAllStringSubmatch(line, -1) {
+		if !ruleMatches(m[1], ruleID) {
+			continue
+		}
+		reason := strings.TrimSpace(m[2])
+		if reason == "" {
+			reason = NoReason
+		}
+		return reason, true
+	}
+	return "", false
+}
+
+func ruleMatches(list, ruleID string) bool {
+	if list == "" || list == "*" {
+		return true
+	}
+	for _, r := range strings.Split(list, ",") {
+		if strings.EqualFold(strings.TrimSpace(r), ruleID) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package suppress
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/devos-os/d-guard/internal/core"
+	"github.com/devos-os/d-guard/internal/source"
+)
+
+func TestLine(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		rule   string
+		ok     bool
+		reason string
+	}{
+		{"no rule", `x := 1 // d-guard:ignore`, "any-rule", true, NoReason},
+		{"wildcard", `# d-guard:ignore *`, "any-rule", true, NoReason},
+		{"single rule", `// d-guard:ignore go-weak-crypto`, "go-weak-crypto", true, NoReason},
+		{"other rule", `// d-guard:ignore go-weak-crypto`, "go-sql-concat", false, ""},
+		{"several rules", `// d-guard:ignore go-weak-crypto,go-sql-concat`, "go-sql-concat", true, NoReason},
+		{"rule case", `// d-guard:ignore GO-SQL-CONCAT`, "go-sql-concat", true, NoReason},
+		{"reason only", `// d-guard:ignore reason="test fixture"`, "any-rule", true, "test fixture"},
+		{"rule and reason", `// d-guard:ignore aws-access-key reason="revoked key"`, "aws-access-key", true, "revoked key"},
+		{"rule and reason, other rule", `// d-guard:ignore aws-access-key reason="revoked key"`, "github-pat", false, ""},
+		{"wildcard and reason", `/* d-guard:ignore * reason="legacy" */`, "any-rule", true, "legacy"},
+		{"block comment", `/* d-guard:ignore */`, "any-rule", true, NoReason},
+		{"html comment", `<!-- d-guard:ignore -->`, "any-rule", true, NoReason},
+		{"block comment with rule", `/* d-guard:ignore go-sql-concat */`, "go-sql-concat", true, NoReason},
+		{"empty reason", `// d-guard:ignore reason=""`, "any-rule", true, NoReason},
+		{"no directive", `// d-guard: ignore`, "any-rule", false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, ok := Line(tt.text, tt.rule)
+			if ok != tt.ok || reason != tt.reason {
+				t.Errorf("Line(%q, %q) = %q, %v; want %q, %v", tt.text, tt.rule, reason, ok, tt.reason, tt.ok)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	root := t.TempDir()
+	file := filepath.Join(root, "main.go")
+	lines := source.NewLinesFS(root, source.Files{file: []byte(
+		"package main\n" +
+			"\n" +
+			"// d-guard:ignore go-weak-crypto reason=\"checksum, not security\"\n" +
+			"var a = md5.Sum(nil)\n" +
+			"var b = md5.Sum(nil) // d-guard:ignore\n" +
+			"\n" +
+			"var c = md5.Sum(nil)\n" +
+			"// d-guard:ignore go-sql-concat\n" +
+			"var d = md5.Sum(nil)\n" +
+			"var e = md5.Sum(nil) // d-guard:ignore\n" +
+			"var f = md5.Sum(nil)\n",
+	)})
+
+	issues := []core.Issue{
+		{RuleID: "go-weak-crypto", File: file, Line: 4}, // Предыдущая строка
+		{RuleID: "go-weak-crypto", File: file, Line: 5}, // Та же строка
+		{RuleID: "go-weak-crypto", File: file, Line: 7}, // Директива двумя строками выше не действует
+		{RuleID: "go-weak-crypto", File: file, Line: 9}, // Директива для другого правила
+		{RuleID: "go-weak-crypto", File: file, Line: 4, Commit: "abc123"},
+		{RuleID: "go-weak-crypto", File: file, Line: 10}, // Директива в конце строки с кодом — только для своей строки
+		{RuleID: "go-weak-crypto", File: file, Line: 11},
+	}
+	got := Apply(issues, lines)
+
+	want := []struct {
+		suppressed bool
+		reason     string
+	}{
+		{true, "checksum, not security"},
+		{true, NoReason},
+		{false, ""},
+		{false, ""},
+		{false, ""},
+	}
+	for i, w := range want {
+		if got[i].Suppressed != w.suppressed || got[i].Justification != w.reason {
+			t.Errorf("issue %d (line %d): suppressed=%v reason=%q; want %v %q",
+				i, got[i].Line, got[i].Suppressed, got[i].Justification, w.suppressed, w.reason)
+		}
+	}
+	if n := len(Active(got)); n != 4 {
+		t.Errorf("Active: %d issues, want 4", n)
+	}
+}
+
+func TestLineAbove(t *testing.T) {
+	tests := []struct {
+		text string
+		ok   bool
+	}{
+		{`// d-guard:ignore`, true},
+		{`	# d-guard:ignore reason="fixture"`, true},
+		{`-- d-guard:ignore`, true},
+		{`/* d-guard:ignore */`, true},
+		{`/* d-guard:ignore`, true},
+		{`<!-- d-guard:ignore -->`, true},
+		{`a := "x" // d-guard:ignore`, false},
+		{`key = "abc"  # d-guard:ignore`, false},
+		{`/* d-guard:ignore */ call()`, false},
+		{`SELECT 1; -- d-guard:ignore`, false},
+		{`// nothing here`, false},
+	}
+	for _, tt := range tests {
+		if _, ok := LineAbove(tt.text, "any-rule"); ok != tt.ok {
+			t.Errorf("LineAbove(%q) = %v, want %v", tt.text, ok, tt.ok)
+		}
+	}
+}
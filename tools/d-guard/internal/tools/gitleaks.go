@@ -1,13 +1,111 @@
 package tools
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"os"
-	"os/exec"
+	"strings"
 
+	"github.com/devos-os/d-guard/internal/config"
 	"github.com/devos-os/d-guard/internal/core"
+	"github.com/devos-os/d-guard/internal/proc"
+	"github.com/devos-os/d-guard/internal/scanner"
 )
 
+func init() { scanner.Register(gitleaksScanner{}) }
+
+type gitleaksScanner struct{}
+
+func (gitleaksScanner) Name() string { return "gitleaks" }
+func (gitleaksScanner) External()    {}
+
+// config — путь к собственному gitleaks.toml
+func (gitleaksScanner) Options() []string { return []string{"config"} }
+
+func (gitleaksScanner) Applicable(t scanner.Target) bool {
+	_, ok := Lookup("gitleaks")
+	return ok
+}
+
+func (gitleaksScanner) SkipReason(t scanner.Target) string { return "gitleaks is not installed" }
+
+func (gitleaksScanner) Version(ctx context.Context) string {
+	return ToolVersion(ctx, "gitleaks")
+}
+
+func (gitleaksScanner) Scan(ctx context.Context, t scanner.Target) ([]core.Issue, error) {
+	cfgPath := t.Option("config", "")
+	custom := t.Config.Secrets.Rules
+
+	// secrets.rules из .d-guard.yaml передаем gitleaks как расширение дефолтного набора
+	if cfgPath == "" && len(custom) > 0 {
+		path, err := writeGitleaksConfig(custom)
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(path)
+		cfgPath = path
+	}
+
+	issues, err := RunGitleaks(ctx, t.Root, t.Files, cfgPath)
+	if err != nil {
+		return nil, err
+	}
+	for i := range issues {
+		for _, r := range custom {
+			if r.ID == issues[i].RuleID && r.Severity != "" {
+				issues[i].Severity, _ = core.ParseSeverity(r.Severity)
+			}
+		}
+	}
+	return issues, nil
+}
+
+// writeGitleaksConfig создает временный gitleaks.toml: дефолтные правила + пользовательские
+func writeGitleaksConfig(rules []config.SecretRule) (string, error) {
+	f, err := os.CreateTemp("", "d-guard-gitleaks-*.toml")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var sb strings.Builder
+	sb.WriteString("[extend]\nuseDefault = true\n")
+	for _, r := range rules {
+		// TOML literal strings ('''...''') не требуют экранирования regex
+		fmt.Fprintf(&sb, "\n[[rules]]\nid = %q\ndescription = %q\nregex = '''%s'''\n", r.ID, r.Description, r.Regex)
+		if r.SecretGroup > 0 {
+			fmt.Fprintf(&sb, "secretGroup = %d\n", r.SecretGroup)
+		}
+		if r.Entropy > 0 {
+			fmt.Fprintf(&sb, "entropy = %g\n", r.Entropy)
+		}
+		if len(r.Keywords) > 0 {
+			fmt.Fprintf(&sb, "keywords = [%s]\n", quoteList(r.Keywords))
+		}
+		if len(r.Allowlist) > 0 {
+			var regexes []string
+			for _, a := range r.Allowlist {
+				regexes = append(regexes, "'''"+a+"'''")
+			}
+			fmt.Fprintf(&sb, "[rules.allowlist]\nregexes = [%s]\n", strings.Join(regexes, ", "))
+		}
+	}
+	if _, err := f.WriteString(sb.String()); err != nil {
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+func quoteList(list []string) string {
+	var quoted []string
+	for _, v := range list {
+		quoted = append(quoted, fmt.Sprintf("%q", v))
+	}
+	return strings.Join(quoted, ", ")
+}
+
 type GitleaksResult struct {
 	Description string `json:"Description"`
 	File        string `json:"File"`
@@ -17,28 +115,44 @@ type GitleaksResult struct {
 	RuleID      string `json:"RuleID"`
 }
 
-func RunGitleaks(root string, files []string) []core.Issue {
+func RunGitleaks(ctx context.Context, root string, files []string, config string) ([]core.Issue, error) {
 	bin, err := EnsureTool("gitleaks")
 	if err != nil {
-		// Fallback на нативный сканер, если нет gitleaks
-		return nil 
+		// Оркестратор запустит нативный fallback (secrets)
+		return nil, scanner.Skip("gitleaks unavailable: %v", err)
 	}
 
-	// Создаем временный файл для отчета
-	tmpReport := "gitleaks-report.json"
+	// Создаем временный файл для отчета (вне репозитория, чтобы не попасть в скан)
+	tmp, err := os.CreateTemp("", "d-guard-gitleaks-*.json")
+	if err != nil {
+		return nil, err
+	}
+	tmp.Close()
+	tmpReport := tmp.Name()
 	defer os.Remove(tmpReport)
 
 	// Запуск: gitleaks detect --source . --no-git --report-path ...
 	// --no-git используем для проверки текущего состояния файлов (unstaged/untracked)
-	cmd := exec.Command(bin, "detect", "--source", root, "--no-git", "--report-path", tmpReport, "--exit-code", "0")
-	cmd.Run() // Игнорируем ошибку exit code, так как нам нужен отчет
+	args := []string{"detect", "--source", root, "--no-git", "--report-path", tmpReport, "--exit-code", "0"}
+	if config != "" {
+		args = append(args, "--config", config)
+	}
+	cmd := proc.Command(ctx, bin, args...)
+	// С --exit-code 0 любой ненулевой код — это сбой самого gitleaks
+	if _, err := cmd.Output(); err != nil {
+		return nil, fmt.Errorf("gitleaks: %s", describeExit(err))
+	}
 
 	// Читаем отчет
 	data, err := os.ReadFile(tmpReport)
-	if err != nil { return nil }
+	if err != nil {
+		return nil, fmt.Errorf("gitleaks report: %w", err)
+	}
 
 	var results []GitleaksResult
-	json.Unmarshal(data, &results)
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("gitleaks report: %w", err)
+	}
 
 	var issues []core.Issue
 	for _, res := range results {
@@ -54,6 +168,7 @@ func RunGitleaks(root string, files []string) []core.Issue {
 
 		issues = append(issues, core.Issue{
 			Scanner:     "Gitleaks",
+			RuleID:      res.RuleID,
 			Severity:    core.SevCritical,
 			Message:     res.Description,
 			File:        res.File,
@@ -62,5 +177,5 @@ func RunGitleaks(root string, files []string) []core.Issue {
 			Suggestion:  "Revoke this secret immediately.",
 		})
 	}
-	return issues
+	return issues, nil
 }
\ No newline at end of file
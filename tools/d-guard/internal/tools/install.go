@@ -0,0 +1,248 @@
+package tools
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Установленные инструменты: ~/.cache/devos/bin/<tool>/<version>/<binary> и
+// install.json рядом — откуда и с какими суммами инструмент был установлен.
+
+const installRecord = "install.json"
+
+// Installed — запись об установке закрепленной версии
+type Installed struct {
+	Tool          string    `json:"tool"`
+	Version       string    `json:"version"`
+	Source        string    `json:"source"`        // URL или файл зеркала
+	ArchiveSHA256 string    `json:"archiveSha256"` // Проверенная сумма архива (из манифеста)
+	BinarySHA256  string    `json:"binarySha256"`  // Сумма распакованного бинарника (для verify)
+	Installed     time.Time `json:"installed"`
+	Path          string    `json:"-"`
+}
+
+// InstallOptions — откуда брать архивы
+type InstallOptions struct {
+	Mirror  string // Каталог или базовый URL с архивами под именами из манифеста
+	Offline bool   // Только локальные файлы (--offline)
+	Force   bool   // Переустановить, даже если версия уже установлена
+}
+
+// pinnedPath — путь к бинарнику закрепленной версии
+func pinnedPath(name string, t *Tool) string {
+	return filepath.Join(binDir(), name, t.Version, t.binary(name))
+}
+
+func (t *Tool) binary(name string) string {
+	if t.Binary != "" {
+		return t.Binary
+	}
+	return name
+}
+
+// Install скачивает (или берет из зеркала) архив, сверяет SHA-256 с манифестом
+// и распаковывает бинарник. Без закрепленной суммы установка не выполняется.
+func Install(ctx context.Context, m *Manifest, name string, opts InstallOptions) (*Installed, error) {
+	t, err := m.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	if rec, err := ReadInstalled(name, t); err == nil && !opts.Force {
+		return rec, nil
+	}
+
+	url := t.Source()
+	if url == "" {
+		hint := t.Hint
+		if hint == "" {
+			hint = "set tools." + name + ".url in the tools manifest"
+		}
+		return nil, fmt.Errorf("%s %s has no release archive to install: %s", name, t.Version, hint)
+	}
+	sum := t.Checksum()
+	if sum == "" {
+		return nil, fmt.Errorf("no SHA-256 pinned for %s %s on %s: run 'd-guard tools pin %s' or add tools.%s.sha256.%s to %s (from the release checksums file)",
+			name, t.Version, Platform(), name, name, Platform(), manifestHint(m))
+	}
+
+	src := url
+	if opts.Mirror != "" {
+		if isURL(opts.Mirror) {
+			src = strings.TrimRight(opts.Mirror, "/") + "/" + path.Base(url)
+		} else if dir, err := filepath.Abs(opts.Mirror); err == nil {
+			src = filepath.Join(dir, path.Base(url))
+		}
+	}
+	if opts.Offline && isURL(src) {
+		return nil, fmt.Errorf("--offline: refusing to download %s (use --mirror with a local directory)", src)
+	}
+
+	data, err := fetch(ctx, src)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", src, err)
+	}
+	got := sha256.Sum256(data)
+	if hex.EncodeToString(got[:]) != sum {
+		return nil, fmt.Errorf("%s: SHA-256 mismatch: got %x, manifest pins %s", src, got, sum)
+	}
+
+	bin, err := extract(path.Base(url), data, t.binary(name))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", src, err)
+	}
+
+	dest := pinnedPath(name, t)
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return nil, err
+	}
+	tmp := dest + ".tmp"
+	if err := os.WriteFile(tmp, bin, 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		return nil, err
+	}
+	binSum := sha256.Sum256(bin)
+	rec := &Installed{
+		Tool: name, Version: t.Version, Source: src, ArchiveSHA256: sum,
+		BinarySHA256: hex.EncodeToString(binSum[:]), Installed: time.Now().UTC(), Path: dest,
+	}
+	data, err = json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return rec, os.WriteFile(filepath.Join(filepath.Dir(dest), installRecord), data, 0o644)
+}
+
+// ReadInstalled читает запись об установке закрепленной версии
+func ReadInstalled(name string, t *Tool) (*Installed, error) {
+	dest := pinnedPath(name, t)
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(dest), installRecord))
+	if err != nil {
+		return nil, err
+	}
+	var rec Installed
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("%s: %w", installRecord, err)
+	}
+	if _, err := os.Stat(dest); err != nil {
+		return nil, err
+	}
+	rec.Path = dest
+	return &rec, nil
+}
+
+// Verify проверяет установленную закрепленную версию: сумма архива совпадает
+// с текущим манифестом, бинарник не изменился после установки
+func Verify(m *Manifest, name string) (*Installed, error) {
+	t, err := m.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := ReadInstalled(name, t)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%s %s is not installed (run 'd-guard tools install %s')", name, t.Version, name)
+		}
+		return nil, err
+	}
+	if sum := t.Checksum(); sum != "" && rec.ArchiveSHA256 != sum {
+		return rec, fmt.Errorf("installed from an archive with SHA-256 %s, manifest now pins %s (reinstall with --force)", rec.ArchiveSHA256, sum)
+	}
+	data, err := os.ReadFile(rec.Path)
+	if err != nil {
+		return rec, err
+	}
+	if got := sha256.Sum256(data); hex.EncodeToString(got[:]) != rec.BinarySHA256 {
+		return rec, fmt.Errorf("%s was modified after installation (SHA-256 %x, expected %s)", rec.Path, got, rec.BinarySHA256)
+	}
+	return rec, nil
+}
+
+// extract достает бинарник из tar.gz/zip (по имени файла в любом каталоге архива)
+// или возвращает данные как есть, если архив — сам бинарник
+func extract(archive string, data []byte, binary string) ([]byte, error) {
+	switch {
+	case strings.HasSuffix(archive, ".tar.gz") || strings.HasSuffix(archive, ".tgz"):
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		tr := tar.NewReader(gz)
+		for {
+			h, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			if h.Typeflag == tar.TypeReg && path.Base(h.Name) == binary {
+				return io.ReadAll(tr)
+			}
+		}
+	case strings.HasSuffix(archive, ".zip"):
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range zr.File {
+			if f.FileInfo().Mode().IsRegular() && path.Base(f.Name) == binary {
+				rc, err := f.Open()
+				if err != nil {
+					return nil, err
+				}
+				defer rc.Close()
+				return io.ReadAll(rc)
+			}
+		}
+	default:
+		return data, nil
+	}
+	return nil, fmt.Errorf("%s not found in the archive", binary)
+}
+
+func fetch(ctx context.Context, src string) ([]byte, error) {
+	if !isURL(src) {
+		return os.ReadFile(src)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download failed: %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func isURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+func manifestHint(m *Manifest) string {
+	if m.Path != "" {
+		return m.Path
+	}
+	return DefaultManifestPath()
+}
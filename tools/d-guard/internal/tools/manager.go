@@ -1,61 +1,125 @@
 package tools
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	//"io"
-	//"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"runtime"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/devos-os/d-guard/internal/proc"
 )
 
+// Lookup ищет уже установленный инструмент, ничего не скачивая: закрепленная
+// версия из манифеста ('d-guard tools install'), затем PATH, затем старый кэш DevOS
+func Lookup(name string) (string, bool) {
+	path, _, ok := Resolve(name)
+	return path, ok
+}
+
+// Происхождение найденного инструмента (для 'd-guard tools list')
 const (
-	// Версии инструментов (фиксируем для стабильности)
-	gitleaksVer = "8.18.1"
-	semgrepVer  = "1.52.0" // Используем open-source ядро
+	FromPinned = "pinned"
+	FromPath   = "PATH"
+	FromCache  = "cache"
 )
 
-// EnsureTool проверяет наличие инструмента, или скачивает его
-func EnsureTool(name string) (string, error) {
-	// 1. Сначала ищем в PATH системы
-	if path, err := exec.LookPath(name); err == nil {
-		return path, nil
+// Resolve — путь к инструменту и откуда он взят (FromPinned, FromPath, FromCache)
+func Resolve(name string) (path, from string, ok bool) {
+	// 1. Закрепленная версия из манифеста
+	if t, err := pinned().Get(name); err == nil {
+		if p := pinnedPath(name, t); isFile(p) {
+			return p, FromPinned, true
+		}
 	}
 
-	// 2. Ищем в локальном кэше DevOS
-	home, _ := os.UserHomeDir()
-	cacheDir := filepath.Join(home, ".cache", "devos", "bin")
-	toolPath := filepath.Join(cacheDir, name)
+	// 2. PATH системы
+	if p, err := exec.LookPath(name); err == nil {
+		return p, FromPath, true
+	}
 
-	if _, err := os.Stat(toolPath); err == nil {
-		return toolPath, nil
+	// 3. Старый локальный кэш DevOS (~/.cache/devos/bin/<name>)
+	if p := filepath.Join(binDir(), name); isFile(p) {
+		return p, FromCache, true
 	}
+	return "", "", false
+}
 
-	// 3. Скачиваем, если нет
-	fmt.Printf("⬇️  Tool '%s' not found. Downloading automatically...\n", name)
-	if err := os.MkdirAll(cacheDir, 0755); err != nil {
-		return "", err
+// EnsureTool возвращает путь к инструменту. Скан ничего не скачивает:
+// установка — явно через 'd-guard tools install' с проверкой контрольной суммы.
+func EnsureTool(name string) (string, error) {
+	if path, ok := Lookup(name); ok {
+		return path, nil
 	}
+	hint := fmt.Sprintf("run 'd-guard tools install %s'", name)
+	if t, err := pinned().Get(name); err == nil && t.Source() == "" && t.Hint != "" {
+		hint = t.Hint
+	}
+	return "", fmt.Errorf("%s is not installed: %s", name, hint)
+}
 
-	return downloadTool(name, toolPath)
+func binDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".cache", "devos", "bin")
 }
 
-func downloadTool(name, dest string) (string, error) {
-	arch := runtime.GOARCH
-	//osName := runtime.GOOS
+func isFile(path string) bool {
+	st, err := os.Stat(path)
+	return err == nil && st.Mode().IsRegular()
+}
+
+// versionPattern — версия в выводе инструмента ("v8.18.1", "Version: 0.48.1", "1.52.0")
+var versionPattern = regexp.MustCompile(`\d+\.\d+\.\d+[0-9A-Za-z.+-]*`)
+
+var warnedVersion sync.Map
 
-	switch name {
-	case "gitleaks":
-		if arch == "amd64" { arch = "x64" }
+// ToolVersion возвращает точную версию того бинарника, который запустит скан.
+// Расхождение с манифестом — предупреждение: отчет покажет фактическую версию.
+func ToolVersion(ctx context.Context, name string) string {
+	bin, from, ok := Resolve(name)
+	if !ok {
+		return ""
+	}
+	args := []string{"--version"}
+	t, err := pinned().Get(name)
+	if err == nil && len(t.VersionArgs) > 0 {
+		args = t.VersionArgs
+	}
+	out, err := proc.Command(ctx, bin, args...).Output()
+	if err != nil {
+		return ""
+	}
+	version := strings.TrimSpace(strings.SplitN(strings.TrimSpace(string(out)), "\n", 2)[0])
+	if m := versionPattern.FindString(string(out)); m != "" {
+		version = m
+	}
+	if t != nil && version != t.Version {
+		if _, seen := warnedVersion.LoadOrStore(name, true); !seen {
+			fmt.Printf("  ⚠️  %s %s (%s) differs from the pinned %s: run 'd-guard tools install %s'\n", name, version, from, t.Version, name)
+		}
+	}
+	return version
+}
 
-		return "", fmt.Errorf("please install manually: curl -sSfL https://github.com/gitleaks/gitleaks/releases/download/v%s/gitleaks_%s_x64.tar.gz | tar xz", gitleaksVer, gitleaksVer)
-	
-	case "semgrep":
-		// Semgrep устанавливается через pip, это сложнее для binary drop.
-		// Но мы можем проверить python environment.
-		return "", fmt.Errorf("please install manually: pip install semgrep")
+// exitCode код завершения процесса (-1, если процесс не запустился)
+func exitCode(err error) int {
+	var ee *exec.ExitError
+	if errors.As(err, &ee) {
+		return ee.ExitCode()
 	}
+	return -1
+}
 
-	return "", fmt.Errorf("unknown tool")
-}
\ No newline at end of file
+// describeExit добавляет к ошибке последнюю строку stderr инструмента
+func describeExit(err error) string {
+	var ee *exec.ExitError
+	if errors.As(err, &ee) && len(ee.Stderr) > 0 {
+		lines := strings.Split(strings.TrimSpace(string(ee.Stderr)), "\n")
+		return fmt.Sprintf("%v: %s", err, strings.TrimSpace(lines[len(lines)-1]))
+	}
+	return err.Error()
+}
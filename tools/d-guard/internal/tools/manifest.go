@@ -0,0 +1,221 @@
+package tools
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Суммы встроенного манифеста обновляются из checksums-файлов релизов
+//go:generate go run ../../cmd/d-guard tools pin --manifest manifest.yaml
+
+//go:embed manifest.yaml
+var builtinManifest []byte
+
+// Manifest — закрепленные версии и контрольные суммы внешних инструментов
+type Manifest struct {
+	Tools map[string]*Tool `yaml:"tools"`
+	Path  string           `yaml:"-"` // Пользовательский манифест поверх встроенного (пусто — только встроенный)
+}
+
+// Tool — одна запись манифеста
+type Tool struct {
+	Version     string            `yaml:"version"`
+	URL         string            `yaml:"url"`          // Шаблон: {version}, {os}, {arch}
+	Binary      string            `yaml:"binary"`       // Исполняемый файл в архиве (по умолчанию — имя инструмента)
+	OS          map[string]string `yaml:"os"`           // GOOS → имя платформы в названии архива
+	Arch        map[string]string `yaml:"arch"`         // GOARCH → имя архитектуры в названии архива
+	SHA256      map[string]string `yaml:"sha256"`       // <GOOS>_<GOARCH> → сумма архива
+	Checksums   string            `yaml:"checksums"`    // Шаблон адреса checksums-файла релиза (для 'tools pin'): {version}
+	VersionArgs []string          `yaml:"version_args"` // Аргументы для вывода версии
+	Hint        string            `yaml:"hint"`         // Ручная установка, если url не задан
+}
+
+// Platform — ключ sha256 для текущей системы (e.g. "linux_amd64")
+func Platform() string { return runtime.GOOS + "_" + runtime.GOARCH }
+
+// Source — адрес архива для текущей платформы (пусто — установка только вручную)
+func (t *Tool) Source() string { return t.SourceFor(runtime.GOOS, runtime.GOARCH) }
+
+// SourceFor — адрес архива для платформы goos/arch
+func (t *Tool) SourceFor(goos, arch string) string {
+	if t.URL == "" {
+		return ""
+	}
+	if v, ok := t.OS[goos]; ok {
+		goos = v
+	}
+	if v, ok := t.Arch[arch]; ok {
+		arch = v
+	}
+	return strings.NewReplacer("{version}", t.Version, "{os}", goos, "{arch}", arch).Replace(t.URL)
+}
+
+// Checksum — закрепленная сумма архива для текущей платформы
+func (t *Tool) Checksum() string {
+	return strings.ToLower(strings.TrimSpace(t.SHA256[Platform()]))
+}
+
+// DefaultManifestPath — ~/.config/devos/d-guard/tools.yaml
+func DefaultManifestPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "devos", "d-guard", "tools.yaml")
+}
+
+// LoadManifest читает встроенный манифест и накладывает path (пусто — путь по умолчанию,
+// если файл существует). Поля пользовательской записи заменяют встроенные, sha256 дополняет.
+func LoadManifest(path string) (*Manifest, error) {
+	m := &Manifest{}
+	if err := yaml.Unmarshal(builtinManifest, m); err != nil {
+		// Встроенный манифест проверяется при сборке — ошибка здесь означает баг
+		panic(fmt.Sprintf("tools: invalid embedded manifest: %v", err))
+	}
+
+	explicit := path != ""
+	if !explicit {
+		path = DefaultManifestPath()
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && os.IsNotExist(err) {
+			return m, nil
+		}
+		return nil, err
+	}
+	var user Manifest
+	if err := yaml.Unmarshal(data, &user); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for name, t := range user.Tools {
+		if t == nil {
+			continue
+		}
+		base, ok := m.Tools[name]
+		if !ok {
+			m.Tools[name] = t
+			continue
+		}
+		// Новая версия без своих сумм не должна унаследовать суммы старой
+		if t.Version != "" && t.Version != base.Version {
+			base.Version, base.SHA256 = t.Version, nil
+		}
+		if t.URL != "" {
+			base.URL = t.URL
+		}
+		if t.Binary != "" {
+			base.Binary = t.Binary
+		}
+		if len(t.VersionArgs) > 0 {
+			base.VersionArgs = t.VersionArgs
+		}
+		if t.Hint != "" {
+			base.Hint = t.Hint
+		}
+		if t.Checksums != "" {
+			base.Checksums = t.Checksums
+		}
+		for k, v := range t.OS {
+			base.OS = setKey(base.OS, k, v)
+		}
+		for k, v := range t.Arch {
+			base.Arch = setKey(base.Arch, k, v)
+		}
+		for k, v := range t.SHA256 {
+			base.SHA256 = setKey(base.SHA256, k, v)
+		}
+	}
+	m.Path = path
+	return m, m.validate()
+}
+
+// validate: версия обязательна, суммы — 64 hex-символа
+func (m *Manifest) validate() error {
+	for _, name := range m.Names() {
+		t := m.Tools[name]
+		if t.Version == "" {
+			return fmt.Errorf("%s: tools.%s.version is required", m.Path, name)
+		}
+		for platform, sum := range t.SHA256 {
+			if !isSHA256(sum) {
+				return fmt.Errorf("%s: tools.%s.sha256.%s: expected 64 hex characters", m.Path, name, platform)
+			}
+		}
+	}
+	return nil
+}
+
+// Names — инструменты манифеста по алфавиту
+func (m *Manifest) Names() []string {
+	var names []string
+	for name := range m.Tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get возвращает запись инструмента
+func (m *Manifest) Get(name string) (*Tool, error) {
+	t, ok := m.Tools[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown tool %q (available: %s)", name, strings.Join(m.Names(), ", "))
+	}
+	return t, nil
+}
+
+var (
+	pinsOnce sync.Once
+	pins     *Manifest
+)
+
+// pinned — манифест для сканов: ошибка в пользовательском файле не ломает скан,
+// но о ней предупреждаем (закрепленные версии могут не примениться)
+func pinned() *Manifest {
+	pinsOnce.Do(func() {
+		m, err := LoadManifest("")
+		if err != nil {
+			fmt.Printf("  ⚠️  tools manifest ignored: %v\n", err)
+			m = &Manifest{}
+			yaml.Unmarshal(builtinManifest, m)
+		}
+		pins = m
+	})
+	return pins
+}
+
+// UseManifest задает манифест для поиска закрепленных версий (e.g. 'd-guard tools --manifest')
+func UseManifest(m *Manifest) {
+	pinsOnce.Do(func() {})
+	pins = m
+}
+
+func setKey(m map[string]string, k, v string) map[string]string {
+	if m == nil {
+		m = map[string]string{}
+	}
+	m[k] = v
+	return m
+}
+
+func isSHA256(s string) bool {
+	s = strings.TrimSpace(s)
+	if len(s) != 64 {
+		return false
+	}
+	for _, c := range strings.ToLower(s) {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
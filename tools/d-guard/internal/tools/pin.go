@@ -0,0 +1,141 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PinPlatforms — платформы (<GOOS>_<GOARCH>), для которых 'tools pin' закрепляет суммы
+var PinPlatforms = []string{"darwin_amd64", "darwin_arm64", "linux_amd64", "linux_arm64"}
+
+// Pin читает checksums-файл релиза (tools.<name>.checksums или from — путь или URL,
+// e.g. для зеркала) и возвращает суммы архивов для PinPlatforms
+func Pin(ctx context.Context, m *Manifest, name, from string) (map[string]string, error) {
+	t, err := m.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	if t.URL == "" {
+		return nil, fmt.Errorf("%s %s has no release archives to pin", name, t.Version)
+	}
+	src := from
+	if src == "" {
+		if t.Checksums == "" {
+			return nil, fmt.Errorf("%s: set tools.%s.checksums in the tools manifest or pass --from", name, name)
+		}
+		src = strings.ReplaceAll(t.Checksums, "{version}", t.Version)
+	}
+
+	data, err := fetch(ctx, src)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", src, err)
+	}
+	sums := parseChecksums(data)
+	pins := map[string]string{}
+	for _, p := range PinPlatforms {
+		goos, arch, _ := strings.Cut(p, "_")
+		if sum, ok := sums[path.Base(t.SourceFor(goos, arch))]; ok {
+			pins[p] = sum
+		}
+	}
+	if len(pins) == 0 {
+		return nil, fmt.Errorf("%s: no %s %s archives for %s", src, name, t.Version, strings.Join(PinPlatforms, ", "))
+	}
+	return pins, nil
+}
+
+// parseChecksums разбирает формат sha256sum: "<sha256>  <файл>" ("*" перед именем — бинарный режим)
+func parseChecksums(data []byte) map[string]string {
+	sums := map[string]string{}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) != 2 || !isSHA256(fields[0]) {
+			continue
+		}
+		sums[path.Base(strings.TrimPrefix(fields[1], "*"))] = strings.ToLower(fields[0])
+	}
+	return sums
+}
+
+// WritePins записывает tools.<name>.version и sha256 в файл манифеста. Остальное
+// содержимое и комментарии сохраняются; файла может не быть.
+func WritePins(file, name, version string, pins map[string]string) error {
+	var doc yaml.Node
+	data, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, HeadComment: doc.HeadComment, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s: expected a mapping at the top level", file)
+	}
+
+	tool := child(child(root, "tools"), name)
+	version = strings.TrimSpace(version)
+	setChild(tool, "version", &yaml.Node{Kind: yaml.ScalarNode, Value: version})
+	sums := &yaml.Node{Kind: yaml.MappingNode}
+	platforms := make([]string, 0, len(pins))
+	for p := range pins {
+		platforms = append(platforms, p)
+	}
+	sort.Strings(platforms)
+	for _, p := range platforms {
+		sums.Content = append(sums.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: p},
+			&yaml.Node{Kind: yaml.ScalarNode, Value: pins[p]})
+	}
+	setChild(tool, "sha256", sums)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(file+".tmp", buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(file+".tmp", file)
+}
+
+// child возвращает вложенный mapping по ключу, создавая его при необходимости
+func child(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key && m.Content[i+1].Kind == yaml.MappingNode {
+			return m.Content[i+1]
+		}
+	}
+	n := &yaml.Node{Kind: yaml.MappingNode}
+	setChild(m, key, n)
+	return n
+}
+
+func setChild(m *yaml.Node, key string, v *yaml.Node) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			v.LineComment = m.Content[i+1].LineComment
+			m.Content[i+1] = v
+			return
+		}
+	}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, v)
+}
@@ -0,0 +1,120 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuiltinManifest(t *testing.T) {
+	m, err := LoadManifest(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err == nil {
+		t.Fatal("LoadManifest: explicit missing file must be an error")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if m, err = LoadManifest(""); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range m.Names() {
+		tool := m.Tools[name]
+		if tool.URL != "" && tool.Checksums == "" {
+			t.Errorf("%s: url without checksums: 'tools pin' cannot pin it", name)
+		}
+	}
+}
+
+func TestPin(t *testing.T) {
+	dir := t.TempDir()
+	sums := filepath.Join(dir, "trivy_0.48.1_checksums.txt")
+	a, b, c := strings.Repeat("a", 64), strings.Repeat("B", 64), strings.Repeat("c", 64)
+	content := a + "  trivy_0.48.1_Linux-64bit.tar.gz\n" +
+		b + " *trivy_0.48.1_macOS-ARM64.tar.gz\n" +
+		c + "  trivy_0.48.1_windows-64bit.zip\n" +
+		"not a checksum line\n"
+	if err := os.WriteFile(sums, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	m, err := LoadManifest("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pins, err := Pin(context.Background(), m, "trivy", sums)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"linux_amd64": a, "darwin_arm64": strings.ToLower(b)}
+	if !reflect.DeepEqual(pins, want) {
+		t.Errorf("Pin = %v, want %v", pins, want)
+	}
+
+	if _, err := Pin(context.Background(), m, "gitleaks", sums); err == nil {
+		t.Error("Pin: checksums of another tool must not match")
+	}
+	if _, err := Pin(context.Background(), m, "semgrep", sums); err == nil {
+		t.Error("Pin: tool without release archives must fail")
+	}
+}
+
+func TestWritePins(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "d-guard", "tools.yaml")
+	initial := `# Зеркало компании
+tools:
+  trivy:
+    url: https://mirror.example.com/trivy_{version}_{os}-{arch}.tar.gz # внутреннее зеркало
+    sha256:
+      linux_amd64: ` + strings.Repeat("0", 64) + `
+`
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte(initial), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	pins := map[string]string{"linux_amd64": strings.Repeat("a", 64), "darwin_arm64": strings.Repeat("b", 64)}
+	if err := WritePins(file, "trivy", "0.48.1", pins); err != nil {
+		t.Fatal(err)
+	}
+	if err := WritePins(file, "gitleaks", "8.18.1", map[string]string{"linux_amd64": strings.Repeat("c", 64)}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(file)
+	for _, s := range []string{"# Зеркало компании", "# внутреннее зеркало", "https://mirror.example.com/"} {
+		if !strings.Contains(string(data), s) {
+			t.Errorf("WritePins lost %q:\n%s", s, data)
+		}
+	}
+	if strings.Contains(string(data), strings.Repeat("0", 64)) {
+		t.Errorf("old checksums must be replaced:\n%s", data)
+	}
+
+	m, err := LoadManifest(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trivy, _ := m.Get("trivy")
+	if !reflect.DeepEqual(trivy.SHA256, pins) || trivy.Version != "0.48.1" || !strings.HasPrefix(trivy.URL, "https://mirror.example.com/") {
+		t.Errorf("trivy after pin: %+v", trivy)
+	}
+	gitleaks, _ := m.Get("gitleaks")
+	if gitleaks.SHA256["linux_amd64"] != strings.Repeat("c", 64) || gitleaks.URL == "" {
+		t.Errorf("gitleaks after pin: %+v", gitleaks)
+	}
+
+	// Новый файл
+	fresh := filepath.Join(t.TempDir(), "tools.yaml")
+	if err := WritePins(fresh, "trivy", "0.48.1", pins); err != nil {
+		t.Fatal(err)
+	}
+	if m, err := LoadManifest(fresh); err != nil || !reflect.DeepEqual(m.Tools["trivy"].SHA256, pins) {
+		t.Errorf("LoadManifest(fresh) = %v", err)
+	}
+}
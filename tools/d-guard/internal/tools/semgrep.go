@@ -1,12 +1,49 @@
 package tools
 
 import (
+	"context"
 	"encoding/json"
-	"os/exec"
+	"fmt"
+	"strings"
 
 	"github.com/devos-os/d-guard/internal/core"
+	"github.com/devos-os/d-guard/internal/proc"
+	"github.com/devos-os/d-guard/internal/scanner"
 )
 
+func init() { scanner.Register(semgrepScanner{}) }
+
+type semgrepScanner struct{}
+
+func (semgrepScanner) Name() string { return "semgrep" }
+func (semgrepScanner) External()    {}
+
+// config — правила Semgrep через запятую (e.g. "devos,p/ci,./rules"), см. semgrep_rules.go
+func (semgrepScanner) Options() []string { return []string{"config"} }
+
+func (semgrepScanner) Applicable(t scanner.Target) bool {
+	_, ok := Lookup("semgrep")
+	return ok
+}
+
+func (semgrepScanner) SkipReason(t scanner.Target) string { return "semgrep is not installed" }
+
+func (semgrepScanner) Version(ctx context.Context) string {
+	return ToolVersion(ctx, "semgrep")
+}
+
+func (semgrepScanner) Scan(ctx context.Context, t scanner.Target) ([]core.Issue, error) {
+	configs, cleanup, err := semgrepConfigs(t)
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
+	if len(configs) == 0 {
+		return nil, scanner.Skip("no files in languages of DevOS rule packs (%s) and no other rules configured", strings.Join(RulePacks(), ", "))
+	}
+	return RunSemgrep(ctx, t.Root, t.Files, configs, t.Offline)
+}
+
 type SemgrepOutput struct {
 	Results []struct {
 		CheckID string `json:"check_id"`
@@ -14,14 +51,27 @@ type SemgrepOutput struct {
 		Start   struct { Line int `json:"line"` } `json:"start"`
 		Extra   struct { Message string `json:"message"` ; Severity string `json:"severity"` } `json:"extra"`
 	} `json:"results"`
+	Errors []struct {
+		Level   string `json:"level"`
+		Message string `json:"message"`
+	} `json:"errors"`
 }
 
-func RunSemgrep(root string, files []string) []core.Issue {
+// RunSemgrep запускает Semgrep без метрик; offline — еще и без проверки версии в сети
+func RunSemgrep(ctx context.Context, root string, files []string, configs []string, offline bool) ([]core.Issue, error) {
 	bin, err := EnsureTool("semgrep")
-	if err != nil { return nil }
+	if err != nil {
+		return nil, scanner.Skip("semgrep unavailable: %v", err)
+	}
 
-	// semgrep scan --config=auto --json [files...]
-	args := []string{"scan", "--config=auto", "--json", "--quiet"}
+	// semgrep scan --json --metrics=off --config=... [files...] (правила из scanners.semgrep.config)
+	args := []string{"scan", "--json", "--quiet", "--metrics=off"}
+	if offline {
+		args = append(args, "--disable-version-check")
+	}
+	for _, c := range configs {
+		args = append(args, "--config="+c)
+	}
 	
 	// Если файлов мало (git hook), передаем их явно для скорости
 	if len(files) > 0 && len(files) < 50 {
@@ -30,11 +80,25 @@ func RunSemgrep(root string, files []string) []core.Issue {
 		args = append(args, root)
 	}
 
-	cmd := exec.Command(bin, args...)
-	out, _ := cmd.Output() // Semgrep может вернуть exit 1 если нашел баги, это норм
+	cmd := proc.Command(ctx, bin, args...)
+	out, runErr := cmd.Output()
+	// Semgrep может вернуть exit 1 если нашел баги, это норм; 2+ — ошибка
+	if runErr != nil && exitCode(runErr) != 1 {
+		return nil, fmt.Errorf("semgrep: %s", describeExit(runErr))
+	}
 
 	var report SemgrepOutput
-	json.Unmarshal(out, &report)
+	if err := json.Unmarshal(out, &report); err != nil {
+		return nil, fmt.Errorf("semgrep output: %w", err)
+	}
+	// Ошибки уровня error (битые правила, нет сети для пакетов реестра) без результатов — сбой
+	if len(report.Results) == 0 {
+		for _, e := range report.Errors {
+			if e.Level == "error" {
+				return nil, fmt.Errorf("semgrep: %s", strings.TrimSpace(e.Message))
+			}
+		}
+	}
 
 	var issues []core.Issue
 	for _, res := range report.Results {
@@ -43,6 +107,7 @@ func RunSemgrep(root string, files []string) []core.Issue {
 		
 		issues = append(issues, core.Issue{
 			Scanner:     "Semgrep SAST",
+			RuleID:      res.CheckID,
 			Severity:    sev,
 			Message:     res.CheckID,
 			File:        res.Path,
@@ -51,5 +116,5 @@ func RunSemgrep(root string, files []string) []core.Issue {
 			Suggestion:  "Check code logic",
 		})
 	}
-	return issues
+	return issues, nil
 }
\ No newline at end of file
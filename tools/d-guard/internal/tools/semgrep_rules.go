@@ -0,0 +1,193 @@
+package tools
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/devos-os/d-guard/internal/cache"
+	"github.com/devos-os/d-guard/internal/scanner"
+	"gopkg.in/yaml.v3"
+)
+
+// Правила Semgrep (scanners.semgrep.config, через запятую):
+//   devos          — встроенные паки DevOS для языков, найденных среди файлов скана
+//   devos/<язык>   — конкретный встроенный пак (go, python, javascript)
+//   p/ci, r/...    — пакеты реестра semgrep.dev: из локального кэша ('d-guard rules pull'),
+//                    без кэша — загрузка самим Semgrep (в --offline запрещена)
+//   ./rules        — локальный файл или каталог (относительно .d-guard.yaml)
+// "auto" не поддерживается: он требует отправки метрик, а Semgrep запускается с --metrics=off.
+
+// DefaultSemgrepConfig — значение scanners.semgrep.config по умолчанию
+const DefaultSemgrepConfig = "devos"
+
+//go:embed rules/*.yaml
+var rulePacks embed.FS
+
+// packExtensions — расширения файлов, по которым выбираются встроенные паки
+var packExtensions = map[string]string{
+	".go":  "go",
+	".py":  "python",
+	".js":  "javascript",
+	".jsx": "javascript",
+	".mjs": "javascript",
+	".cjs": "javascript",
+	".ts":  "javascript",
+	".tsx": "javascript",
+}
+
+// RulePacks — имена встроенных паков
+func RulePacks() []string {
+	entries, _ := rulePacks.ReadDir("rules")
+	var names []string
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), ".yaml"))
+	}
+	return names
+}
+
+// DetectPacks — встроенные паки для языков среди files
+func DetectPacks(files []string) []string {
+	seen := map[string]bool{}
+	for _, f := range files {
+		if pack, ok := packExtensions[strings.ToLower(filepath.Ext(f))]; ok {
+			seen[pack] = true
+		}
+	}
+	var packs []string
+	for pack := range seen {
+		packs = append(packs, pack)
+	}
+	sort.Strings(packs)
+	return packs
+}
+
+// RulesCacheDir — кэш пакетов реестра Semgrep для работы без сети
+func RulesCacheDir() string {
+	return filepath.Join(cache.DefaultDir(), "semgrep")
+}
+
+// CachedRules — путь к пакету реестра в кэше ("p/ci" → <cache>/p/ci.yaml)
+func CachedRules(config string) string {
+	return filepath.Join(RulesCacheDir(), filepath.FromSlash(config)+".yaml")
+}
+
+// IsRegistry — пакет реестра semgrep.dev (p/, r/, s/)
+func IsRegistry(config string) bool {
+	for _, prefix := range []string{"p/", "r/", "s/"} {
+		if strings.HasPrefix(config, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// PullRules сохраняет пакет реестра в кэш: из semgrep.dev или из файла from
+// (перенос на машину без сети). Возвращает путь в кэше и число правил.
+func PullRules(ctx context.Context, config, from string) (string, int, error) {
+	if !IsRegistry(config) || strings.Contains(config, "..") {
+		return "", 0, fmt.Errorf("%q is not a registry config (expected p/<pack>, r/<rule> or s/<snippet>)", config)
+	}
+	src := from
+	if src == "" {
+		src = "https://semgrep.dev/c/" + config
+	}
+	data, err := fetch(ctx, src)
+	if err != nil {
+		return "", 0, fmt.Errorf("%s: %w", src, err)
+	}
+	var pack struct {
+		Rules []map[string]any `yaml:"rules"`
+	}
+	if err := yaml.Unmarshal(data, &pack); err != nil || len(pack.Rules) == 0 {
+		return "", 0, fmt.Errorf("%s: not a Semgrep rules file", src)
+	}
+
+	dest := CachedRules(config)
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return "", 0, err
+	}
+	if err := os.WriteFile(dest+".tmp", data, 0o644); err != nil {
+		return "", 0, err
+	}
+	return dest, len(pack.Rules), os.Rename(dest+".tmp", dest)
+}
+
+// semgrepConfigs превращает scanners.semgrep.config в аргументы --config.
+// Встроенные паки пишутся во временный каталог, cleanup его удаляет.
+func semgrepConfigs(t scanner.Target) (configs []string, cleanup func(), err error) {
+	cleanup = func() {}
+	base := t.Root
+	if t.Config != nil && t.Config.Path != "" {
+		base = filepath.Dir(t.Config.Path)
+	}
+
+	var packs []string
+	for _, c := range strings.Split(t.Option("config", DefaultSemgrepConfig), ",") {
+		switch c = strings.TrimSpace(c); {
+		case c == "":
+		case c == "auto":
+			return nil, cleanup, fmt.Errorf("semgrep config \"auto\" requires sending metrics to semgrep.dev; use %q, registry packs (p/ci) or local rules", DefaultSemgrepConfig)
+		case c == "devos":
+			packs = append(packs, DetectPacks(t.Files)...)
+		case strings.HasPrefix(c, "devos/"):
+			name := strings.TrimPrefix(c, "devos/")
+			if !contains(RulePacks(), name) {
+				return nil, cleanup, fmt.Errorf("unknown DevOS rule pack %q (available: %s)", c, strings.Join(RulePacks(), ", "))
+			}
+			packs = append(packs, name)
+		case IsRegistry(c):
+			if cached := CachedRules(c); isFile(cached) {
+				configs = append(configs, cached)
+			} else if t.Offline {
+				return nil, cleanup, fmt.Errorf("semgrep rules %s are not cached for --offline: run 'd-guard rules pull %s'", c, c)
+			} else {
+				configs = append(configs, c)
+			}
+		default:
+			if !filepath.IsAbs(c) {
+				c = filepath.Join(base, c)
+			}
+			configs = append(configs, c)
+		}
+	}
+	if len(packs) == 0 {
+		return configs, cleanup, nil
+	}
+
+	dir, err := os.MkdirTemp("", "d-guard-semgrep-*")
+	if err != nil {
+		return nil, cleanup, err
+	}
+	cleanup = func() { os.RemoveAll(dir) }
+	seen := map[string]bool{}
+	for _, pack := range packs {
+		if seen[pack] {
+			continue
+		}
+		seen[pack] = true
+		data, err := rulePacks.ReadFile("rules/" + pack + ".yaml")
+		if err != nil {
+			return nil, cleanup, err
+		}
+		path := filepath.Join(dir, "devos-"+pack+".yaml")
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return nil, cleanup, err
+		}
+		configs = append(configs, path)
+	}
+	return configs, cleanup, nil
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/devos-os/d-guard/internal/config"
+	"github.com/devos-os/d-guard/internal/scanner"
+)
+
+func TestSemgrepConfigs(t *testing.T) {
+	// Кэш пакетов реестра — во временном каталоге, а не в кэше машины
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	if err := os.MkdirAll(filepath.Dir(CachedRules("p/ci")), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(CachedRules("p/ci"), []byte("rules: []\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	root := t.TempDir()
+	files := []string{filepath.Join(root, "main.go"), filepath.Join(root, "web", "app.tsx"), filepath.Join(root, "README.md")}
+
+	tests := []struct {
+		name    string
+		config  string
+		offline bool
+		want    []string // встроенные паки — как "devos-<пак>.yaml"
+		err     string
+	}{
+		{"default", "", false, []string{"devos-go.yaml", "devos-javascript.yaml"}, ""},
+		{"devos", "devos", false, []string{"devos-go.yaml", "devos-javascript.yaml"}, ""},
+		{"explicit pack", "devos/python", false, []string{"devos-python.yaml"}, ""},
+		{"pack listed twice", "devos, devos/go", false, []string{"devos-go.yaml", "devos-javascript.yaml"}, ""},
+		{"unknown pack", "devos/rust", false, nil, `unknown DevOS rule pack "devos/rust"`},
+		{"local path", "./rules,/etc/semgrep.yaml", false, []string{filepath.Join(root, "rules"), "/etc/semgrep.yaml"}, ""},
+		{"cached registry pack", "p/ci", true, []string{CachedRules("p/ci")}, ""},
+		{"registry pack online", "p/owasp-top-ten", false, []string{"p/owasp-top-ten"}, ""},
+		{"registry pack offline", "p/owasp-top-ten", true, nil, "not cached for --offline"},
+		{"auto", "devos,auto", false, nil, `"auto" requires sending metrics`},
+		{"empty items", " , p/ci ,", false, []string{CachedRules("p/ci")}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := scanner.Target{
+				Root: root, Files: files, Offline: tt.offline,
+				Config:  &config.Project{Path: filepath.Join(root, ".d-guard.yaml")},
+				Options: map[string]string{"config": tt.config},
+			}
+			configs, cleanup, err := semgrepConfigs(target)
+			defer cleanup()
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, c := range configs {
+				if base := filepath.Base(c); strings.HasPrefix(base, "devos-") {
+					if _, err := os.Stat(c); err != nil {
+						t.Errorf("pack %s was not written: %v", c, err)
+					}
+					c = base
+				}
+				got = append(got, c)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("configs = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSemgrepConfigsCleanup(t *testing.T) {
+	configs, cleanup, err := semgrepConfigs(scanner.Target{Root: t.TempDir(), Files: []string{"/repo/app.py"}})
+	if err != nil || len(configs) != 1 {
+		t.Fatalf("configs = %v, %v", configs, err)
+	}
+	cleanup()
+	if _, err := os.Stat(filepath.Dir(configs[0])); !os.IsNotExist(err) {
+		t.Errorf("temporary pack directory left behind: %v", err)
+	}
+}